@@ -0,0 +1,10 @@
+// Package api holds the service contracts: the OpenAPI document for the HTTP API and
+// the protobuf definitions for gRPC (api/proto).
+package api
+
+import _ "embed"
+
+// OpenAPISpec is the OpenAPI 3 document describing the routes registered in handlers.SetupRoutes.
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
@@ -2,12 +2,24 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"pvz/internal/config"
 	"pvz/internal/data"
+	"pvz/internal/events"
+	"pvz/internal/grpcapi"
 	"pvz/internal/handlers"
+	"pvz/internal/logging"
+	"pvz/internal/metrics"
+	"pvz/internal/notify"
+	"pvz/internal/ratelimit"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/tracing"
 	"syscall"
 	"time"
 
@@ -15,50 +27,227 @@ import (
 )
 
 func main() {
-	if err := data.InitDB(); err != nil {
-		log.Fatalf("Ошибка инициализации БД: %v", err)
+	// the configured logger replaces this one as soon as the configuration is read
+	logger, _ := logging.New(os.Stderr, config.Default().Log)
+	slog.SetDefault(logger)
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			fatal("migration failed", err)
+		}
+		return
+	}
+
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fatal("invalid configuration", err)
+	}
+	logger, err = logging.New(os.Stderr, cfg.Log)
+	if err != nil {
+		fatal("failed to set up logging", err)
+	}
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("failed to flush traces", slog.Any("error", err))
+		}
+	}()
+
+	if err := data.InitDB(cfg.DB); err != nil {
+		fatal("failed to connect to database", err)
 	}
 	defer func() {
 		if err := data.CloseDB(); err != nil {
-			log.Printf("Ошибка при закрытии соединения с БД: %v", err)
+			slog.Error("failed to close database", slog.Any("error", err))
 		}
 	}()
 
-	router := gin.Default()
+	if cfg.DB.AutoMigrate {
+		if err := autoMigrate(context.Background()); err != nil {
+			fatal("migration failed", err)
+		}
+	}
+
+	publisher, err := newOutboxPublisher(cfg.Outbox)
+	if err != nil {
+		fatal("failed to set up event publishing", err)
+	}
+	webhookRepo := repository.NewWebhookRepository(data.DB)
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	relay := events.NewRelay(repository.NewOutboxRepository(data.DB), publisher, time.Second, 100, 10, time.Second)
+	go relay.Run(workersCtx)
+	// subscriptions take events from the outbox on their own, so a failing publisher does not hold them back
+	webhookRelay := events.NewRelay(repository.NewWebhookOutboxRepository(data.DB), events.NewSubscriptionPublisher(webhookRepo),
+		time.Second, 100, 10, time.Second)
+	go webhookRelay.Run(workersCtx)
+	dispatcher := events.NewWebhookDispatcher(webhookRepo, nil, time.Second, 50, 8, 5*time.Second)
+	go dispatcher.Run(workersCtx)
+	go purgeIdempotencyKeys(workersCtx, repository.NewIdempotencyRepository(data.DB), time.Hour)
+
+	tokens, err := newTokenManager(cfg.JWT)
+	if err != nil {
+		fatal("failed to set up token signing", err)
+	}
+	m := metrics.New(repository.NewPWZRepository(data.DB))
+	m.RegisterDB(data.DB, repository.NewReceptionRepository(data.DB))
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		fatal("invalid trusted proxies", err)
+	}
+
+	notifier, err := newNotifier(cfg.Notify)
+	if err != nil {
+		fatal("failed to set up notifications", err)
+	}
+
+	// both APIs check tokens and count logins through the same service and store; each replica limits
+	// on its own, a shared ratelimit.Store would make the limits global
+	userService := services.NewUserService(
+		repository.NewUserRepository(data.DB),
+		repository.NewLoginAttemptRepository(data.DB),
+		tokens,
+		services.Lockout{MaxAttempts: cfg.Lockout.MaxAttempts, Duration: cfg.Lockout.Duration},
+		services.PasswordPolicy{MinLength: cfg.Password.MinLength, RequiredClasses: cfg.Password.RequiredClasses},
+		cfg.Limits.UserListMax,
+		cfg.Auth.DevMode,
+	)
+	limits := ratelimit.NewMemoryStore()
 
-	handlers.SetupRoutes(data.DB, router)
+	handlers.SetupRoutes(data.DB, router, cfg, tokens, m, notifier, userService, limits)
 
 	srv := &http.Server{
-		Addr:    ":8080",
-		Handler: router.Handler(),
+		Addr:              fmt.Sprintf(":%d", cfg.HTTP.Port),
+		Handler:           router.Handler(),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
 	}
 
 	go func() {
 		// service connections
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\\n", err)
+			fatal("http server failed", err)
+		}
+	}()
+
+	grpcServer := grpcapi.NewServer(
+		services.NewPVZService(repository.NewPWZRepository(data.DB), cfg.Limits.PVZListMax, m),
+		services.NewReceptionService(repository.NewReceptionRepository(data.DB), m),
+		services.NewProductService(repository.NewProductRepository(data.DB), m),
+		userService,
+		tokens,
+		userService,
+		limits,
+		ratelimit.Limit{Requests: cfg.RateLimit.Auth.Requests, Period: cfg.RateLimit.Auth.Period},
+		cfg.Limits.PVZListDefault,
+	)
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+	if err != nil {
+		fatal("grpc listen failed", err)
+	}
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			fatal("grpc server failed", err)
 		}
 	}()
 
 	// Wait for interrupt signal to gracefully shutdown the server with
-	// a timeout of 5 seconds.
+	// a timeout of SHUTDOWN_TIMEOUT.
 	quit := make(chan os.Signal, 1)
 	// kill (no param) default send syscall.SIGTERM
 	// kill -2 is syscall.SIGINT
 	// kill -9 is syscall. SIGKILL but can"t be catch, so don't need add it
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutdown Server ...")
+	slog.Info("shutting down")
+	stopWorkers()
+	grpcServer.GracefulStop()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server Shutdown:", err)
+		fatal("http server shutdown failed", err)
 	}
-	// catching ctx.Done(). timeout of 5 seconds.
-	select {
-	case <-ctx.Done():
-		log.Println("timeout of 5 seconds.")
+	slog.Info("server stopped")
+}
+
+// fatal logs err and exits. Like log.Fatal, it skips deferred calls.
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
+
+// newOutboxPublisher selects where outbox events go: OUTBOX_PUBLISHER is one of
+// "stdout" (default), "file" (OUTBOX_FILE) or "webhook" (OUTBOX_WEBHOOK_URL).
+func newOutboxPublisher(cfg config.OutboxConfig) (events.PublisherInterface, error) {
+	switch cfg.Publisher {
+	case "stdout":
+		return events.NewWriterPublisher(os.Stdout), nil
+	case "file":
+		return events.NewFilePublisher(cfg.File)
+	case "webhook":
+		return events.NewWebhookPublisher(cfg.WebhookURL, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown OUTBOX_PUBLISHER %q", cfg.Publisher)
+	}
+}
+
+// newNotifier selects how users get messages such as password reset tokens: NOTIFIER is "log" or
+// "file" (NOTIFIER_FILE). Without NOTIFIER there is no notifier and password reset is off.
+func newNotifier(cfg config.NotifyConfig) (notify.NotifierInterface, error) {
+	switch cfg.Notifier {
+	case "":
+		return nil, nil
+	case "log":
+		return notify.NewLogNotifier(), nil
+	case "file":
+		return notify.NewFileNotifier(cfg.File)
+	default:
+		return nil, fmt.Errorf("unknown NOTIFIER %q", cfg.Notifier)
+	}
+}
+
+// newTokenManager signs with JWT_KEYS when any are configured and with JWT_SECRET otherwise.
+func newTokenManager(cfg config.JWTConfig) (*services.TokenManager, error) {
+	if len(cfg.Keys) == 0 {
+		return services.NewTokenManager(cfg.Secret, cfg.TTL, cfg.Issuer, cfg.Audience), nil
+	}
+
+	keys := make([]services.SigningKey, 0, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		loaded, err := services.LoadSigningKey(key.ID, key.File, key.ActiveFrom)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, loaded)
+	}
+	return services.NewKeyedTokenManager(keys, cfg.Secret, cfg.AcceptHS256Until, cfg.TTL, cfg.Issuer, cfg.Audience)
+}
+
+// purgeIdempotencyKeys deletes expired idempotency records; expired keys are already reusable,
+// this only keeps the table small.
+func purgeIdempotencyKeys(ctx context.Context, repo repository.IdempotencyRepositoryInterface, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := repo.DeleteExpired(ctx); err != nil {
+				slog.ErrorContext(ctx, "failed to purge idempotency keys", slog.Any("error", err))
+			}
+		}
 	}
-	log.Println("Server exiting")
 }
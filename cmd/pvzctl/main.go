@@ -0,0 +1,377 @@
+// Command pvzctl runs operational tasks against the service database. It goes through the same
+// repositories and services as the API, so it keeps their validation and writes outbox events.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"pvz/internal/config"
+	"pvz/internal/data"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const usage = `usage: pvzctl [config flags] <command> [flags]
+
+commands:
+  user create -email EMAIL -role employee|moderator [-password PASSWORD]
+  user reset-password -email EMAIL [-password PASSWORD]
+  user unlock -email EMAIL
+  user assign-pvz -email EMAIL -pvz PVZ_ID
+  user unassign-pvz -email EMAIL -pvz PVZ_ID
+  pvz create -city CITY
+  pvz dump -id PVZ_ID
+  reception close -pvz PVZ_ID -by EMAIL
+
+Passwords not given with -password are read from the first line of stdin.
+Config flags are the ones pvz-service accepts (-config, -db-host, ...).`
+
+type app struct {
+	userService      services.UserServiceInterface
+	pvzService       services.PVZServiceInterface
+	receptionService services.ReceptionServiceInterface
+	userRepo         repository.UserRepositoryInterface
+	pvzRepo          repository.PVZRepositoryInterface
+
+	in  io.Reader
+	out io.Writer
+}
+
+type command func(ctx context.Context, a *app, args []string) error
+
+var commands = map[string]command{
+	"user create":         createUser,
+	"user reset-password": resetPassword,
+	"user unlock":         unlockUser,
+	"user assign-pvz":     assignPVZ,
+	"user unassign-pvz":   unassignPVZ,
+	"pvz create":          createPVZ,
+	"pvz dump":            dumpPVZ,
+	"reception close":     closeReception,
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "pvzctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	cfg, rest, err := config.Parse("pvzctl", args)
+	if err != nil {
+		return err
+	}
+	cmd, cmdArgs, err := lookup(rest)
+	if err != nil {
+		return err
+	}
+
+	if err := data.InitDB(cfg.DB); err != nil {
+		return err
+	}
+	defer data.CloseDB()
+
+	userRepo := repository.NewUserRepository(data.DB)
+	pvzRepo := repository.NewPWZRepository(data.DB)
+	policy := services.PasswordPolicy{MinLength: cfg.Password.MinLength, RequiredClasses: cfg.Password.RequiredClasses}
+	// pvzctl exports no metrics; pvz_receptions_open is read from the database, so it reflects a close from here
+	receptionService := services.NewReceptionService(repository.NewReceptionRepository(data.DB), services.NopObserver{})
+	a := &app{
+		// pvzctl never issues tokens or logs users in
+		userService:      services.NewUserService(userRepo, nil, nil, services.Lockout{}, policy, cfg.Limits.UserListMax, false),
+		pvzService:       services.NewPVZService(pvzRepo, cfg.Limits.PVZListMax, services.NopObserver{}),
+		receptionService: receptionService,
+		userRepo:         userRepo,
+		pvzRepo:          pvzRepo,
+		in:               os.Stdin,
+		out:              os.Stdout,
+	}
+	return cmd(ctx, a, cmdArgs)
+}
+
+// lookup finds the command named by the first two arguments and returns it with the arguments left.
+func lookup(args []string) (command, []string, error) {
+	if len(args) < 2 {
+		return nil, nil, errors.New(usage)
+	}
+	cmd, ok := commands[args[0]+" "+args[1]]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown command %q\n%s", args[0]+" "+args[1], usage)
+	}
+	return cmd, args[2:], nil
+}
+
+func createUser(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	email := fs.String("email", "", "user email")
+	role := fs.String("role", "", "employee or moderator")
+	password := fs.String("password", "", "password, read from stdin when empty")
+	if err := parseFlags(fs, args, "email", "role"); err != nil {
+		return err
+	}
+	if err := readPassword(a.in, password); err != nil {
+		return err
+	}
+
+	// whoever runs pvzctl has the database at hand, so it may create moderators like a moderator could
+	user, err := a.userService.RegisterUser(ctx, *email, *password, *role, services.RegistrationAuth{CallerRole: "moderator"})
+	if err != nil {
+		return err
+	}
+	return a.print(user)
+}
+
+func resetPassword(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	email := fs.String("email", "", "user email")
+	password := fs.String("password", "", "new password, read from stdin when empty")
+	if err := parseFlags(fs, args, "email"); err != nil {
+		return err
+	}
+	if err := readPassword(a.in, password); err != nil {
+		return err
+	}
+
+	if err := a.userService.ResetPassword(ctx, *email, *password); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "password of %s reset\n", *email)
+	return nil
+}
+
+func unlockUser(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("user unlock", flag.ContinueOnError)
+	email := fs.String("email", "", "user email")
+	if err := parseFlags(fs, args, "email"); err != nil {
+		return err
+	}
+
+	user, err := a.userRepo.GetUserByEmail(ctx, *email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("%w: %s", services.ErrUserNotFound, *email)
+	}
+
+	if err := a.userService.UnlockUser(ctx, user.ID, "moderator"); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "%s unlocked\n", *email)
+	return nil
+}
+
+// assignPVZ adds a PVZ to the ones the user works at, which GET /me lists.
+func assignPVZ(ctx context.Context, a *app, args []string) error {
+	user, pvzID, err := a.userAndPVZ(ctx, "user assign-pvz", args)
+	if err != nil {
+		return err
+	}
+
+	if err := a.userRepo.AssignPVZ(ctx, user.ID, pvzID); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "%s assigned to %s\n", user.Email, pvzID)
+	return nil
+}
+
+func unassignPVZ(ctx context.Context, a *app, args []string) error {
+	user, pvzID, err := a.userAndPVZ(ctx, "user unassign-pvz", args)
+	if err != nil {
+		return err
+	}
+
+	if err := a.userRepo.UnassignPVZ(ctx, user.ID, pvzID); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "%s unassigned from %s\n", user.Email, pvzID)
+	return nil
+}
+
+// userAndPVZ parses the -email and -pvz flags of the assignment commands and looks the user up.
+func (a *app) userAndPVZ(ctx context.Context, name string, args []string) (*models.User, uuid.UUID, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	email := fs.String("email", "", "user email")
+	id := fs.String("pvz", "", "PVZ id")
+	if err := parseFlags(fs, args, "email", "pvz"); err != nil {
+		return nil, uuid.Nil, err
+	}
+	pvzID, err := uuid.Parse(*id)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("invalid -pvz: %w", err)
+	}
+
+	user, err := a.userRepo.GetUserByEmail(ctx, *email)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	if user == nil {
+		return nil, uuid.Nil, fmt.Errorf("%w: %s", services.ErrUserNotFound, *email)
+	}
+	return user, pvzID, nil
+}
+
+func createPVZ(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("pvz create", flag.ContinueOnError)
+	city := fs.String("city", "", strings.Join(services.Cities(), ", "))
+	if err := parseFlags(fs, args, "city"); err != nil {
+		return err
+	}
+
+	pvz, err := a.pvzService.CreatePVZ(ctx, *city, "moderator")
+	if err != nil {
+		return err
+	}
+	return a.print(pvz)
+}
+
+// pvzDump is what pvz dump prints. Unlike the API responses it keeps the reception and product ids,
+// so every product can be traced to its reception.
+type pvzDump struct {
+	ID               uuid.UUID       `json:"id"`
+	City             string          `json:"city"`
+	RegistrationDate time.Time       `json:"registrationDate"`
+	Receptions       []receptionDump `json:"receptions"`
+}
+
+type receptionDump struct {
+	ID        uuid.UUID     `json:"id"`
+	DateTime  time.Time     `json:"dateTime"`
+	Status    string        `json:"status"`
+	CreatedBy uuid.UUID     `json:"createdBy"`
+	ClosedBy  *uuid.UUID    `json:"closedBy,omitempty"`
+	Products  []productDump `json:"products"`
+}
+
+type productDump struct {
+	ID          uuid.UUID `json:"id"`
+	DateTime    time.Time `json:"dateTime"`
+	Type        string    `json:"type"`
+	ReceptionID uuid.UUID `json:"receptionId"`
+	CreatedBy   uuid.UUID `json:"createdBy"`
+}
+
+func newPVZDump(pvz *models.PVZWithReceptions) pvzDump {
+	dump := pvzDump{ID: pvz.ID, City: pvz.City, RegistrationDate: pvz.RegistrationDate, Receptions: []receptionDump{}}
+	for _, reception := range pvz.Receptions {
+		products := make([]productDump, 0, len(reception.Products))
+		for _, product := range reception.Products {
+			products = append(products, productDump{
+				ID:          product.ID,
+				DateTime:    product.DateTime,
+				Type:        product.ProductType,
+				ReceptionID: product.ReceptionID,
+				CreatedBy:   product.CreatedBy,
+			})
+		}
+		dump.Receptions = append(dump.Receptions, receptionDump{
+			ID:        reception.ID,
+			DateTime:  reception.DateTime,
+			Status:    reception.Status,
+			CreatedBy: reception.CreatedBy,
+			ClosedBy:  reception.ClosedBy,
+			Products:  products,
+		})
+	}
+	return dump
+}
+
+// dumpPVZ prints a PVZ with all of its receptions and their products.
+func dumpPVZ(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("pvz dump", flag.ContinueOnError)
+	id := fs.String("id", "", "PVZ id")
+	if err := parseFlags(fs, args, "id"); err != nil {
+		return err
+	}
+	pvzID, err := uuid.Parse(*id)
+	if err != nil {
+		return fmt.Errorf("invalid -id: %w", err)
+	}
+
+	pvz, err := a.pvzRepo.GetPVZ(ctx, pvzID)
+	if err != nil {
+		return err
+	}
+	return a.print(newPVZDump(pvz))
+}
+
+// closeReception closes the open reception of a PVZ on behalf of the given user, e.g. when the
+// employee who opened it is unavailable.
+func closeReception(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("reception close", flag.ContinueOnError)
+	id := fs.String("pvz", "", "PVZ id")
+	by := fs.String("by", "", "email of the user recorded as closing the reception")
+	if err := parseFlags(fs, args, "pvz", "by"); err != nil {
+		return err
+	}
+	pvzID, err := uuid.Parse(*id)
+	if err != nil {
+		return fmt.Errorf("invalid -pvz: %w", err)
+	}
+
+	user, err := a.userRepo.GetUserByEmail(ctx, *by)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("%w: %s", services.ErrUserNotFound, *by)
+	}
+
+	reception, err := a.receptionService.ForceCloseReception(ctx, pvzID, user.ID)
+	if err != nil {
+		return err
+	}
+	return a.print(reception)
+}
+
+// parseFlags parses args and checks that the named flags were given.
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	for _, name := range required {
+		if fs.Lookup(name).Value.String() == "" {
+			return fmt.Errorf("-%s is required", name)
+		}
+	}
+	return nil
+}
+
+// readPassword fills an empty password from the first line of in, so it stays out of shell history.
+func readPassword(in io.Reader, password *string) error {
+	if *password != "" {
+		return nil
+	}
+	scanner := bufio.NewScanner(in)
+	if scanner.Scan() {
+		*password = strings.TrimRight(scanner.Text(), "\r")
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+	if *password == "" {
+		return errors.New("password is required")
+	}
+	return nil
+}
+
+func (a *app) print(v interface{}) error {
+	encoder := json.NewEncoder(a.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
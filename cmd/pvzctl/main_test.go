@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// The user service and repositories have many methods pvzctl never calls; the mocks embed their
+// interfaces, so calling one of those panics instead of passing silently.
+
+type MockUserService struct {
+	services.UserServiceInterface
+	mock.Mock
+}
+
+func (m *MockUserService) RegisterUser(ctx context.Context, email, password, role string, auth services.RegistrationAuth) (models.User, error) {
+	args := m.Called(ctx, email, password, role, auth)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockUserService) ResetPassword(ctx context.Context, email, password string) error {
+	return m.Called(ctx, email, password).Error(0)
+}
+
+func (m *MockUserService) UnlockUser(ctx context.Context, id uuid.UUID, role string) error {
+	return m.Called(ctx, id, role).Error(0)
+}
+
+type MockUserRepository struct {
+	repository.UserRepositoryInterface
+	mock.Mock
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) AssignPVZ(ctx context.Context, id, pvzID uuid.UUID) error {
+	return m.Called(ctx, id, pvzID).Error(0)
+}
+
+func (m *MockUserRepository) UnassignPVZ(ctx context.Context, id, pvzID uuid.UUID) error {
+	return m.Called(ctx, id, pvzID).Error(0)
+}
+
+type MockPVZService struct {
+	services.PVZServiceInterface
+	mock.Mock
+}
+
+func (m *MockPVZService) CreatePVZ(ctx context.Context, city, role string) (models.PVZ, error) {
+	args := m.Called(ctx, city, role)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+type MockPVZRepository struct {
+	repository.PVZRepositoryInterface
+	mock.Mock
+}
+
+func (m *MockPVZRepository) GetPVZ(ctx context.Context, id uuid.UUID) (*models.PVZWithReceptions, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.PVZWithReceptions), args.Error(1)
+}
+
+type MockReceptionService struct {
+	services.ReceptionServiceInterface
+	mock.Mock
+}
+
+func (m *MockReceptionService) ForceCloseReception(ctx context.Context, pvzID, closedBy uuid.UUID) (models.Reception, error) {
+	args := m.Called(ctx, pvzID, closedBy)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
+type testApp struct {
+	*app
+	userService      *MockUserService
+	userRepo         *MockUserRepository
+	pvzService       *MockPVZService
+	pvzRepo          *MockPVZRepository
+	receptionService *MockReceptionService
+	out              *bytes.Buffer
+}
+
+func newTestApp(stdin string) *testApp {
+	ctl := &testApp{
+		userService:      new(MockUserService),
+		userRepo:         new(MockUserRepository),
+		pvzService:       new(MockPVZService),
+		pvzRepo:          new(MockPVZRepository),
+		receptionService: new(MockReceptionService),
+		out:              new(bytes.Buffer),
+	}
+	ctl.app = &app{
+		userService:      ctl.userService,
+		pvzService:       ctl.pvzService,
+		receptionService: ctl.receptionService,
+		userRepo:         ctl.userRepo,
+		pvzRepo:          ctl.pvzRepo,
+		in:               strings.NewReader(stdin),
+		out:              ctl.out,
+	}
+	return ctl
+}
+
+// exec runs a command line the way run does once the database is open.
+func (ctl *testApp) exec(args ...string) error {
+	cmd, rest, err := lookup(args)
+	if err != nil {
+		return err
+	}
+	return cmd(context.Background(), ctl.app, rest)
+}
+
+func TestLookup(t *testing.T) {
+	t.Run("known command", func(t *testing.T) {
+		cmd, rest, err := lookup([]string{"pvz", "dump", "-id", "42"})
+
+		require.NoError(t, err)
+		assert.NotNil(t, cmd)
+		assert.Equal(t, []string{"-id", "42"}, rest)
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		_, _, err := lookup([]string{"pvz", "delete"})
+		assert.ErrorContains(t, err, `unknown command "pvz delete"`)
+	})
+
+	t.Run("no command", func(t *testing.T) {
+		_, _, err := lookup([]string{"user"})
+		assert.ErrorContains(t, err, "usage: pvzctl")
+	})
+}
+
+func TestCommandFlags(t *testing.T) {
+	for name, args := range map[string][]string{
+		"missing required flag": {"user", "create", "-email", "ops@example.com"},
+		"unexpected argument":   {"pvz", "create", "-city", "Казань", "extra"},
+		"unknown flag":          {"user", "unlock", "-email", "ops@example.com", "-force"},
+		"invalid uuid":          {"pvz", "dump", "-id", "not-a-uuid"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctl := newTestApp("")
+
+			assert.Error(t, ctl.exec(args...))
+			// nothing is called on the mocks, which would panic on a missing expectation
+		})
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	t.Run("password from stdin", func(t *testing.T) {
+		ctl := newTestApp("s3cret-Password\r\n")
+		user := models.User{ID: uuid.New(), Email: "ops@example.com", Role: "moderator"}
+		ctl.userService.On("RegisterUser", mock.Anything, "ops@example.com", "s3cret-Password", "moderator",
+			services.RegistrationAuth{CallerRole: "moderator"}).Return(user, nil)
+
+		err := ctl.exec("user", "create", "-email", "ops@example.com", "-role", "moderator")
+
+		require.NoError(t, err)
+		assert.Contains(t, ctl.out.String(), `"email": "ops@example.com"`)
+		ctl.userService.AssertExpectations(t)
+	})
+
+	t.Run("empty password", func(t *testing.T) {
+		ctl := newTestApp("")
+
+		err := ctl.exec("user", "create", "-email", "ops@example.com", "-role", "employee")
+		assert.ErrorContains(t, err, "password is required")
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctl := newTestApp("")
+	ctl.userService.On("ResetPassword", mock.Anything, "ops@example.com", "new-secret").Return(nil)
+
+	err := ctl.exec("user", "reset-password", "-email", "ops@example.com", "-password", "new-secret")
+
+	require.NoError(t, err)
+	assert.Equal(t, "password of ops@example.com reset\n", ctl.out.String())
+}
+
+func TestUnlockUser(t *testing.T) {
+	t.Run("unlocks", func(t *testing.T) {
+		ctl := newTestApp("")
+		user := &models.User{ID: uuid.New(), Email: "ops@example.com"}
+		ctl.userRepo.On("GetUserByEmail", mock.Anything, "ops@example.com").Return(user, nil)
+		ctl.userService.On("UnlockUser", mock.Anything, user.ID, "moderator").Return(nil)
+
+		require.NoError(t, ctl.exec("user", "unlock", "-email", "ops@example.com"))
+		ctl.userService.AssertExpectations(t)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		ctl := newTestApp("")
+		ctl.userRepo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return((*models.User)(nil), nil)
+
+		err := ctl.exec("user", "unlock", "-email", "nobody@example.com")
+		assert.ErrorIs(t, err, services.ErrUserNotFound)
+	})
+}
+
+func TestAssignPVZ(t *testing.T) {
+	ctl := newTestApp("")
+	user := &models.User{ID: uuid.New(), Email: "ops@example.com"}
+	pvzID := uuid.New()
+	ctl.userRepo.On("GetUserByEmail", mock.Anything, "ops@example.com").Return(user, nil)
+	ctl.userRepo.On("AssignPVZ", mock.Anything, user.ID, pvzID).Return(nil)
+	ctl.userRepo.On("UnassignPVZ", mock.Anything, user.ID, pvzID).Return(nil)
+
+	require.NoError(t, ctl.exec("user", "assign-pvz", "-email", "ops@example.com", "-pvz", pvzID.String()))
+	require.NoError(t, ctl.exec("user", "unassign-pvz", "-email", "ops@example.com", "-pvz", pvzID.String()))
+	ctl.userRepo.AssertExpectations(t)
+}
+
+func TestCreatePVZ(t *testing.T) {
+	ctl := newTestApp("")
+	pvz := models.PVZ{ID: uuid.New(), City: "Казань"}
+	ctl.pvzService.On("CreatePVZ", mock.Anything, "Казань", "moderator").Return(pvz, nil)
+
+	require.NoError(t, ctl.exec("pvz", "create", "-city", "Казань"))
+	assert.Contains(t, ctl.out.String(), pvz.ID.String())
+}
+
+func TestDumpPVZ(t *testing.T) {
+	ctl := newTestApp("")
+	pvzID, receptionID, productID, userID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	ctl.pvzRepo.On("GetPVZ", mock.Anything, pvzID).Return(&models.PVZWithReceptions{
+		ID:   pvzID,
+		City: "Казань",
+		Receptions: []models.ReceptionWithProducts{{
+			Reception: models.Reception{ID: receptionID, PVZID: pvzID, Status: models.ReceptionStatusInProgress, CreatedBy: userID},
+			Products:  []models.Product{{ID: productID, ProductType: "обувь", ReceptionID: receptionID, CreatedBy: userID, DateTime: time.Now()}},
+		}},
+	}, nil)
+
+	require.NoError(t, ctl.exec("pvz", "dump", "-id", pvzID.String()))
+
+	var dump pvzDump
+	require.NoError(t, json.Unmarshal(ctl.out.Bytes(), &dump))
+	require.Len(t, dump.Receptions, 1)
+	assert.Equal(t, receptionID, dump.Receptions[0].ID)
+	require.Len(t, dump.Receptions[0].Products, 1)
+	assert.Equal(t, productID, dump.Receptions[0].Products[0].ID)
+	assert.Equal(t, receptionID, dump.Receptions[0].Products[0].ReceptionID)
+}
+
+func TestCloseReception(t *testing.T) {
+	ctl := newTestApp("")
+	user := &models.User{ID: uuid.New(), Email: "ops@example.com"}
+	pvzID := uuid.New()
+	ctl.userRepo.On("GetUserByEmail", mock.Anything, "ops@example.com").Return(user, nil)
+	ctl.receptionService.On("ForceCloseReception", mock.Anything, pvzID, user.ID).
+		Return(models.Reception{PVZID: pvzID, Status: models.ReceptionStatusClosed, ClosedBy: &user.ID}, nil)
+
+	require.NoError(t, ctl.exec("reception", "close", "-pvz", pvzID.String(), "-by", "ops@example.com"))
+	assert.Contains(t, ctl.out.String(), `"status": "close"`)
+	ctl.receptionService.AssertExpectations(t)
+}
@@ -0,0 +1,675 @@
+// Package config loads the service configuration once at startup. Values come from, in increasing
+// priority: built-in defaults, dotenv files (.env and .env.secret, or the files given with -config),
+// environment variables and command-line flags. Every setting has an environment name such as
+// DB_MAX_OPEN_CONNS and a flag derived from it (-db-max-open-conns).
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type Config struct {
+	HTTP        HTTPConfig
+	GRPC        GRPCConfig
+	DB          DBConfig
+	JWT         JWTConfig
+	Limits      LimitsConfig
+	Outbox      OutboxConfig
+	Idempotency IdempotencyConfig
+	Health      HealthConfig
+	Tracing     TracingConfig
+	Log         LogConfig
+	RateLimit   RateLimitConfig
+	Lockout     LockoutConfig
+	Password    PasswordConfig
+	Notify      NotifyConfig
+	Auth        AuthConfig
+}
+
+type HTTPConfig struct {
+	Port              int
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	// WriteTimeout does not apply to event streams, which lift the deadline for themselves.
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	// TrustedProxies are the addresses or CIDRs whose X-Forwarded-For is believed when the client IP
+	// is determined; with none the peer address is the client.
+	TrustedProxies []string
+}
+
+type GRPCConfig struct {
+	Port int
+}
+
+type DBConfig struct {
+	Host            string
+	Port            int
+	User            string
+	Password        string
+	Name            string
+	SSLMode         string
+	ConnectTimeout  time.Duration
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// AutoMigrate applies pending migrations at startup; `pvz-service migrate` does it on demand.
+	AutoMigrate bool
+}
+
+type JWTConfig struct {
+	// Secret signs HS256 tokens when there are no Keys. Next to Keys it is ignored unless AcceptHS256Until
+	// is set.
+	Secret string
+	// Keys are the RS256 and EdDSA keys tokens are signed with once any is configured.
+	Keys []JWTKey
+	TTL  time.Duration
+	// Issuer and Audience go into the iss and aud claims, and tokens with other values are refused.
+	Issuer   string
+	Audience string
+	// AcceptHS256Until keeps HS256 tokens issued before it valid next to Keys until they expire, so the
+	// switch to keys does not log everyone out. Zero refuses HS256 tokens once Keys are configured.
+	AcceptHS256Until time.Time
+}
+
+// JWTKey is a private key in a PEM file. It signs tokens from ActiveFrom, or from the start when that is
+// zero, until a key with a later ActiveFrom takes over.
+type JWTKey struct {
+	ID         string
+	File       string
+	ActiveFrom time.Time
+}
+
+type LimitsConfig struct {
+	PVZListDefault  int
+	PVZListMax      int
+	UserListDefault int
+	UserListMax     int
+}
+
+type OutboxConfig struct {
+	Publisher  string
+	File       string
+	WebhookURL string
+}
+
+type IdempotencyConfig struct {
+	TTL time.Duration
+	// LockTTL bounds how long a request holds its key; it frees keys of requests that never finish,
+	// e.g. after a crash, and has to outlast the slowest request.
+	LockTTL time.Duration
+}
+
+type HealthConfig struct {
+	// ReadinessTimeout bounds the database ping of /readyz.
+	ReadinessTimeout time.Duration
+}
+
+type TracingConfig struct {
+	// Exporter is none, stdout, file or otlp.
+	Exporter string
+	File     string
+	// OTLPEndpoint is an http(s) URL of an OTLP/HTTP collector; when empty the standard
+	// OTEL_EXPORTER_OTLP_* variables apply.
+	OTLPEndpoint string
+	// SampleRatio is the share of new traces recorded; requests with a sampled parent are always recorded.
+	SampleRatio float64
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error.
+	Level string
+	// Format is json or text.
+	Format string
+}
+
+// RateLimitConfig sets the token bucket of each route group. Authenticated requests are counted
+// per user, the others per client IP.
+type RateLimitConfig struct {
+	// Auth covers /login, /register and /dummyLogin.
+	Auth Rate
+	// Read covers authenticated GET requests and /dictionaries.
+	Read Rate
+	// Write covers authenticated POST, PUT and DELETE requests.
+	Write Rate
+}
+
+// LockoutConfig locks an account for Duration after MaxAttempts wrong passwords in a row.
+type LockoutConfig struct {
+	// MaxAttempts of 0 disables lockouts; attempts are recorded anyway.
+	MaxAttempts int
+	Duration    time.Duration
+}
+
+type PasswordConfig struct {
+	MinLength int
+	// RequiredClasses are the character classes every new password needs: lower, upper, digit, symbol.
+	RequiredClasses []string
+	// ResetTTL is how long a password reset token stays valid.
+	ResetTTL time.Duration
+}
+
+type NotifyConfig struct {
+	// Notifier is log or file, which hand reset tokens to whoever reads them and so need DevMode, or empty,
+	// which switches password reset off. A mail or SMS notifier plugs in the same way.
+	Notifier string
+	File     string
+}
+
+// AuthConfig controls the ways to get an account or a token besides /login.
+type AuthConfig struct {
+	// DevMode enables /dummyLogin, which issues a token for any role without a user behind it.
+	DevMode bool
+	// InvitationTTL is how long a moderator invitation stays valid.
+	InvitationTTL time.Duration
+	// APIKeyRotationGrace is how long the old secret of a rotated API key keeps working.
+	APIKeyRotationGrace time.Duration
+}
+
+// Rate allows Requests per Period, in bursts of up to Requests. The zero Rate is unlimited.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Unlimited reports whether the rate disables limiting.
+func (r Rate) Unlimited() bool {
+	return r.Requests == 0
+}
+
+// ParseRate reads "<requests>/<period>", e.g. "10/1m" or "5/s", or "off".
+func ParseRate(value string) (Rate, error) {
+	value = strings.TrimSpace(value)
+	if value == "off" || value == "0" {
+		return Rate{}, nil
+	}
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("not a rate: %q", value)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Rate{}, fmt.Errorf("not a rate: %q", value)
+	}
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Rate{}, fmt.Errorf("not a rate: %q", value)
+	}
+	return Rate{Requests: n, Period: d}, nil
+}
+
+// ParseJWTKeys reads comma-separated "<kid>=<file>[@<time>]", e.g.
+// "2026-10=/keys/a.pem,2026-11=/keys/b.pem@2026-11-01T00:00:00Z".
+func ParseJWTKeys(value string) ([]JWTKey, error) {
+	var keys []JWTKey
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		id, file, ok := strings.Cut(item, "=")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("not a kid=file pair: %q", item)
+		}
+		key := JWTKey{ID: id, File: file}
+		if file, activeFrom, ok := strings.Cut(file, "@"); ok {
+			t, err := time.Parse(time.RFC3339, activeFrom)
+			if err != nil {
+				return nil, fmt.Errorf("not an RFC 3339 time in %q", item)
+			}
+			key.File, key.ActiveFrom = file, t
+		}
+		if key.File == "" {
+			return nil, fmt.Errorf("no file for key %q", id)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// DSN returns the lib/pq connection string. Values are quoted, so a password with spaces, quotes or
+// backslashes stays one value.
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s connect_timeout=%d",
+		dsnQuote(c.Host), c.Port, dsnQuote(c.User), dsnQuote(c.Password), dsnQuote(c.Name), dsnQuote(c.SSLMode),
+		int(c.ConnectTimeout.Seconds()))
+}
+
+var dsnEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+func dsnQuote(value string) string {
+	return "'" + dsnEscaper.Replace(value) + "'"
+}
+
+// Default returns the configuration used when nothing overrides it. The JWT secret has no default.
+func Default() *Config {
+	return &Config{
+		HTTP: HTTPConfig{
+			Port:              8080,
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   5 * time.Second,
+		},
+		GRPC: GRPCConfig{Port: 9090},
+		DB: DBConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "pvz",
+			SSLMode:         "disable",
+			ConnectTimeout:  5 * time.Second,
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		JWT:         JWTConfig{TTL: 24 * time.Hour, Issuer: "pvz-service", Audience: "pvz-api"},
+		Limits:      LimitsConfig{PVZListDefault: 10, PVZListMax: 30, UserListDefault: 10, UserListMax: 30},
+		Outbox:      OutboxConfig{Publisher: "stdout", File: "events.jsonl"},
+		Idempotency: IdempotencyConfig{TTL: 24 * time.Hour, LockTTL: time.Minute},
+		Health:      HealthConfig{ReadinessTimeout: 2 * time.Second},
+		Tracing:     TracingConfig{Exporter: "none", File: "traces.jsonl", SampleRatio: 1},
+		Log:         LogConfig{Level: "info", Format: "json"},
+		RateLimit: RateLimitConfig{
+			Auth:  Rate{Requests: 10, Period: time.Minute},
+			Read:  Rate{Requests: 50, Period: time.Second},
+			Write: Rate{Requests: 20, Period: time.Second},
+		},
+		Lockout: LockoutConfig{MaxAttempts: 5, Duration: 15 * time.Minute},
+		Password: PasswordConfig{
+			MinLength:       8,
+			RequiredClasses: []string{"lower", "upper", "digit"},
+			ResetTTL:        time.Hour,
+		},
+		Notify: NotifyConfig{File: "notifications.jsonl"},
+		Auth:   AuthConfig{InvitationTTL: 72 * time.Hour, APIKeyRotationGrace: 24 * time.Hour},
+	}
+}
+
+// defaultFiles are read when -config is not given; unlike explicit files they may be missing.
+var defaultFiles = []string{".env", ".env.secret"}
+
+// Load builds and validates the server configuration. args are the command-line arguments without the program name.
+func Load(args []string) (*Config, error) {
+	cfg, rest, err := Parse("pvz-service", args)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Parse builds the configuration from the leading flags of args and returns the arguments after them.
+// It does not validate, so tools that need only part of the configuration can check what they use.
+func Parse(name string, args []string) (*Config, []string, error) {
+	cfg := Default()
+	settings := cfg.settings()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFiles := fs.String("config", "", "comma-separated dotenv files to load instead of .env and .env.secret")
+	flagValues := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flagValues[s.env] = fs.String(flagName(s.env), "", s.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	values, err := readFiles(*configFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			values[s.env] = value
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if flagName(s.env) == f.Name {
+				values[s.env] = *flagValues[s.env]
+			}
+		}
+	})
+
+	var errs []error
+	for _, s := range settings {
+		value, ok := values[s.env]
+		if !ok {
+			continue
+		}
+		if err := s.set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	return cfg, fs.Args(), nil
+}
+
+// Validate reports every invalid setting at once, so a bad deployment fails at startup with a full list.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.HTTP.Port), "SERVER_PORT must be between 1 and 65535")
+	check(validPort(c.GRPC.Port), "GRPC_PORT must be between 1 and 65535")
+	check(c.HTTP.Port != c.GRPC.Port, "SERVER_PORT and GRPC_PORT must differ")
+	check(c.HTTP.ReadHeaderTimeout > 0, "HTTP_READ_HEADER_TIMEOUT must be positive")
+	check(c.HTTP.ReadTimeout >= 0 && c.HTTP.WriteTimeout >= 0 && c.HTTP.IdleTimeout >= 0, "HTTP timeouts must not be negative")
+	check(c.HTTP.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+	for _, proxy := range c.HTTP.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "HTTP_TRUSTED_PROXIES: %q is neither an IP nor a CIDR", proxy)
+	}
+
+	check(c.DB.Host != "", "DB_HOST is required")
+	check(validPort(c.DB.Port), "DB_PORT must be between 1 and 65535")
+	check(c.DB.User != "", "DB_USER is required")
+	check(c.DB.Name != "", "DB_NAME is required")
+	check(c.DB.ConnectTimeout >= time.Second, "DB_CONNECT_TIMEOUT must be at least 1s")
+	check(c.DB.MaxOpenConns > 0, "DB_MAX_OPEN_CONNS must be positive")
+	// database/sql lowers the idle limit to DB_MAX_OPEN_CONNS by itself
+	check(c.DB.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	check(c.DB.ConnMaxLifetime >= 0 && c.DB.ConnMaxIdleTime >= 0, "DB connection lifetimes must not be negative")
+
+	check(c.JWT.Secret != "" || len(c.JWT.Keys) > 0, "JWT_SECRET or JWT_KEYS is required")
+	kids := make(map[string]bool)
+	for _, key := range c.JWT.Keys {
+		check(!kids[key.ID], "JWT_KEYS: key id %q is used twice", key.ID)
+		kids[key.ID] = true
+	}
+	check(c.JWT.AcceptHS256Until.IsZero() || c.JWT.Secret != "" && len(c.JWT.Keys) > 0,
+		"JWT_ACCEPT_HS256_UNTIL needs both JWT_SECRET and JWT_KEYS")
+	check(c.JWT.TTL > 0, "JWT_TTL must be positive")
+	check(c.JWT.Issuer != "" && c.JWT.Audience != "", "JWT_ISSUER and JWT_AUDIENCE are required")
+
+	check(c.Limits.PVZListMax > 0, "PVZ_LIST_MAX_LIMIT must be positive")
+	check(c.Limits.PVZListDefault > 0 && c.Limits.PVZListDefault <= c.Limits.PVZListMax,
+		"PVZ_LIST_DEFAULT_LIMIT must be between 1 and PVZ_LIST_MAX_LIMIT")
+	check(c.Limits.UserListMax > 0, "USER_LIST_MAX_LIMIT must be positive")
+	check(c.Limits.UserListDefault > 0 && c.Limits.UserListDefault <= c.Limits.UserListMax,
+		"USER_LIST_DEFAULT_LIMIT must be between 1 and USER_LIST_MAX_LIMIT")
+
+	switch c.Outbox.Publisher {
+	case "stdout":
+	case "file":
+		check(c.Outbox.File != "", "OUTBOX_FILE is required for the file publisher")
+	case "webhook":
+		check(c.Outbox.WebhookURL != "", "OUTBOX_WEBHOOK_URL is required for the webhook publisher")
+	default:
+		check(false, "OUTBOX_PUBLISHER must be stdout, file or webhook, got %q", c.Outbox.Publisher)
+	}
+
+	check(c.Idempotency.TTL > 0, "IDEMPOTENCY_TTL must be positive")
+	check(c.Idempotency.LockTTL > 0 && c.Idempotency.LockTTL <= c.Idempotency.TTL,
+		"IDEMPOTENCY_LOCK_TTL must be positive and at most IDEMPOTENCY_TTL")
+	check(c.Health.ReadinessTimeout > 0, "READINESS_TIMEOUT must be positive")
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	case "file":
+		check(c.Tracing.File != "", "TRACING_FILE is required for the file exporter")
+	default:
+		check(false, "TRACING_EXPORTER must be none, stdout, file or otlp, got %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	check(c.Log.Format == "json" || c.Log.Format == "text", "LOG_FORMAT must be json or text, got %q", c.Log.Format)
+
+	check(c.Lockout.MaxAttempts >= 0, "LOCKOUT_MAX_ATTEMPTS must not be negative")
+	check(c.Lockout.MaxAttempts == 0 || c.Lockout.Duration > 0, "LOCKOUT_DURATION must be positive")
+
+	// bcrypt ignores everything after 72 bytes
+	check(c.Password.MinLength > 0 && c.Password.MinLength <= 72, "PASSWORD_MIN_LENGTH must be between 1 and 72")
+	for _, class := range c.Password.RequiredClasses {
+		switch class {
+		case "lower", "upper", "digit", "symbol":
+		default:
+			check(false, "PASSWORD_REQUIRED_CLASSES: unknown class %q, use lower, upper, digit or symbol", class)
+		}
+	}
+	check(c.Password.ResetTTL > 0, "PASSWORD_RESET_TTL must be positive")
+
+	switch c.Notify.Notifier {
+	case "":
+	case "log", "file":
+		check(c.Auth.DevMode, "NOTIFIER=%s exposes password reset tokens and is allowed only with DEV_MODE", c.Notify.Notifier)
+		check(c.Notify.Notifier != "file" || c.Notify.File != "", "NOTIFIER_FILE is required for the file notifier")
+	default:
+		check(false, "NOTIFIER must be empty, log or file, got %q", c.Notify.Notifier)
+	}
+
+	check(c.Auth.InvitationTTL > 0, "INVITATION_TTL must be positive")
+	check(c.Auth.APIKeyRotationGrace >= 0, "API_KEY_ROTATION_GRACE must not be negative")
+
+	return errors.Join(errs...)
+}
+
+type setting struct {
+	env   string
+	usage string
+	set   func(string) error
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		intSetting("SERVER_PORT", "HTTP port", &c.HTTP.Port),
+		durationSetting("HTTP_READ_HEADER_TIMEOUT", "time to read request headers", &c.HTTP.ReadHeaderTimeout),
+		durationSetting("HTTP_READ_TIMEOUT", "time to read a whole request, 0 disables", &c.HTTP.ReadTimeout),
+		durationSetting("HTTP_WRITE_TIMEOUT", "time to write a response, 0 disables", &c.HTTP.WriteTimeout),
+		durationSetting("HTTP_IDLE_TIMEOUT", "keep-alive idle time, 0 disables", &c.HTTP.IdleTimeout),
+		durationSetting("SHUTDOWN_TIMEOUT", "graceful shutdown time", &c.HTTP.ShutdownTimeout),
+		listSetting("HTTP_TRUSTED_PROXIES", "comma-separated proxy IPs or CIDRs allowed to set X-Forwarded-For", &c.HTTP.TrustedProxies),
+		intSetting("GRPC_PORT", "gRPC port", &c.GRPC.Port),
+		stringSetting("DB_HOST", "PostgreSQL host", &c.DB.Host),
+		intSetting("DB_PORT", "PostgreSQL port", &c.DB.Port),
+		stringSetting("DB_USER", "PostgreSQL user", &c.DB.User),
+		stringSetting("DB_PASSWORD", "PostgreSQL password", &c.DB.Password),
+		stringSetting("DB_NAME", "PostgreSQL database", &c.DB.Name),
+		stringSetting("DB_SSLMODE", "PostgreSQL sslmode", &c.DB.SSLMode),
+		durationSetting("DB_CONNECT_TIMEOUT", "PostgreSQL connect timeout", &c.DB.ConnectTimeout),
+		intSetting("DB_MAX_OPEN_CONNS", "maximum open connections", &c.DB.MaxOpenConns),
+		intSetting("DB_MAX_IDLE_CONNS", "maximum idle connections", &c.DB.MaxIdleConns),
+		durationSetting("DB_CONN_MAX_LIFETIME", "maximum connection lifetime, 0 is unlimited", &c.DB.ConnMaxLifetime),
+		durationSetting("DB_CONN_MAX_IDLE_TIME", "maximum connection idle time, 0 is unlimited", &c.DB.ConnMaxIdleTime),
+		boolSetting("DB_AUTO_MIGRATE", "apply pending migrations at startup", &c.DB.AutoMigrate),
+		stringSetting("JWT_SECRET", "HMAC secret for HS256 tokens", &c.JWT.Secret),
+		jwtKeysSetting("JWT_KEYS", "comma-separated kid=PEM file of RS256 or EdDSA private keys, each optionally @RFC 3339 time it starts signing", &c.JWT.Keys),
+		timeSetting("JWT_ACCEPT_HS256_UNTIL", "RFC 3339 time until which HS256 tokens issued with JWT_SECRET stay valid next to JWT_KEYS", &c.JWT.AcceptHS256Until),
+		durationSetting("JWT_TTL", "token lifetime", &c.JWT.TTL),
+		stringSetting("JWT_ISSUER", "iss claim of tokens", &c.JWT.Issuer),
+		stringSetting("JWT_AUDIENCE", "aud claim of tokens", &c.JWT.Audience),
+		intSetting("PVZ_LIST_DEFAULT_LIMIT", "default page size of GET /pvz", &c.Limits.PVZListDefault),
+		intSetting("PVZ_LIST_MAX_LIMIT", "maximum page size of GET /pvz", &c.Limits.PVZListMax),
+		intSetting("USER_LIST_DEFAULT_LIMIT", "default page size of GET /users", &c.Limits.UserListDefault),
+		intSetting("USER_LIST_MAX_LIMIT", "maximum page size of GET /users", &c.Limits.UserListMax),
+		stringSetting("OUTBOX_PUBLISHER", "stdout, file or webhook", &c.Outbox.Publisher),
+		stringSetting("OUTBOX_FILE", "file for the file publisher", &c.Outbox.File),
+		stringSetting("OUTBOX_WEBHOOK_URL", "URL for the webhook publisher", &c.Outbox.WebhookURL),
+		durationSetting("IDEMPOTENCY_TTL", "how long Idempotency-Key responses are kept", &c.Idempotency.TTL),
+		durationSetting("IDEMPOTENCY_LOCK_TTL", "how long an unfinished request holds its Idempotency-Key", &c.Idempotency.LockTTL),
+		durationSetting("READINESS_TIMEOUT", "database ping timeout of /readyz", &c.Health.ReadinessTimeout),
+		stringSetting("TRACING_EXPORTER", "none, stdout, file or otlp", &c.Tracing.Exporter),
+		stringSetting("TRACING_FILE", "file for the file exporter", &c.Tracing.File),
+		stringSetting("TRACING_OTLP_ENDPOINT", "OTLP/HTTP collector URL", &c.Tracing.OTLPEndpoint),
+		floatSetting("TRACING_SAMPLE_RATIO", "share of new traces recorded, 0 to 1", &c.Tracing.SampleRatio),
+		stringSetting("LOG_LEVEL", "debug, info, warn or error", &c.Log.Level),
+		stringSetting("LOG_FORMAT", "json or text", &c.Log.Format),
+		rateSetting("RATE_LIMIT_AUTH", "rate of /login, /register and /dummyLogin per IP, e.g. 10/1m or off", &c.RateLimit.Auth),
+		rateSetting("RATE_LIMIT_READ", "rate of reads per user or IP", &c.RateLimit.Read),
+		rateSetting("RATE_LIMIT_WRITE", "rate of writes per user", &c.RateLimit.Write),
+		intSetting("LOCKOUT_MAX_ATTEMPTS", "wrong passwords in a row that lock an account, 0 disables", &c.Lockout.MaxAttempts),
+		durationSetting("LOCKOUT_DURATION", "how long a locked account refuses logins", &c.Lockout.Duration),
+		intSetting("PASSWORD_MIN_LENGTH", "minimum length of new passwords", &c.Password.MinLength),
+		listSetting("PASSWORD_REQUIRED_CLASSES", "comma-separated character classes new passwords need: lower, upper, digit, symbol", &c.Password.RequiredClasses),
+		durationSetting("PASSWORD_RESET_TTL", "lifetime of password reset tokens", &c.Password.ResetTTL),
+		stringSetting("NOTIFIER", "log or file, with DEV_MODE only; empty disables password reset", &c.Notify.Notifier),
+		stringSetting("NOTIFIER_FILE", "file for the file notifier", &c.Notify.File),
+		boolSetting("DEV_MODE", "enable /dummyLogin; never in production", &c.Auth.DevMode),
+		durationSetting("INVITATION_TTL", "lifetime of moderator invitations", &c.Auth.InvitationTTL),
+		durationSetting("API_KEY_ROTATION_GRACE", "how long the old secret of a rotated API key keeps working", &c.Auth.APIKeyRotationGrace),
+	}
+}
+
+func stringSetting(env, usage string, dst *string) setting {
+	return setting{env: env, usage: usage, set: func(value string) error {
+		*dst = value
+		return nil
+	}}
+}
+
+func listSetting(env, usage string, dst *[]string) setting {
+	return setting{env: env, usage: usage, set: func(value string) error {
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*dst = list
+		return nil
+	}}
+}
+
+func rateSetting(env, usage string, dst *Rate) setting {
+	return setting{env: env, usage: usage, set: func(value string) error {
+		parsed, err := ParseRate(value)
+		if err != nil {
+			return err
+		}
+		*dst = parsed
+		return nil
+	}}
+}
+
+func jwtKeysSetting(env, usage string, dst *[]JWTKey) setting {
+	return setting{env: env, usage: usage, set: func(value string) error {
+		parsed, err := ParseJWTKeys(value)
+		if err != nil {
+			return err
+		}
+		*dst = parsed
+		return nil
+	}}
+}
+
+func timeSetting(env, usage string, dst *time.Time) setting {
+	return setting{env: env, usage: usage, set: func(value string) error {
+		parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("not an RFC 3339 time: %q", value)
+		}
+		*dst = parsed
+		return nil
+	}}
+}
+
+func intSetting(env, usage string, dst *int) setting {
+	return setting{env: env, usage: usage, set: func(value string) error {
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("not an integer: %q", value)
+		}
+		*dst = parsed
+		return nil
+	}}
+}
+
+func boolSetting(env, usage string, dst *bool) setting {
+	return setting{env: env, usage: usage, set: func(value string) error {
+		parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("not a boolean: %q", value)
+		}
+		*dst = parsed
+		return nil
+	}}
+}
+
+func floatSetting(env, usage string, dst *float64) setting {
+	return setting{env: env, usage: usage, set: func(value string) error {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("not a number: %q", value)
+		}
+		*dst = parsed
+		return nil
+	}}
+}
+
+func durationSetting(env, usage string, dst *time.Duration) setting {
+	return setting{env: env, usage: usage, set: func(value string) error {
+		parsed, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("not a duration: %q", value)
+		}
+		*dst = parsed
+		return nil
+	}}
+}
+
+func readFiles(list string) (map[string]string, error) {
+	values := make(map[string]string)
+	files := defaultFiles
+	explicit := list != ""
+	if explicit {
+		files = strings.Split(list, ",")
+	}
+
+	for _, file := range files {
+		read, err := godotenv.Read(strings.TrimSpace(file))
+		if err != nil {
+			if !explicit && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read config file %s: %w", file, err)
+		}
+		for key, value := range read {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// flagName turns DB_MAX_OPEN_CONNS into db-max-open-conns.
+func flagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(env), "_", "-")
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.env")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "secret")
+
+		cfg, err := Load(nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 8080, cfg.HTTP.Port)
+		assert.Equal(t, 9090, cfg.GRPC.Port)
+		assert.Equal(t, 10, cfg.Limits.PVZListDefault)
+		assert.Equal(t, 30, cfg.Limits.PVZListMax)
+		assert.Equal(t, 10, cfg.Limits.UserListDefault)
+		assert.Equal(t, 30, cfg.Limits.UserListMax)
+		assert.Equal(t, 24*time.Hour, cfg.JWT.TTL)
+		assert.Equal(t, "pvz-service", cfg.JWT.Issuer)
+		assert.Equal(t, "pvz-api", cfg.JWT.Audience)
+		assert.False(t, cfg.Auth.DevMode, "dev mode must be switched on explicitly")
+	})
+
+	t.Run("file < env < flags", func(t *testing.T) {
+		file := writeEnvFile(t, "JWT_SECRET=from-file\nDB_HOST=file-host\nDB_PORT=6543\nDB_MAX_OPEN_CONNS=5\n")
+		t.Setenv("DB_HOST", "env-host")
+		t.Setenv("DB_MAX_OPEN_CONNS", "7")
+
+		cfg, err := Load([]string{"-config", file, "-db-max-open-conns", "9", "-jwt-ttl", "2h", "-db-auto-migrate", "true"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "from-file", cfg.JWT.Secret)
+		assert.Equal(t, 6543, cfg.DB.Port)
+		assert.Equal(t, "env-host", cfg.DB.Host)
+		assert.Equal(t, 9, cfg.DB.MaxOpenConns)
+		assert.Equal(t, 2*time.Hour, cfg.JWT.TTL)
+		assert.True(t, cfg.DB.AutoMigrate)
+	})
+
+	t.Run("missing explicit file", func(t *testing.T) {
+		_, err := Load([]string{"-config", filepath.Join(t.TempDir(), "missing.env")})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("unparsable values", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "secret")
+		t.Setenv("DB_PORT", "abc")
+
+		_, err := Load([]string{"-jwt-ttl", "soon"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "DB_PORT")
+		assert.Contains(t, err.Error(), "JWT_TTL")
+	})
+
+	t.Run("positional arguments", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "secret")
+
+		_, err := Load([]string{"-db-host", "db", "serve"})
+
+		assert.ErrorContains(t, err, "unexpected arguments: serve")
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		_, err := Load([]string{"-no-such-setting", "1"})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+
+	cfg, rest, err := Parse("pvzctl", []string{"-db-host", "db", "user", "create", "-email", "a@b.c"})
+
+	require.NoError(t, err, "Parse does not validate")
+	assert.Equal(t, "db", cfg.DB.Host)
+	assert.Equal(t, []string{"user", "create", "-email", "a@b.c"}, rest)
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("reports every problem", func(t *testing.T) {
+		cfg := Default()
+		cfg.Limits.PVZListDefault = 50
+		cfg.Limits.UserListMax = 0
+		cfg.DB.MaxIdleConns = -1
+		cfg.Outbox.Publisher = "kafka"
+
+		err := cfg.Validate()
+
+		require.Error(t, err)
+		for _, want := range []string{"JWT_SECRET", "PVZ_LIST_DEFAULT_LIMIT", "USER_LIST_MAX_LIMIT", "DB_MAX_IDLE_CONNS", "OUTBOX_PUBLISHER"} {
+			assert.Contains(t, err.Error(), want)
+		}
+	})
+
+	t.Run("token claims", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.JWT.Audience = ""
+
+		assert.ErrorContains(t, cfg.Validate(), "JWT_AUDIENCE")
+	})
+
+	t.Run("signing keys instead of a secret", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Keys = []JWTKey{{ID: "a", File: "a.pem"}}
+		assert.NoError(t, cfg.Validate())
+
+		cfg.JWT.Keys = append(cfg.JWT.Keys, JWTKey{ID: "a", File: "b.pem"})
+		assert.ErrorContains(t, cfg.Validate(), "used twice")
+	})
+
+	t.Run("HS256 cutoff", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.JWT.AcceptHS256Until = time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+		assert.ErrorContains(t, cfg.Validate(), "JWT_ACCEPT_HS256_UNTIL")
+
+		cfg.JWT.Keys = []JWTKey{{ID: "a", File: "a.pem"}}
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("idempotency lock outlasting the stored responses", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.Idempotency.LockTTL = 2 * cfg.Idempotency.TTL
+
+		assert.ErrorContains(t, cfg.Validate(), "IDEMPOTENCY_LOCK_TTL")
+	})
+
+	t.Run("webhook publisher needs a URL", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.Outbox.Publisher = "webhook"
+
+		assert.ErrorContains(t, cfg.Validate(), "OUTBOX_WEBHOOK_URL")
+	})
+
+	t.Run("tracing", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.Tracing.Exporter = "jaeger"
+		cfg.Tracing.SampleRatio = 1.5
+
+		err := cfg.Validate()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TRACING_EXPORTER")
+		assert.Contains(t, err.Error(), "TRACING_SAMPLE_RATIO")
+	})
+
+	t.Run("trusted proxies", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.HTTP.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+		require.NoError(t, cfg.Validate())
+
+		cfg.HTTP.TrustedProxies = append(cfg.HTTP.TrustedProxies, "proxy.local")
+		assert.ErrorContains(t, cfg.Validate(), "proxy.local")
+	})
+
+	t.Run("logging", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.Log.Level = "verbose"
+		cfg.Log.Format = "xml"
+
+		err := cfg.Validate()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "LOG_LEVEL")
+		assert.Contains(t, err.Error(), "LOG_FORMAT")
+	})
+
+	t.Run("lockout", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.Lockout.Duration = 0
+		assert.ErrorContains(t, cfg.Validate(), "LOCKOUT_DURATION")
+
+		cfg.Lockout.MaxAttempts = 0
+		assert.NoError(t, cfg.Validate(), "a disabled lockout needs no duration")
+
+		cfg.Lockout.MaxAttempts = -1
+		assert.ErrorContains(t, cfg.Validate(), "LOCKOUT_MAX_ATTEMPTS")
+	})
+
+	t.Run("passwords and notifier", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.Password.MinLength = 100
+		cfg.Password.RequiredClasses = []string{"lower", "emoji"}
+		cfg.Notify.Notifier = "smtp"
+
+		err := cfg.Validate()
+
+		require.Error(t, err)
+		for _, want := range []string{"PASSWORD_MIN_LENGTH", `"emoji"`, "NOTIFIER"} {
+			assert.Contains(t, err.Error(), want)
+		}
+	})
+
+	t.Run("development notifiers", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		assert.NoError(t, cfg.Validate(), "password reset is off without a notifier")
+
+		cfg.Notify.Notifier = "log"
+		assert.ErrorContains(t, cfg.Validate(), "DEV_MODE")
+
+		cfg.Auth.DevMode = true
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("invitations", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.Auth.InvitationTTL = 0
+
+		err := cfg.Validate()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "INVITATION_TTL")
+	})
+
+	t.Run("API key rotation grace", func(t *testing.T) {
+		cfg := Default()
+		cfg.JWT.Secret = "secret"
+		cfg.Auth.APIKeyRotationGrace = -time.Minute
+
+		assert.ErrorContains(t, cfg.Validate(), "API_KEY_ROTATION_GRACE")
+	})
+}
+
+func TestParseRate(t *testing.T) {
+	cases := []struct {
+		value string
+		want  Rate
+	}{
+		{"10/1m", Rate{Requests: 10, Period: time.Minute}},
+		{"5/s", Rate{Requests: 5, Period: time.Second}},
+		{" 100/30s ", Rate{Requests: 100, Period: 30 * time.Second}},
+		{"off", Rate{}},
+		{"0", Rate{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := ParseRate(tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	for _, invalid := range []string{"10", "-1/s", "ten/s", "10/0s", "10/fortnight"} {
+		_, err := ParseRate(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseJWTKeys(t *testing.T) {
+	keys, err := ParseJWTKeys(" 2026-10=/keys/a.pem, 2026-11=/keys/b.pem@2026-11-01T00:00:00Z ")
+	require.NoError(t, err)
+	assert.Equal(t, []JWTKey{
+		{ID: "2026-10", File: "/keys/a.pem"},
+		{ID: "2026-11", File: "/keys/b.pem", ActiveFrom: time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)},
+	}, keys)
+
+	for _, invalid := range []string{"/keys/a.pem", "=/keys/a.pem", "a=", "a=/keys/a.pem@tomorrow", "a=@2026-11-01T00:00:00Z"} {
+		_, err := ParseJWTKeys(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestFlagName(t *testing.T) {
+	assert.Equal(t, "db-max-open-conns", flagName("DB_MAX_OPEN_CONNS"))
+	assert.Equal(t, "server-port", flagName("SERVER_PORT"))
+}
+
+func TestDSN(t *testing.T) {
+	cfg := Default().DB
+	cfg.Password = "pw"
+
+	assert.Equal(t, "host='localhost' port=5432 user='postgres' password='pw' dbname='pvz' sslmode='disable' connect_timeout=5", cfg.DSN())
+
+	cfg.Password = `it's a \ secret sslmode=disable`
+	assert.Contains(t, cfg.DSN(), ` password='it\'s a \\ secret sslmode=disable' `)
+	_, err := pq.NewConnector(cfg.DSN())
+	assert.NoError(t, err)
+}
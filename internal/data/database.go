@@ -1,39 +1,42 @@
 package data
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"pvz/internal/config"
+	"pvz/internal/tracing"
 
-	"github.com/joho/godotenv"
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq"
 )
 
 var DB *sql.DB
 
 // Init database connection
-func InitDB() error {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
-	}
-
-	psqlInfo := getDBConnectionString()
-	log.Printf("Connecting to DB with: %s", psqlInfo) // Логирование строки подключения
+func InitDB(cfg config.DBConfig) error {
+	slog.Info("connecting to database", slog.String("host", cfg.Host), slog.Int("port", cfg.Port),
+		slog.String("database", cfg.Name), slog.String("user", cfg.User))
 
-	var err error
-	DB, err = sql.Open("postgres", psqlInfo)
+	connector, err := pq.NewConnector(cfg.DSN())
 	if err != nil {
 		return fmt.Errorf("Database connection error: %v", err)
 	}
+	DB = sql.OpenDB(tracing.WrapConnector(connector))
+	DB.SetMaxOpenConns(cfg.MaxOpenConns)
+	DB.SetMaxIdleConns(cfg.MaxIdleConns)
+	DB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	DB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
 	// Проверка подключения
-	err = DB.Ping()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
+	err = DB.PingContext(ctx)
 	if err != nil {
 		return fmt.Errorf("Failed to connect database: %v", err)
 	}
 
-	log.Println("Successfully connected to database")
+	slog.Info("connected to database")
 	return nil
 }
 
@@ -43,14 +46,3 @@ func CloseDB() error {
 	}
 	return nil
 }
-
-func getDBConnectionString() string {
-	return fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-	)
-}
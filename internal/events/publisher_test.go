@@ -0,0 +1,90 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"pvz/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEvent() models.Event {
+	return models.Event{
+		ID:        uuid.New(),
+		Type:      models.EventReceptionClosed,
+		PVZID:     uuid.New(),
+		Payload:   json.RawMessage(`{"status":"close"}`),
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+func TestWriterPublisher_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+	event := newTestEvent()
+
+	err := publisher.Publish(context.Background(), event)
+
+	assert.NoError(t, err)
+	var got models.Event
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, event.ID, got.ID)
+	assert.Equal(t, event.Type, got.Type)
+	assert.JSONEq(t, string(event.Payload), string(got.Payload))
+}
+
+func TestFilePublisher_Publish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	publisher, err := NewFilePublisher(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, publisher.Publish(context.Background(), newTestEvent()))
+	assert.NoError(t, publisher.Publish(context.Background(), newTestEvent()))
+	assert.NoError(t, publisher.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(data, []byte("\n")))
+}
+
+func TestWebhookPublisher_Publish(t *testing.T) {
+	event := newTestEvent()
+
+	t.Run("successful delivery", func(t *testing.T) {
+		var received models.Event
+		var eventType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			eventType = r.Header.Get("X-Event-Type")
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &received)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		err := NewWebhookPublisher(server.URL, nil).Publish(context.Background(), event)
+
+		assert.NoError(t, err)
+		assert.Equal(t, event.ID, received.ID)
+		assert.Equal(t, models.EventReceptionClosed, eventType)
+	})
+
+	t.Run("non 2xx response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		err := NewWebhookPublisher(server.URL, nil).Publish(context.Background(), event)
+
+		assert.ErrorContains(t, err, "status 502")
+	})
+}
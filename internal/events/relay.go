@@ -0,0 +1,122 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"pvz/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultPublishTimeout = 30 * time.Second
+
+// Relay periodically moves events from the outbox table to a publisher.
+// Delivery is at-least-once: an event is marked as published only after Publish returns nil.
+// Events are claimed for a lease and published outside any transaction; a failing event is retried
+// with exponential backoff and set aside after maxAttempts, so it cannot hold back the events behind it.
+type Relay struct {
+	outboxRepo     repository.OutboxRepositoryInterface
+	publisher      PublisherInterface
+	interval       time.Duration
+	batchSize      int
+	maxAttempts    int
+	baseBackoff    time.Duration
+	publishTimeout time.Duration
+	now            func() time.Time
+}
+
+func NewRelay(outboxRepo repository.OutboxRepositoryInterface, publisher PublisherInterface, interval time.Duration, batchSize, maxAttempts int, baseBackoff time.Duration) *Relay {
+	return &Relay{
+		outboxRepo:     outboxRepo,
+		publisher:      publisher,
+		interval:       interval,
+		batchSize:      batchSize,
+		maxAttempts:    maxAttempts,
+		baseBackoff:    baseBackoff,
+		publishTimeout: defaultPublishTimeout,
+		now:            time.Now,
+	}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// drain the outbox while full batches keep coming
+			for {
+				n, err := r.RelayOnce(ctx)
+				if err != nil {
+					slog.ErrorContext(ctx, "outbox relay failed", slog.Any("error", err))
+					break
+				}
+				if n < r.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// RelayOnce publishes a single batch in order and returns the number of published events. The batch
+// must be published before the lease runs out, otherwise another replica could claim it again.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	// the lease covers the publishing and leaves an interval to record the outcome
+	events, err := r.outboxRepo.ClaimUnpublished(ctx, r.batchSize, r.publishTimeout+r.interval)
+	if err != nil {
+		return 0, err
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, r.publishTimeout)
+	defer cancel()
+
+	published := 0
+	var errs []error
+	for i, event := range events {
+		publishErr := r.publisher.Publish(publishCtx, event)
+		if ctx.Err() != nil {
+			// shutting down: the lease expires and the events are claimed again
+			return published, ctx.Err()
+		}
+		if publishErr == nil {
+			// an event that cannot be marked is published again once its lease expires
+			if err := r.outboxRepo.MarkPublished(ctx, event.ID); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			published++
+			continue
+		}
+
+		var retryAt *time.Time
+		if attempts := event.Attempts + 1; attempts < r.maxAttempts {
+			next := r.now().Add(backoff(r.baseBackoff, attempts))
+			retryAt = &next
+		} else {
+			slog.ErrorContext(ctx, "outbox event failed after max attempts",
+				slog.String("event_id", event.ID.String()), slog.Any("error", publishErr))
+		}
+		if err := r.outboxRepo.MarkPublishFailed(ctx, event.ID, publishErr.Error(), retryAt); err != nil {
+			errs = append(errs, err)
+		}
+
+		// the rest of the batch waits behind the failed event to keep the order
+		rest := make([]uuid.UUID, 0, len(events)-i-1)
+		for _, waiting := range events[i+1:] {
+			rest = append(rest, waiting.ID)
+		}
+		if err := r.outboxRepo.ReleaseEvents(ctx, rest); err != nil {
+			errs = append(errs, err)
+		}
+		break
+	}
+
+	return published, errors.Join(errs...)
+}
@@ -0,0 +1,173 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"pvz/internal/models"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOutboxRepository keeps unpublished events in memory, in order.
+type MockOutboxRepository struct {
+	events      []models.Event
+	failed      []models.Event
+	lockedUntil map[uuid.UUID]time.Time
+}
+
+func newMockOutboxRepository(events ...models.Event) *MockOutboxRepository {
+	return &MockOutboxRepository{events: events, lockedUntil: map[uuid.UUID]time.Time{}}
+}
+
+func (m *MockOutboxRepository) ClaimUnpublished(ctx context.Context, limit int, lease time.Duration) ([]models.Event, error) {
+	for _, event := range m.events {
+		if m.lockedUntil[event.ID].After(time.Now()) {
+			return nil, nil
+		}
+	}
+	claimed := m.events[:min(limit, len(m.events))]
+	for _, event := range claimed {
+		m.lockedUntil[event.ID] = time.Now().Add(lease)
+	}
+	return slices.Clone(claimed), nil
+}
+
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	m.events = slices.DeleteFunc(m.events, func(event models.Event) bool { return event.ID == id })
+	delete(m.lockedUntil, id)
+	return nil
+}
+
+func (m *MockOutboxRepository) MarkPublishFailed(ctx context.Context, id uuid.UUID, lastError string, retryAt *time.Time) error {
+	i := slices.IndexFunc(m.events, func(event models.Event) bool { return event.ID == id })
+	m.events[i].Attempts++
+	if retryAt == nil {
+		m.failed = append(m.failed, m.events[i])
+		m.events = slices.Delete(m.events, i, i+1)
+		delete(m.lockedUntil, id)
+		return nil
+	}
+	m.lockedUntil[id] = *retryAt
+	return nil
+}
+
+func (m *MockOutboxRepository) ReleaseEvents(ctx context.Context, ids []uuid.UUID) error {
+	for _, id := range ids {
+		delete(m.lockedUntil, id)
+	}
+	return nil
+}
+
+func (m *MockOutboxRepository) GetEventsAfter(ctx context.Context, pvzID uuid.UUID, afterSeq int64, eventTypes []string, limit int) ([]models.Event, error) {
+	return nil, nil
+}
+
+func (m *MockOutboxRepository) GetLastEventSeq(ctx context.Context, pvzID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+type MockPublisher struct {
+	mock.Mock
+}
+
+func (m *MockPublisher) Publish(ctx context.Context, event models.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func TestRelay_RelayOnce(t *testing.T) {
+	first, second := newTestEvent(), newTestEvent()
+
+	t.Run("publishes events in order", func(t *testing.T) {
+		repo := newMockOutboxRepository(first, second)
+		publisher := new(MockPublisher)
+		publisher.On("Publish", mock.Anything, first).Return(nil).Once()
+		publisher.On("Publish", mock.Anything, second).Return(nil).Once()
+
+		n, err := NewRelay(repo, publisher, time.Second, 10, 3, time.Second).RelayOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Empty(t, repo.events)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("failed event is retried with backoff and holds back the rest", func(t *testing.T) {
+		repo := newMockOutboxRepository(first, second)
+		publisher := new(MockPublisher)
+		publisher.On("Publish", mock.Anything, first).Return(errors.New("broker is down")).Once()
+		relay := NewRelay(repo, publisher, time.Second, 10, 3, time.Minute)
+		now := time.Now()
+		relay.now = func() time.Time { return now }
+
+		n, err := relay.RelayOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.Len(t, repo.events, 2)
+		assert.Equal(t, 1, repo.events[0].Attempts)
+		assert.Equal(t, now.Add(time.Minute), repo.lockedUntil[first.ID])
+		assert.NotContains(t, repo.lockedUntil, second.ID)
+
+		// nothing is claimed while the failed event waits for its retry
+		n, err = relay.RelayOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("event is set aside after max attempts", func(t *testing.T) {
+		poison := newTestEvent()
+		poison.Attempts = 2
+		repo := newMockOutboxRepository(poison, second)
+		publisher := new(MockPublisher)
+		publisher.On("Publish", mock.Anything, poison).Return(errors.New("payload rejected")).Once()
+		publisher.On("Publish", mock.Anything, second).Return(nil).Once()
+		relay := NewRelay(repo, publisher, time.Second, 10, 3, time.Minute)
+
+		_, err := relay.RelayOnce(context.Background())
+		assert.NoError(t, err)
+		n, err := relay.RelayOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Empty(t, repo.events)
+		assert.Len(t, repo.failed, 1)
+		publisher.AssertExpectations(t)
+	})
+}
+
+type countingPublisher struct {
+	published atomic.Int32
+}
+
+func (p *countingPublisher) Publish(ctx context.Context, event models.Event) error {
+	p.published.Add(1)
+	return nil
+}
+
+func TestRelay_Run(t *testing.T) {
+	repo := newMockOutboxRepository(newTestEvent(), newTestEvent(), newTestEvent())
+	publisher := &countingPublisher{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewRelay(repo, publisher, 10*time.Millisecond, 2, 3, time.Second).Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return publisher.published.Load() == 3
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,23 @@
+package events
+
+import (
+	"context"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+)
+
+// SubscriptionPublisher fans an event out into pending deliveries for every matching webhook subscription.
+// It runs behind a Relay of its own, so it neither waits for nor repeats the configured publisher.
+// Enqueueing is idempotent, so an event published twice gets its deliveries once.
+type SubscriptionPublisher struct {
+	webhookRepo repository.WebhookRepositoryInterface
+}
+
+func NewSubscriptionPublisher(webhookRepo repository.WebhookRepositoryInterface) *SubscriptionPublisher {
+	return &SubscriptionPublisher{webhookRepo: webhookRepo}
+}
+
+func (p *SubscriptionPublisher) Publish(ctx context.Context, event models.Event) error {
+	_, err := p.webhookRepo.EnqueueDeliveries(ctx, event)
+	return err
+}
@@ -0,0 +1,197 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const maxBackoff = time.Hour
+
+// ErrNonPublicAddress is returned for webhooks that resolve to loopback, private, link-local and other
+// addresses that are not reachable from the internet.
+var ErrNonPublicAddress = errors.New("webhook address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range, which netip does not count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// newPublicClient returns a client that dials public addresses only. The check runs on the address
+// being connected to, after DNS resolution and on every redirect, so a subscriber cannot point its host
+// at the service's own network. There is no proxy, as the proxy address is what would be checked.
+func newPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: dialPublicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func dialPublicOnly(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := addrPort.Addr().Unmap(); !isPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, ip)
+	}
+	return nil
+}
+
+func isPublic(ip netip.Addr) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// WebhookDispatcher sends pending webhook deliveries, signing each body with the subscription secret.
+// Failed attempts are retried with exponential backoff until maxAttempts is reached.
+type WebhookDispatcher struct {
+	webhookRepo repository.WebhookRepositoryInterface
+	client      *http.Client
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	baseBackoff time.Duration
+	now         func() time.Time
+}
+
+func NewWebhookDispatcher(webhookRepo repository.WebhookRepositoryInterface, client *http.Client, interval time.Duration, batchSize, maxAttempts int, baseBackoff time.Duration) *WebhookDispatcher {
+	if client == nil {
+		client = newPublicClient(10 * time.Second)
+	}
+	return &WebhookDispatcher{
+		webhookRepo: webhookRepo,
+		client:      client,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		baseBackoff: baseBackoff,
+		now:         time.Now,
+	}
+}
+
+// Run dispatches deliveries until ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DispatchOnce(ctx); err != nil {
+				slog.ErrorContext(ctx, "webhook dispatch failed", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// DispatchOnce sends one batch of due deliveries and returns how many of them succeeded. The batch is
+// sent concurrently and every send must finish before the lease runs out, otherwise another replica
+// could claim the same delivery and send it again.
+func (d *WebhookDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	sendTimeout := d.client.Timeout
+	if sendTimeout == 0 {
+		sendTimeout = time.Minute
+	}
+	// the lease covers the sends and leaves an interval to record their outcome
+	lease := sendTimeout + d.interval
+
+	deliveries, err := d.webhookRepo.ClaimDueDeliveries(ctx, d.batchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	type result struct {
+		statusCode int
+		err        error
+	}
+	results := make([]result, len(deliveries))
+	var wg sync.WaitGroup
+	for i, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].statusCode, results[i].err = d.send(sendCtx, delivery)
+		}()
+	}
+	wg.Wait()
+
+	// a delivery that cannot be marked is sent again once its lease expires; the rest are still marked
+	delivered := 0
+	var errs []error
+	for i, delivery := range deliveries {
+		statusCode, sendErr := results[i].statusCode, results[i].err
+		if sendErr == nil {
+			if err := d.webhookRepo.MarkDeliveryDelivered(ctx, delivery.ID, statusCode); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			delivered++
+			continue
+		}
+
+		var nextAttemptAt *time.Time
+		if attempts := delivery.Attempts + 1; attempts < d.maxAttempts {
+			next := d.now().Add(d.backoff(attempts))
+			nextAttemptAt = &next
+		}
+		if err := d.webhookRepo.MarkDeliveryFailed(ctx, delivery.ID, statusCode, sendErr.Error(), nextAttemptAt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-ID", delivery.ID.String())
+	req.Header.Set("X-Event-ID", delivery.EventID.String())
+	req.Header.Set("X-Event-Type", delivery.EventType)
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, d.now().Unix(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	return backoff(d.baseBackoff, attempts)
+}
+
+// backoff returns base * 2^(attempts-1), capped at maxBackoff.
+func backoff(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,304 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"pvz/internal/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWebhookRepository keeps deliveries in memory and implements only what the dispatcher needs.
+type fakeWebhookRepository struct {
+	mu         sync.Mutex
+	deliveries map[uuid.UUID]*models.WebhookDelivery
+	enqueued   []models.Event
+	// markErrors fails marking these deliveries
+	markErrors map[uuid.UUID]error
+}
+
+func newFakeWebhookRepository(deliveries ...models.WebhookDelivery) *fakeWebhookRepository {
+	repo := &fakeWebhookRepository{deliveries: map[uuid.UUID]*models.WebhookDelivery{}}
+	for i := range deliveries {
+		repo.deliveries[deliveries[i].ID] = &deliveries[i]
+	}
+	return repo
+}
+
+func (r *fakeWebhookRepository) InsertSubscription(ctx context.Context, url, eventType, secret string, createdBy uuid.UUID) (*models.WebhookSubscription, error) {
+	return nil, nil
+}
+
+func (r *fakeWebhookRepository) GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return nil, nil
+}
+
+func (r *fakeWebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (r *fakeWebhookRepository) EnqueueDeliveries(ctx context.Context, event models.Event) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enqueued = append(r.enqueued, event)
+	return 1, nil
+}
+
+func (r *fakeWebhookRepository) GetDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (r *fakeWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []models.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == models.DeliveryStatusPending && !delivery.NextAttemptAt.After(time.Now()) {
+			due = append(due, *delivery)
+			delivery.NextAttemptAt = time.Now().Add(lease)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeWebhookRepository) MarkDeliveryDelivered(ctx context.Context, id uuid.UUID, responseCode int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.markErrors[id]; err != nil {
+		return err
+	}
+	delivery := r.deliveries[id]
+	delivery.Status = models.DeliveryStatusDelivered
+	delivery.Attempts++
+	delivery.ResponseCode = &responseCode
+	return nil
+}
+
+func (r *fakeWebhookRepository) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, responseCode int, lastError string, nextAttemptAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery := r.deliveries[id]
+	delivery.Attempts++
+	delivery.ResponseCode = &responseCode
+	delivery.LastError = &lastError
+	if nextAttemptAt == nil {
+		delivery.Status = models.DeliveryStatusFailed
+	} else {
+		delivery.NextAttemptAt = *nextAttemptAt
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepository) ResetDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func newTestDelivery(url, secret string) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:            uuid.New(),
+		EventID:       uuid.New(),
+		EventType:     models.EventReceptionClosed,
+		Payload:       []byte(`{"type":"reception.closed"}`),
+		Status:        models.DeliveryStatusPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+		URL:           url,
+		Secret:        secret,
+	}
+}
+
+// loopbackClient reaches the httptest receivers, which the default client refuses.
+var loopbackClient = &http.Client{Timeout: 2 * time.Second}
+
+func TestWebhookDispatcher_DispatchOnce(t *testing.T) {
+	const secret = "top-secret"
+
+	t.Run("signed delivery", func(t *testing.T) {
+		var signatureValid bool
+		var eventType string
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			_, signatureValid = VerifySignature(secret, r.Header.Get(SignatureHeader), body)
+			eventType = r.Header.Get("X-Event-Type")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer receiver.Close()
+
+		delivery := newTestDelivery(receiver.URL, secret)
+		repo := newFakeWebhookRepository(delivery)
+		dispatcher := NewWebhookDispatcher(repo, loopbackClient, time.Second, 10, 3, time.Second)
+
+		n, err := dispatcher.DispatchOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.True(t, signatureValid)
+		assert.Equal(t, models.EventReceptionClosed, eventType)
+		assert.Equal(t, models.DeliveryStatusDelivered, repo.deliveries[delivery.ID].Status)
+	})
+
+	t.Run("failed delivery is retried with backoff", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer receiver.Close()
+
+		delivery := newTestDelivery(receiver.URL, secret)
+		repo := newFakeWebhookRepository(delivery)
+		dispatcher := NewWebhookDispatcher(repo, loopbackClient, time.Second, 10, 3, time.Minute)
+		now := time.Now()
+		dispatcher.now = func() time.Time { return now }
+
+		n, err := dispatcher.DispatchOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		stored := repo.deliveries[delivery.ID]
+		assert.Equal(t, models.DeliveryStatusPending, stored.Status)
+		assert.Equal(t, 1, stored.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, *stored.ResponseCode)
+		assert.Equal(t, now.Add(time.Minute), stored.NextAttemptAt)
+	})
+
+	t.Run("delivery fails after max attempts", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer receiver.Close()
+
+		delivery := newTestDelivery(receiver.URL, secret)
+		delivery.Attempts = 2
+		repo := newFakeWebhookRepository(delivery)
+		dispatcher := NewWebhookDispatcher(repo, loopbackClient, time.Second, 10, 3, time.Minute)
+
+		_, err := dispatcher.DispatchOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DeliveryStatusFailed, repo.deliveries[delivery.ID].Status)
+	})
+
+	t.Run("batch is sent concurrently", func(t *testing.T) {
+		// every request waits for all of them, so sending one after another would time out
+		const batch = 5
+		var arrived sync.WaitGroup
+		arrived.Add(batch)
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			arrived.Done()
+			arrived.Wait()
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer receiver.Close()
+
+		var deliveries []models.WebhookDelivery
+		for range batch {
+			deliveries = append(deliveries, newTestDelivery(receiver.URL, secret))
+		}
+		repo := newFakeWebhookRepository(deliveries...)
+		dispatcher := NewWebhookDispatcher(repo, &http.Client{Timeout: 2 * time.Second}, time.Second, batch, 3, time.Second)
+
+		n, err := dispatcher.DispatchOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, batch, n)
+	})
+
+	t.Run("failing to mark one delivery does not stop the batch", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer receiver.Close()
+
+		broken, ok := newTestDelivery(receiver.URL, secret), newTestDelivery(receiver.URL, secret)
+		repo := newFakeWebhookRepository(broken, ok)
+		repo.markErrors = map[uuid.UUID]error{broken.ID: errors.New("db down")}
+		dispatcher := NewWebhookDispatcher(repo, loopbackClient, time.Second, 10, 3, time.Second)
+
+		n, err := dispatcher.DispatchOnce(context.Background())
+
+		assert.ErrorContains(t, err, "db down")
+		assert.Equal(t, 1, n)
+		assert.Equal(t, models.DeliveryStatusDelivered, repo.deliveries[ok.ID].Status)
+	})
+}
+
+func TestWebhookDispatcher_RefusesNonPublicAddresses(t *testing.T) {
+	var called bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	delivery := newTestDelivery(receiver.URL, "secret")
+	repo := newFakeWebhookRepository(delivery)
+	dispatcher := NewWebhookDispatcher(repo, nil, time.Second, 10, 1, time.Second)
+
+	n, err := dispatcher.DispatchOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.False(t, called)
+	stored := repo.deliveries[delivery.ID]
+	assert.Equal(t, models.DeliveryStatusFailed, stored.Status)
+	assert.Contains(t, *stored.LastError, ErrNonPublicAddress.Error())
+}
+
+func TestIsPublic(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::248": true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"224.0.0.1":            false,
+	} {
+		assert.Equal(t, public, isPublic(netip.MustParseAddr(address)), address)
+	}
+}
+
+func TestWebhookDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(newFakeWebhookRepository(), nil, time.Second, 10, 20, time.Second)
+
+	assert.Equal(t, time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 2*time.Second, dispatcher.backoff(2))
+	assert.Equal(t, 8*time.Second, dispatcher.backoff(4))
+	assert.Equal(t, maxBackoff, dispatcher.backoff(20))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	header := Sign("secret", 1700000000, body)
+
+	timestamp, ok := VerifySignature("secret", header, body)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1700000000), timestamp)
+
+	_, ok = VerifySignature("secret", header, []byte(`{"id":"2"}`))
+	assert.False(t, ok)
+
+	_, ok = VerifySignature("other-secret", header, body)
+	assert.False(t, ok)
+}
+
+func TestSubscriptionPublisher_Publish(t *testing.T) {
+	repo := newFakeWebhookRepository()
+	event := newTestEvent()
+
+	err := NewSubscriptionPublisher(repo).Publish(context.Background(), event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Event{event}, repo.enqueued)
+}
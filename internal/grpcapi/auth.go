@@ -0,0 +1,86 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"pvz/internal/logging"
+	"pvz/internal/services"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type contextKey string
+
+const (
+	userIDKey contextKey = "user_id"
+	roleKey   contextKey = "role"
+)
+
+// publicMethods do not require a token, like /register, /login and /dummyLogin over HTTP.
+var publicMethods = map[string]bool{
+	"/pvz.v1.UserService/Register":   true,
+	"/pvz.v1.UserService/Login":      true,
+	"/pvz.v1.UserService/DummyLogin": true,
+}
+
+// optionalTokenMethods are public methods that still read a token when one is sent: a moderator's
+// token lets Register create another moderator.
+var optionalTokenMethods = map[string]bool{
+	"/pvz.v1.UserService/Register": true,
+}
+
+// JWTInterceptor is the gRPC counterpart of middleware.JWTMiddleware. It reads "authorization: Bearer <token>"
+// from the incoming metadata, refuses the tokens the checker no longer honours and stores user id and role in the context.
+func JWTInterceptor(tokens *services.TokenManager, checker services.TokenCheckerInterface) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+
+		if publicMethods[info.FullMethod] && (len(values) == 0 || !optionalTokenMethods[info.FullMethod]) {
+			return handler(ctx, req)
+		}
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+		}
+
+		parts := strings.Split(values[0], " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+		}
+
+		claims, err := tokens.Parse(parts[1])
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token: "+err.Error())
+		}
+		if err := checker.CheckToken(ctx, claims); err != nil {
+			if errors.Is(err, services.ErrTokenRevoked) {
+				return nil, status.Error(codes.Unauthenticated, "invalid token: "+err.Error())
+			}
+			slog.ErrorContext(ctx, "failed to check token", slog.Any("error", err))
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+
+		ctx = context.WithValue(ctx, userIDKey, claims.UserID)
+		ctx = context.WithValue(ctx, roleKey, claims.Role)
+		ctx = logging.With(ctx, slog.String("user_id", claims.UserID.String()), slog.String("role", claims.Role))
+		return handler(ctx, req)
+	}
+}
+
+func userFromContext(ctx context.Context) (uuid.UUID, string, error) {
+	userID, ok := ctx.Value(userIDKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, "", status.Error(codes.Internal, "user id not found in context")
+	}
+	role, ok := ctx.Value(roleKey).(string)
+	if !ok {
+		return uuid.Nil, "", status.Error(codes.Internal, "user role not found in context")
+	}
+	return userID, role, nil
+}
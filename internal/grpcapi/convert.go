@@ -0,0 +1,85 @@
+package grpcapi
+
+import (
+	"pvz/internal/models"
+	pvzv1 "pvz/internal/pb/pvz/v1"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func parseUUID(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid %s: %v", field, err)
+	}
+	return id, nil
+}
+
+func parseOptionalUUID(field, value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := parseUUID(field, value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func toPBPVZ(pvz models.PVZ) *pvzv1.PVZ {
+	return &pvzv1.PVZ{
+		Id:               pvz.ID.String(),
+		RegistrationDate: timestamppb.New(pvz.RegistrationDate),
+		City:             pvz.City,
+	}
+}
+
+func toPBReception(reception models.Reception) *pvzv1.Reception {
+	pb := &pvzv1.Reception{
+		Id:        reception.ID.String(),
+		DateTime:  timestamppb.New(reception.DateTime),
+		PvzId:     reception.PVZID.String(),
+		Status:    reception.Status,
+		CreatedBy: reception.CreatedBy.String(),
+	}
+	if reception.ClosedBy != nil {
+		pb.ClosedBy = reception.ClosedBy.String()
+	}
+	return pb
+}
+
+func toPBProduct(product models.Product) *pvzv1.Product {
+	return &pvzv1.Product{
+		Id:          product.ID.String(),
+		DateTime:    timestamppb.New(product.DateTime),
+		Type:        product.ProductType,
+		ReceptionId: product.ReceptionID.String(),
+		CreatedBy:   product.CreatedBy.String(),
+	}
+}
+
+func toPBPVZWithReceptions(pvz models.PVZWithReceptions) *pvzv1.PVZWithReceptions {
+	pb := &pvzv1.PVZWithReceptions{
+		Pvz: toPBPVZ(models.PVZ{ID: pvz.ID, RegistrationDate: pvz.RegistrationDate, City: pvz.City}),
+	}
+	for _, reception := range pvz.Receptions {
+		pbReception := &pvzv1.ReceptionWithProducts{Reception: toPBReception(reception.Reception)}
+		for _, product := range reception.Products {
+			pbReception.Products = append(pbReception.Products, toPBProduct(product))
+		}
+		pb.Receptions = append(pb.Receptions, pbReception)
+	}
+	return pb
+}
@@ -0,0 +1,38 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"pvz/internal/ratelimit"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimitInterceptor is the gRPC counterpart of middleware.RateLimit on the public routes: it lets each
+// peer IP make limit.Requests calls of the public methods per limit.Period. Calls over the limit get
+// RESOURCE_EXHAUSTED and a retry-after header in whole seconds. A zero limit disables it, and when the
+// store fails the call is let through.
+func RateLimitInterceptor(store ratelimit.Store, group string, limit ratelimit.Limit) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if limit.Requests == 0 || !publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		decision, err := store.Take(ctx, group+":ip:"+clientInfo(ctx).IP, limit)
+		if err != nil {
+			slog.ErrorContext(ctx, "rate limit store failed", slog.String("group", group), slog.Any("error", err))
+			return handler(ctx, req)
+		}
+		if !decision.Allowed {
+			retryAfter := strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds())))
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded, retry after "+retryAfter+"s")
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,235 @@
+// Package grpcapi exposes the service layer over gRPC. Handlers are thin adapters: they convert
+// protobuf messages, call the same services as the HTTP handlers and map errors to status codes.
+package grpcapi
+
+import (
+	"context"
+	"net"
+	pvzv1 "pvz/internal/pb/pvz/v1"
+	"pvz/internal/ratelimit"
+	"pvz/internal/services"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const defaultPage = 1
+
+type Server struct {
+	pvzv1.UnimplementedPVZServiceServer
+	pvzv1.UnimplementedReceptionServiceServer
+	pvzv1.UnimplementedProductServiceServer
+	pvzv1.UnimplementedUserServiceServer
+
+	pvzService       services.PVZServiceInterface
+	receptionService services.ReceptionServiceInterface
+	productService   services.ProductServiceInterface
+	userService      services.UserServiceInterface
+	defaultLimit     int
+}
+
+func NewServer(
+	pvzService services.PVZServiceInterface,
+	receptionService services.ReceptionServiceInterface,
+	productService services.ProductServiceInterface,
+	userService services.UserServiceInterface,
+	tokens *services.TokenManager,
+	checker services.TokenCheckerInterface,
+	limits ratelimit.Store,
+	authLimit ratelimit.Limit,
+	defaultLimit int,
+) *grpc.Server {
+	srv := &Server{
+		pvzService:       pvzService,
+		receptionService: receptionService,
+		productService:   productService,
+		userService:      userService,
+		defaultLimit:     defaultLimit,
+	}
+
+	grpcServer := grpc.NewServer(
+		// the stats handler continues traces from the traceparent metadata of incoming calls
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			// the group of the HTTP auth routes: a client has one budget for logins over both APIs
+			RateLimitInterceptor(limits, "auth", authLimit),
+			JWTInterceptor(tokens, checker),
+		),
+	)
+	pvzv1.RegisterPVZServiceServer(grpcServer, srv)
+	pvzv1.RegisterReceptionServiceServer(grpcServer, srv)
+	pvzv1.RegisterProductServiceServer(grpcServer, srv)
+	pvzv1.RegisterUserServiceServer(grpcServer, srv)
+	return grpcServer
+}
+
+func (s *Server) CreatePVZ(ctx context.Context, req *pvzv1.CreatePVZRequest) (*pvzv1.PVZ, error) {
+	_, role, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pvz, err := s.pvzService.CreatePVZ(ctx, req.GetCity(), role)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPBPVZ(pvz), nil
+}
+
+func (s *Server) GetPVZList(ctx context.Context, req *pvzv1.GetPVZListRequest) (*pvzv1.GetPVZListResponse, error) {
+	_, role, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	createdBy, err := parseOptionalUUID("created_by", req.GetCreatedBy())
+	if err != nil {
+		return nil, err
+	}
+	closedBy, err := parseOptionalUUID("closed_by", req.GetClosedBy())
+	if err != nil {
+		return nil, err
+	}
+
+	page := int(req.GetPage())
+	if page == 0 {
+		page = defaultPage
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = s.defaultLimit
+	}
+
+	pvzs, err := s.pvzService.GetPVZList(ctx, optionalTime(req.GetStartDate()), optionalTime(req.GetEndDate()),
+		createdBy, closedBy, page, limit, role)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pvzv1.GetPVZListResponse{}
+	for _, pvz := range pvzs {
+		resp.Pvzs = append(resp.Pvzs, toPBPVZWithReceptions(pvz))
+	}
+	return resp, nil
+}
+
+func (s *Server) CreateReception(ctx context.Context, req *pvzv1.CreateReceptionRequest) (*pvzv1.Reception, error) {
+	userID, role, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pvzID, err := parseUUID("pvz_id", req.GetPvzId())
+	if err != nil {
+		return nil, err
+	}
+
+	reception, err := s.receptionService.CreateReception(ctx, pvzID, userID, role)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPBReception(reception), nil
+}
+
+func (s *Server) CloseLastReception(ctx context.Context, req *pvzv1.CloseLastReceptionRequest) (*pvzv1.Reception, error) {
+	userID, role, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pvzID, err := parseUUID("pvz_id", req.GetPvzId())
+	if err != nil {
+		return nil, err
+	}
+
+	reception, err := s.receptionService.CloseReception(ctx, pvzID, userID, role)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPBReception(reception), nil
+}
+
+func (s *Server) AddProduct(ctx context.Context, req *pvzv1.AddProductRequest) (*pvzv1.Product, error) {
+	userID, role, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pvzID, err := parseUUID("pvz_id", req.GetPvzId())
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.productService.AddProduct(ctx, req.GetType(), pvzID, userID, role)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPBProduct(product), nil
+}
+
+func (s *Server) DeleteLastProduct(ctx context.Context, req *pvzv1.DeleteLastProductRequest) (*pvzv1.DeleteLastProductResponse, error) {
+	_, role, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pvzID, err := parseUUID("pvz_id", req.GetPvzId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.productService.DeleteProduct(ctx, pvzID, role); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pvzv1.DeleteLastProductResponse{}, nil
+}
+
+func (s *Server) Register(ctx context.Context, req *pvzv1.RegisterRequest) (*pvzv1.User, error) {
+	if req.GetEmail() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "email and password are required")
+	}
+	if req.GetRole() != "employee" && req.GetRole() != "moderator" {
+		return nil, toStatus(services.ErrInvalidRole)
+	}
+
+	// the token is optional for Register; without one the caller has no role
+	callerRole, _ := ctx.Value(roleKey).(string)
+	auth := services.RegistrationAuth{CallerRole: callerRole, InvitationCode: req.GetInvitationCode()}
+	user, err := s.userService.RegisterUser(ctx, req.GetEmail(), req.GetPassword(), req.GetRole(), auth)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pvzv1.User{Id: user.ID.String(), Email: user.Email, Role: user.Role}, nil
+}
+
+func (s *Server) Login(ctx context.Context, req *pvzv1.LoginRequest) (*pvzv1.TokenResponse, error) {
+	token, err := s.userService.LoginUser(ctx, req.GetEmail(), req.GetPassword(), clientInfo(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pvzv1.TokenResponse{Token: token}, nil
+}
+
+// clientInfo describes the caller for the login history: the peer IP and the user-agent metadata.
+func clientInfo(ctx context.Context) services.ClientInfo {
+	var client services.ClientInfo
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client.IP); err == nil {
+			client.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		client.UserAgent = strings.Join(md.Get("user-agent"), " ")
+	}
+	return client
+}
+
+func (s *Server) DummyLogin(ctx context.Context, req *pvzv1.DummyLoginRequest) (*pvzv1.TokenResponse, error) {
+	token, err := s.userService.DummyLogin(req.GetRole())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pvzv1.TokenResponse{Token: token}, nil
+}
@@ -0,0 +1,335 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"pvz/internal/models"
+	pvzv1 "pvz/internal/pb/pvz/v1"
+	"pvz/internal/ratelimit"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type MockReceptionService struct {
+	mock.Mock
+}
+
+func (m *MockReceptionService) CreateReception(ctx context.Context, pvzID, userID uuid.UUID, role string) (models.Reception, error) {
+	args := m.Called(ctx, pvzID, userID, role)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
+func (m *MockReceptionService) CloseReception(ctx context.Context, pvzID, userID uuid.UUID, role string) (models.Reception, error) {
+	args := m.Called(ctx, pvzID, userID, role)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
+func (m *MockReceptionService) ForceCloseReception(ctx context.Context, pvzID, closedBy uuid.UUID) (models.Reception, error) {
+	args := m.Called(ctx, pvzID, closedBy)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) RegisterUser(ctx context.Context, email, password, role string, auth services.RegistrationAuth) (models.User, error) {
+	args := m.Called(ctx, email, password, role, auth)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockUserService) LoginUser(ctx context.Context, email, password string, client services.ClientInfo) (string, error) {
+	args := m.Called(ctx, email, password, client)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserService) UnlockUser(ctx context.Context, id uuid.UUID, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetLoginHistory(ctx context.Context, id uuid.UUID, role string) ([]models.LoginAttempt, error) {
+	args := m.Called(ctx, id, role)
+	return args.Get(0).([]models.LoginAttempt), args.Error(1)
+}
+
+func (m *MockUserService) DummyLogin(role string) (string, error) {
+	args := m.Called(role)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserService) ResetPassword(ctx context.Context, email, password string) error {
+	args := m.Called(ctx, email, password)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ChangePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error {
+	args := m.Called(ctx, id, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetUsers(ctx context.Context, filter repository.UserFilter, page, limit int, role string) ([]models.User, error) {
+	args := m.Called(ctx, filter, page, limit, role)
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockUserService) GetUser(ctx context.Context, id uuid.UUID, role string) (models.User, error) {
+	args := m.Called(ctx, id, role)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockUserService) ChangeRole(ctx context.Context, id uuid.UUID, newRole string, userID uuid.UUID, role string) error {
+	args := m.Called(ctx, id, newRole, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockUserService) SetDeactivated(ctx context.Context, id uuid.UUID, deactivated bool, userID uuid.UUID, role string) error {
+	args := m.Called(ctx, id, deactivated, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockUserService) DeleteUser(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) error {
+	args := m.Called(ctx, id, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetProfile(ctx context.Context, userID uuid.UUID, role string) (models.Profile, error) {
+	args := m.Called(ctx, userID, role)
+	return args.Get(0).(models.Profile), args.Error(1)
+}
+
+var testTokens = services.NewTokenManager("grpcsecret", time.Hour, "pvz-service", "pvz-api")
+
+// revokedUserID is the user whose tokens the test server refuses.
+var revokedUserID = uuid.New()
+
+var testChecker = services.TokenCheckerFunc(func(_ context.Context, claims *services.CustomClaims) error {
+	if claims.UserID == revokedUserID {
+		return services.ErrTokenRevoked
+	}
+	return nil
+})
+
+func dialServer(t *testing.T, receptionService services.ReceptionServiceInterface, userService services.UserServiceInterface) *grpc.ClientConn {
+	t.Helper()
+	return dialLimitedServer(t, receptionService, userService, ratelimit.Limit{})
+}
+
+// dialLimitedServer is dialServer with the public methods limited to authLimit.
+func dialLimitedServer(t *testing.T, receptionService services.ReceptionServiceInterface, userService services.UserServiceInterface,
+	authLimit ratelimit.Limit) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(nil, receptionService, nil, userService, testTokens, testChecker, ratelimit.NewMemoryStore(), authLimit, 10)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func withToken(t *testing.T, role string) context.Context {
+	t.Helper()
+	token, err := testTokens.Generate(&models.User{ID: uuid.New(), Role: role})
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestServer_CreateReception(t *testing.T) {
+	t.Run("missing token", func(t *testing.T) {
+		client := pvzv1.NewReceptionServiceClient(dialServer(t, new(MockReceptionService), nil))
+
+		_, err := client.CreateReception(context.Background(), &pvzv1.CreateReceptionRequest{PvzId: uuid.NewString()})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		client := pvzv1.NewReceptionServiceClient(dialServer(t, new(MockReceptionService), nil))
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer invalid")
+
+		_, err := client.CreateReception(ctx, &pvzv1.CreateReceptionRequest{PvzId: uuid.NewString()})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		client := pvzv1.NewReceptionServiceClient(dialServer(t, new(MockReceptionService), nil))
+		token, err := testTokens.Generate(&models.User{ID: revokedUserID, Role: "employee"})
+		require.NoError(t, err)
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+
+		_, err = client.CreateReception(ctx, &pvzv1.CreateReceptionRequest{PvzId: uuid.NewString()})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("invalid pvz id", func(t *testing.T) {
+		client := pvzv1.NewReceptionServiceClient(dialServer(t, new(MockReceptionService), nil))
+
+		_, err := client.CreateReception(withToken(t, "employee"), &pvzv1.CreateReceptionRequest{PvzId: "not-a-uuid"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("successful reception create", func(t *testing.T) {
+		mockService := new(MockReceptionService)
+		client := pvzv1.NewReceptionServiceClient(dialServer(t, mockService, nil))
+
+		pvzID := uuid.New()
+		expected := models.Reception{
+			ID:        uuid.New(),
+			DateTime:  time.Now().UTC(),
+			PVZID:     pvzID,
+			Status:    "in_progress",
+			CreatedBy: uuid.New(),
+		}
+		mockService.On("CreateReception", mock.Anything, pvzID, mock.AnythingOfType("uuid.UUID"), "employee").
+			Return(expected, nil)
+
+		resp, err := client.CreateReception(withToken(t, "employee"), &pvzv1.CreateReceptionRequest{PvzId: pvzID.String()})
+		require.NoError(t, err)
+		assert.Equal(t, expected.ID.String(), resp.GetId())
+		assert.Equal(t, pvzID.String(), resp.GetPvzId())
+		assert.Equal(t, "in_progress", resp.GetStatus())
+		assert.True(t, expected.DateTime.Equal(resp.GetDateTime().AsTime()))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("service errors are mapped to status codes", func(t *testing.T) {
+		cases := []struct {
+			err  error
+			code codes.Code
+		}{
+			{services.ErrAccessDenied, codes.PermissionDenied},
+			{repository.ErrActiveReceptionExists, codes.FailedPrecondition},
+			{repository.ErrPVZNotFound, codes.NotFound},
+			{repository.ErrReceptionConflict, codes.Aborted},
+			{assert.AnError, codes.Internal},
+		}
+
+		for _, tc := range cases {
+			mockService := new(MockReceptionService)
+			client := pvzv1.NewReceptionServiceClient(dialServer(t, mockService, nil))
+			mockService.On("CreateReception", mock.Anything, mock.Anything, mock.Anything, "moderator").
+				Return(models.Reception{}, tc.err)
+
+			_, err := client.CreateReception(withToken(t, "moderator"), &pvzv1.CreateReceptionRequest{PvzId: uuid.NewString()})
+			assert.Equal(t, tc.code, status.Code(err), tc.err.Error())
+		}
+	})
+}
+
+func TestServer_Login(t *testing.T) {
+	t.Run("login does not require a token", func(t *testing.T) {
+		mockService := new(MockUserService)
+		client := pvzv1.NewUserServiceClient(dialServer(t, nil, mockService))
+		// the user agent goes to the login history
+		withUserAgent := mock.MatchedBy(func(client services.ClientInfo) bool { return strings.Contains(client.UserAgent, "grpc-go") })
+		mockService.On("LoginUser", mock.Anything, "user@example.com", "password", withUserAgent).Return("token", nil)
+
+		resp, err := client.Login(context.Background(), &pvzv1.LoginRequest{Email: "user@example.com", Password: "password"})
+		require.NoError(t, err)
+		assert.Equal(t, "token", resp.GetToken())
+	})
+
+	t.Run("wrong password and unknown email look the same", func(t *testing.T) {
+		mockService := new(MockUserService)
+		client := pvzv1.NewUserServiceClient(dialServer(t, nil, mockService))
+		mockService.On("LoginUser", mock.Anything, "user@example.com", "wrong", mock.Anything).Return("", services.ErrWrongPassword)
+		mockService.On("LoginUser", mock.Anything, "nobody@example.com", "wrong", mock.Anything).Return("", services.ErrUserNotFound)
+
+		_, errWrong := client.Login(context.Background(), &pvzv1.LoginRequest{Email: "user@example.com", Password: "wrong"})
+		_, errUnknown := client.Login(context.Background(), &pvzv1.LoginRequest{Email: "nobody@example.com", Password: "wrong"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(errWrong))
+		assert.Equal(t, status.Convert(errWrong).Message(), status.Convert(errUnknown).Message())
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		mockService := new(MockUserService)
+		client := pvzv1.NewUserServiceClient(dialLimitedServer(t, nil, mockService, ratelimit.Limit{Requests: 1, Period: time.Minute}))
+		mockService.On("LoginUser", mock.Anything, "user@example.com", "password", mock.Anything).Return("token", nil).Once()
+
+		_, err := client.Login(context.Background(), &pvzv1.LoginRequest{Email: "user@example.com", Password: "password"})
+		require.NoError(t, err)
+
+		var header metadata.MD
+		_, err = client.Login(context.Background(), &pvzv1.LoginRequest{Email: "user@example.com", Password: "password"}, grpc.Header(&header))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, []string{"60"}, header.Get("retry-after"))
+		// the limit counts the public methods together
+		_, err = client.DummyLogin(context.Background(), &pvzv1.DummyLoginRequest{Role: "moderator"})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("dummy login rejects unknown role", func(t *testing.T) {
+		client := pvzv1.NewUserServiceClient(dialServer(t, nil, services.NewUserService(nil, nil, testTokens, services.Lockout{}, services.PasswordPolicy{}, 30, true)))
+
+		_, err := client.DummyLogin(context.Background(), &pvzv1.DummyLoginRequest{Role: "admin"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("dummy login outside dev mode", func(t *testing.T) {
+		userService := services.NewUserService(nil, nil, testTokens, services.Lockout{}, services.PasswordPolicy{}, 30, false)
+		client := pvzv1.NewUserServiceClient(dialServer(t, nil, userService))
+
+		_, err := client.DummyLogin(context.Background(), &pvzv1.DummyLoginRequest{Role: "moderator"})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
+
+func TestServer_Register(t *testing.T) {
+	user := models.User{ID: uuid.New(), Email: "moderator@example.com", Role: "moderator"}
+	request := func(code string) *pvzv1.RegisterRequest {
+		return &pvzv1.RegisterRequest{Email: user.Email, Password: "Sklad2026pvz", Role: "moderator", InvitationCode: code}
+	}
+
+	t.Run("moderator token", func(t *testing.T) {
+		mockService := new(MockUserService)
+		client := pvzv1.NewUserServiceClient(dialServer(t, nil, mockService))
+		mockService.On("RegisterUser", mock.Anything, user.Email, "Sklad2026pvz", "moderator",
+			services.RegistrationAuth{CallerRole: "moderator"}).Return(user, nil)
+
+		resp, err := client.Register(withToken(t, "moderator"), request(""))
+		require.NoError(t, err)
+		assert.Equal(t, "moderator", resp.GetRole())
+	})
+
+	t.Run("invitation code without a token", func(t *testing.T) {
+		mockService := new(MockUserService)
+		client := pvzv1.NewUserServiceClient(dialServer(t, nil, mockService))
+		mockService.On("RegisterUser", mock.Anything, user.Email, "Sklad2026pvz", "moderator",
+			services.RegistrationAuth{InvitationCode: "used"}).Return(models.User{}, repository.ErrInvalidInvitation)
+
+		_, err := client.Register(context.Background(), request("used"))
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("invalid token is rejected", func(t *testing.T) {
+		client := pvzv1.NewUserServiceClient(dialServer(t, nil, new(MockUserService)))
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer invalid")
+
+		_, err := client.Register(ctx, request(""))
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
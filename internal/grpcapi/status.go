@@ -0,0 +1,62 @@
+package grpcapi
+
+import (
+	"errors"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{services.ErrAccessDenied, codes.PermissionDenied},
+	{services.ErrCityNotAllowed, codes.InvalidArgument},
+	{services.ErrProductTypeNotAllowed, codes.InvalidArgument},
+	{services.ErrStartLaterThenEnd, codes.InvalidArgument},
+	{services.ErrPageParamIsInvalid, codes.InvalidArgument},
+	{services.ErrLimitParamIsInvalid, codes.InvalidArgument},
+	{services.ErrInvalidRole, codes.InvalidArgument},
+	{services.ErrEventTypeNotAllowed, codes.InvalidArgument},
+	{services.ErrInvalidWebhookURL, codes.InvalidArgument},
+	{services.ErrUserNotFound, codes.Unauthenticated},
+	{services.ErrWrongPassword, codes.Unauthenticated},
+	{services.ErrAccountLocked, codes.Unauthenticated},
+	{services.ErrWrongCurrentPassword, codes.InvalidArgument},
+	{services.ErrWeakPassword, codes.InvalidArgument},
+	{services.ErrDummyLoginDisabled, codes.Unimplemented},
+	{services.ErrAccountDeactivated, codes.PermissionDenied},
+	{repository.ErrUserExists, codes.AlreadyExists},
+	{repository.ErrActiveReceptionExists, codes.FailedPrecondition},
+	{repository.ErrPVZNotFound, codes.NotFound},
+	{repository.ErrReceptionConflict, codes.Aborted},
+	{repository.ErrNoActiveReception, codes.FailedPrecondition},
+	{repository.ErrEmptyReception, codes.FailedPrecondition},
+	{repository.ErrSubscriptionNotFound, codes.NotFound},
+	{repository.ErrDeliveryNotFound, codes.NotFound},
+	{repository.ErrUserNotFound, codes.NotFound},
+	{repository.ErrResetTokenNotFound, codes.InvalidArgument},
+	{repository.ErrInvalidInvitation, codes.PermissionDenied},
+}
+
+// toStatus maps service and repository errors to gRPC status codes. Unknown errors become Internal
+// without exposing their text.
+func toStatus(err error) error {
+	for _, mapping := range errorCodes {
+		if errors.Is(err, mapping.err) {
+			// do not reveal whether the email exists
+			if mapping.code == codes.Unauthenticated {
+				return status.Error(codes.Unauthenticated, "invalid email or password")
+			}
+			// the violated rules tell the client how to fix the password
+			if mapping.err == services.ErrWeakPassword {
+				return status.Error(mapping.code, err.Error())
+			}
+			return status.Error(mapping.code, mapping.err.Error())
+		}
+	}
+	return status.Error(codes.Internal, "internal server error")
+}
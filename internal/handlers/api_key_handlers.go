@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"pvz/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyServiceInterface
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// Create responds with the key; it cannot be looked up later.
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req struct {
+		Name        string      `json:"name" binding:"required"`
+		Permissions []string    `json:"permissions" binding:"required"`
+		PVZIDs      []uuid.UUID `json:"pvzIds"`
+		ExpiresAt   *time.Time  `json:"expiresAt"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	userID, role, err := getCaller(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), services.APIKeyRequest{
+		Name:        req.Name,
+		Permissions: req.Permissions,
+		PVZIDs:      req.PVZIDs,
+		ExpiresAt:   req.ExpiresAt,
+	}, userID, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) List(c *gin.Context) {
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	keys, err := h.apiKeyService.GetAPIKeys(c.Request.Context(), role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// Rotate responds with the key's new secret; the old one keeps working for API_KEY_ROTATION_GRACE.
+func (h *APIKeyHandler) Rotate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("apiKeyId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	key, err := h.apiKeyService.RotateAPIKey(c.Request.Context(), id, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("apiKeyId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), id, role); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, req services.APIKeyRequest, userID uuid.UUID, role string) (models.APIKey, error) {
+	args := m.Called(ctx, req, userID, role)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) GetAPIKeys(ctx context.Context, role string) ([]models.APIKey, error) {
+	args := m.Called(ctx, role)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RotateAPIKey(ctx context.Context, id uuid.UUID, role string) (models.APIKey, error) {
+	args := m.Called(ctx, id, role)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(mockService *MockAPIKeyService) *gin.Engine {
+		handler := NewAPIKeyHandler(mockService)
+		router := gin.New()
+		router.POST("/api-keys", moderatorAuthMock(), handler.Create)
+		router.GET("/api-keys", moderatorAuthMock(), handler.List)
+		router.POST("/api-keys/:apiKeyId/rotate", moderatorAuthMock(), handler.Rotate)
+		router.DELETE("/api-keys/:apiKeyId", moderatorAuthMock(), handler.Revoke)
+		return router
+	}
+	pvzID := uuid.New()
+	key := models.APIKey{
+		ID:          uuid.New(),
+		Name:        "wms",
+		Key:         "pvz_secret",
+		Prefix:      "pvz_secretse",
+		Permissions: []string{models.PermissionProductsWrite},
+		PVZIDs:      []uuid.UUID{pvzID},
+		CreatedBy:   testUserID,
+		CreatedAt:   time.Now(),
+	}
+
+	t.Run("create returns the key", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		mockService.On("CreateAPIKey", mock.Anything, services.APIKeyRequest{
+			Name: "wms", Permissions: []string{models.PermissionProductsWrite}, PVZIDs: []uuid.UUID{pvzID},
+		}, testUserID, "moderator").Return(key, nil)
+
+		body := `{"name":"wms","permissions":["products:write"],"pvzIds":["` + pvzID.String() + `"]}`
+		req := httptest.NewRequest("POST", "/api-keys", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var got models.APIKey
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, "pvz_secret", got.Key)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("create without permissions", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api-keys", bytes.NewBufferString(`{"name":"wms"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		newRouter(new(MockAPIKeyService)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("create with an unknown permission", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		mockService.On("CreateAPIKey", mock.Anything, mock.Anything, testUserID, "moderator").
+			Return(models.APIKey{}, services.ErrPermissionNotAllowed)
+
+		req := httptest.NewRequest("POST", "/api-keys", bytes.NewBufferString(`{"name":"wms","permissions":["pvz:write"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"permission_not_allowed"`)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		listed := key
+		listed.Key = ""
+		mockService.On("GetAPIKeys", mock.Anything, "moderator").Return([]models.APIKey{listed}, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("GET", "/api-keys", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), `"key"`)
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		mockService.On("RotateAPIKey", mock.Anything, key.ID, "moderator").Return(key, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("POST", "/api-keys/"+key.ID.String()+"/rotate", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"key":"pvz_secret"`)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		unknownID := uuid.New()
+		mockService.On("RevokeAPIKey", mock.Anything, key.ID, "moderator").Return(nil)
+		mockService.On("RevokeAPIKey", mock.Anything, unknownID, "moderator").Return(repository.ErrAPIKeyNotFound)
+		router := newRouter(mockService)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api-keys/"+key.ID.String(), nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api-keys/"+unknownID.String(), nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"api_key_not_found"`)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newRouter(new(MockAPIKeyService)).ServeHTTP(w, httptest.NewRequest("POST", "/api-keys/not-a-uuid/rotate", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"pvz/internal/i18n"
+	"pvz/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type dictionaryEntry struct {
+	Value string `json:"value"`
+	Name  string `json:"name"`
+}
+
+// DictionaryHandler returns the accepted cities and product types with display names in the
+// locale chosen by Accept-Language. Values are what the API expects in requests.
+func DictionaryHandler(c *gin.Context) {
+	locale := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+
+	cities := make([]dictionaryEntry, 0)
+	for _, city := range services.Cities() {
+		cities = append(cities, dictionaryEntry{Value: city, Name: i18n.CityName(locale, city)})
+	}
+
+	productTypes := make([]dictionaryEntry, 0)
+	for _, productType := range services.ProductTypes() {
+		productTypes = append(productTypes, dictionaryEntry{Value: productType, Name: i18n.ProductTypeName(locale, productType)})
+	}
+
+	c.Header("Content-Language", string(locale))
+	c.JSON(http.StatusOK, gin.H{"cities": cities, "productTypes": productTypes})
+}
@@ -1,9 +1,89 @@
 package handlers
 
-import "errors"
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"pvz/internal/problem"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
 
 var (
-	ErrUserExists      = errors.New("user already exists")
-	ErrWrongPassword   = errors.New("wrong password")
-	ErrUserDoesntExist = errors.New("no such user")
+	ErrUserExists      = repository.ErrUserExists
+	ErrWrongPassword   = services.ErrWrongPassword
+	ErrUserDoesntExist = services.ErrUserNotFound
 )
+
+// errorResponses is the single place where service and repository errors get an HTTP status and a code.
+// Business rule violations stay 400, as the public API contract has always returned for them.
+var errorResponses = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{services.ErrAccessDenied, http.StatusForbidden, problem.CodeAccessDenied},
+	{services.ErrCityNotAllowed, http.StatusBadRequest, problem.CodeCityNotAllowed},
+	{services.ErrProductTypeNotAllowed, http.StatusBadRequest, problem.CodeProductTypeNotAllowed},
+	{services.ErrStartLaterThenEnd, http.StatusBadRequest, problem.CodeInvalidDateRange},
+	{services.ErrPageParamIsInvalid, http.StatusBadRequest, problem.CodeInvalidPage},
+	{services.ErrInvalidRole, http.StatusBadRequest, problem.CodeInvalidRole},
+	{services.ErrEventTypeNotAllowed, http.StatusBadRequest, problem.CodeEventTypeNotAllowed},
+	{services.ErrInvalidWebhookURL, http.StatusBadRequest, problem.CodeInvalidWebhookURL},
+	{services.ErrUserNotFound, http.StatusUnauthorized, problem.CodeInvalidCredentials},
+	{services.ErrWrongPassword, http.StatusUnauthorized, problem.CodeInvalidCredentials},
+	// a locked account is not announced, so the lockout cannot be used to probe which emails exist
+	{services.ErrAccountLocked, http.StatusUnauthorized, problem.CodeInvalidCredentials},
+	{services.ErrWrongCurrentPassword, http.StatusBadRequest, problem.CodeWrongCurrentPassword},
+	{services.ErrDummyLoginDisabled, http.StatusNotFound, problem.CodeDummyLoginDisabled},
+	// only reported after the right password, see UserService.LoginUser
+	{services.ErrAccountDeactivated, http.StatusForbidden, problem.CodeAccountDeactivated},
+	{services.ErrOwnAccount, http.StatusBadRequest, problem.CodeOwnAccount},
+	{services.ErrPermissionNotAllowed, http.StatusBadRequest, problem.CodePermissionNotAllowed},
+	{services.ErrExpiryInPast, http.StatusBadRequest, problem.CodeInvalidExpiry},
+	{repository.ErrUserExists, http.StatusBadRequest, problem.CodeUserExists},
+	{repository.ErrPVZNotFound, http.StatusBadRequest, problem.CodePVZNotFound},
+	{repository.ErrActiveReceptionExists, http.StatusBadRequest, problem.CodeActiveReceptionExists},
+	{repository.ErrNoActiveReception, http.StatusBadRequest, problem.CodeNoActiveReception},
+	{repository.ErrEmptyReception, http.StatusBadRequest, problem.CodeEmptyReception},
+	{repository.ErrReceptionConflict, http.StatusConflict, problem.CodeReceptionConflict},
+	{repository.ErrSubscriptionNotFound, http.StatusNotFound, problem.CodeSubscriptionNotFound},
+	{repository.ErrDeliveryNotFound, http.StatusNotFound, problem.CodeDeliveryNotFound},
+	{repository.ErrUserNotFound, http.StatusNotFound, problem.CodeUserNotFound},
+	{repository.ErrResetTokenNotFound, http.StatusBadRequest, problem.CodeInvalidResetToken},
+	{repository.ErrInvalidInvitation, http.StatusForbidden, problem.CodeInvalidInvitation},
+	{repository.ErrInvitationNotFound, http.StatusNotFound, problem.CodeInvitationNotFound},
+	{repository.ErrAPIKeyNotFound, http.StatusNotFound, problem.CodeAPIKeyNotFound},
+}
+
+// respondError writes err as localized problem+json. Errors without a mapping are logged and reported as a
+// generic 500 so that database and driver messages never reach the client.
+func respondError(c *gin.Context, err error) {
+	var policyErr *services.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		problem.WriteViolations(c, http.StatusBadRequest, problem.CodeWeakPassword, policyErr.Violations)
+		return
+	}
+	var limitErr *services.LimitError
+	if errors.As(err, &limitErr) {
+		problem.Writef(c, http.StatusBadRequest, problem.CodeInvalidLimit, limitErr.Max)
+		return
+	}
+
+	for _, mapping := range errorResponses {
+		if errors.Is(err, mapping.err) {
+			// the detail comes from the message catalog, so unknown email, wrong password and lockout look the same
+			problem.Write(c, mapping.status, mapping.code, "")
+			return
+		}
+	}
+
+	slog.ErrorContext(c.Request.Context(), "request failed", slog.Any("error", err))
+	problem.Write(c, http.StatusInternalServerError, problem.CodeInternal, "")
+}
+
+func respondBadRequest(c *gin.Context, detail string) {
+	problem.Write(c, http.StatusBadRequest, problem.CodeInvalidRequest, detail)
+}
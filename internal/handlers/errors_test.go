@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pvz/internal/problem"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRespondError(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"access denied", services.ErrAccessDenied, http.StatusForbidden, problem.CodeAccessDenied},
+		{"wrapped sentinel", fmt.Errorf("insert reception: %w", repository.ErrActiveReceptionExists), http.StatusBadRequest, problem.CodeActiveReceptionExists},
+		{"empty reception", repository.ErrEmptyReception, http.StatusBadRequest, problem.CodeEmptyReception},
+		{"reception conflict", repository.ErrReceptionConflict, http.StatusConflict, problem.CodeReceptionConflict},
+		{"subscription not found", repository.ErrSubscriptionNotFound, http.StatusNotFound, problem.CodeSubscriptionNotFound},
+		{"unknown user", services.ErrUserNotFound, http.StatusUnauthorized, problem.CodeInvalidCredentials},
+		{"unexpected error", errors.New(`pq: relation "pvz" does not exist`), http.StatusInternalServerError, problem.CodeInternal},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/fail", func(c *gin.Context) { respondError(c, tc.err) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+			var details problem.Details
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+			assert.Equal(t, tc.status, details.Status)
+			assert.Equal(t, tc.code, details.Code)
+			assert.Equal(t, "urn:pvz:problem:"+tc.code, details.Type)
+			assert.Equal(t, "/fail", details.Instance)
+			assert.NotContains(t, w.Body.String(), "pq:")
+		})
+	}
+}
+
+func TestProductHandler_Add_ProblemResponse(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	router := gin.New()
+	router.POST("/products", jwtAuthMock(), handler.Add)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/products", nil)
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var details problem.Details
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+	assert.Equal(t, problem.CodeInvalidRequest, details.Code)
+	assert.Equal(t, "Invalid request", details.Title)
+}
+
+func TestRespondError_Localized(t *testing.T) {
+	router := gin.New()
+	router.GET("/fail", func(c *gin.Context) { respondError(c, repository.ErrNoActiveReception) })
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/fail", nil)
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "ru", w.Header().Get("Content-Language"))
+
+	var details problem.Details
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+	assert.Equal(t, problem.CodeNoActiveReception, details.Code)
+	assert.Equal(t, "Нет открытой приёмки", details.Title)
+	assert.Equal(t, "В этом ПВЗ нет незакрытой приёмки.", details.Detail)
+}
+
+func TestRespondError_LimitDetail(t *testing.T) {
+	router := gin.New()
+	router.GET("/fail", func(c *gin.Context) { respondError(c, &services.LimitError{Max: 50}) })
+
+	for language, want := range map[string]string{
+		"en": "limit must be between 1 and 50",
+		"ru": "Лимит должен быть от 1 до 50.",
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/fail", nil)
+		req.Header.Set("Accept-Language", language)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeInvalidLimit, details.Code)
+		assert.Equal(t, want, details.Detail)
+	}
+}
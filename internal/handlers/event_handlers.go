@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pvz/internal/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	sseRetryMillis    = 3000
+	sseHeartbeatEvery = 15 * time.Second
+)
+
+type EventHandler struct {
+	eventService services.EventServiceInterface
+	pollInterval time.Duration
+}
+
+func NewEventHandler(eventService services.EventServiceInterface, pollInterval time.Duration) *EventHandler {
+	return &EventHandler{eventService: eventService, pollInterval: pollInterval}
+}
+
+// Stream serves live pvz activity as server-sent events. The SSE id of every event is its outbox position,
+// so a client reconnecting with Last-Event-ID continues right after the last event it has seen. Events
+// are ordered by the transaction that wrote them, so ids do not always increase.
+func (h *EventHandler) Stream(c *gin.Context) {
+	pvzID, err := uuid.Parse(c.Param("pvzId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var lastSeq int64
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		lastSeq, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || lastSeq < 0 {
+			respondBadRequest(c, "invalid Last-Event-ID")
+			return
+		}
+	} else {
+		// a fresh subscriber only gets events that happen from now on
+		lastSeq, err = h.eventService.GetLastEventSeq(ctx, pvzID, role)
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	// the stream outlives the server's write timeout; not every writer supports deadlines, e.g. in tests
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetryMillis)
+	c.Writer.Flush()
+
+	poll := time.NewTicker(h.pollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(sseHeartbeatEvery)
+	defer heartbeat.Stop()
+
+	for {
+		events, err := h.eventService.GetPVZEvents(ctx, pvzID, lastSeq, role)
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Fprintf(c.Writer, "event: error\ndata: %q\n\n", "failed to load events")
+				c.Writer.Flush()
+			}
+			return
+		}
+
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+			lastSeq = event.Seq
+		}
+		if len(events) > 0 {
+			c.Writer.Flush()
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		case <-poll.C:
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pvz/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEventService struct {
+	mock.Mock
+}
+
+func (m *MockEventService) GetPVZEvents(ctx context.Context, pvzID uuid.UUID, afterSeq int64, role string) ([]models.Event, error) {
+	args := m.Called(ctx, pvzID, afterSeq, role)
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockEventService) GetLastEventSeq(ctx context.Context, pvzID uuid.UUID, role string) (int64, error) {
+	args := m.Called(ctx, pvzID, role)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// streamFor runs the SSE handler until the request context times out and returns the response.
+func streamFor(router *gin.Engine, path string, headers map[string]string) *httptest.ResponseRecorder {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	req := httptest.NewRequest("GET", path, nil).WithContext(ctx)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestEventHandler_Stream(t *testing.T) {
+	pvzID := uuid.New()
+	path := "/pvz/" + pvzID.String() + "/events"
+	payload, _ := json.Marshal(map[string]string{"type": "обувь"})
+
+	t.Run("resumes from Last-Event-ID", func(t *testing.T) {
+		mockService := new(MockEventService)
+		router := gin.Default()
+		router.GET("/pvz/:pvzId/events", jwtAuthMock(), NewEventHandler(mockService, 10*time.Millisecond).Stream)
+
+		events := []models.Event{
+			{Seq: 6, ID: uuid.New(), Type: models.EventProductAdded, PVZID: pvzID, Payload: payload},
+			{Seq: 7, ID: uuid.New(), Type: models.EventReceptionClosed, PVZID: pvzID, Payload: payload},
+		}
+		mockService.On("GetPVZEvents", mock.Anything, pvzID, int64(5), "employee").Return(events, nil).Once()
+		mockService.On("GetPVZEvents", mock.Anything, pvzID, int64(7), "employee").Return([]models.Event{}, nil)
+
+		w := streamFor(router, path, map[string]string{"Last-Event-ID": "5"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		assert.Contains(t, body, "id: 6\nevent: product.added\n")
+		assert.Contains(t, body, "id: 7\nevent: reception.closed\n")
+		mockService.AssertNotCalled(t, "GetLastEventSeq", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fresh subscriber starts from the latest event", func(t *testing.T) {
+		mockService := new(MockEventService)
+		router := gin.Default()
+		router.GET("/pvz/:pvzId/events", jwtAuthMock(), NewEventHandler(mockService, 10*time.Millisecond).Stream)
+
+		mockService.On("GetLastEventSeq", mock.Anything, pvzID, "employee").Return(int64(42), nil)
+		mockService.On("GetPVZEvents", mock.Anything, pvzID, int64(42), "employee").Return([]models.Event{}, nil)
+
+		w := streamFor(router, path, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "retry: ")
+		assert.NotContains(t, w.Body.String(), "id: ")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid Last-Event-ID", func(t *testing.T) {
+		mockService := new(MockEventService)
+		router := gin.Default()
+		router.GET("/pvz/:pvzId/events", jwtAuthMock(), NewEventHandler(mockService, 10*time.Millisecond).Stream)
+
+		w := streamFor(router, path, map[string]string{"Last-Event-ID": "abc"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"pvz/internal/buildinfo"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	statusUp   = "up"
+	statusDown = "down"
+)
+
+// HealthHandler serves the probes. Both are public and cheap enough to be polled every few seconds.
+type HealthHandler struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func NewHealthHandler(db *sql.DB, timeout time.Duration) *HealthHandler {
+	return &HealthHandler{db: db, timeout: timeout}
+}
+
+type readinessReport struct {
+	Status   string        `json:"status"`
+	Database databaseCheck `json:"database"`
+}
+
+type databaseCheck struct {
+	Status    string     `json:"status"`
+	Error     string     `json:"error,omitempty"`
+	LatencyMs int64      `json:"latencyMs"`
+	Pool      *poolStats `json:"pool,omitempty"`
+}
+
+type poolStats struct {
+	MaxOpenConnections int   `json:"maxOpenConnections"`
+	OpenConnections    int   `json:"openConnections"`
+	InUse              int   `json:"inUse"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"waitCount"`
+	WaitDurationMs     int64 `json:"waitDurationMs"`
+	MaxIdleClosed      int64 `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64 `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64 `json:"maxLifetimeClosed"`
+}
+
+// Liveness only reports that the process handles requests; a database outage must not get it restarted.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": statusUp})
+}
+
+// Readiness pings the database and reports the connection pool. It answers 503 while the
+// database is unreachable so the instance is taken out of load balancing.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, readinessReport{
+			Status:   statusDown,
+			Database: databaseCheck{Status: statusDown, Error: "database is not configured"},
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+	start := time.Now()
+	err := h.db.PingContext(ctx)
+
+	stats := h.db.Stats()
+	check := databaseCheck{
+		Status:    statusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Pool: &poolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		},
+	}
+	if err != nil {
+		// the probe is public: the cause, which may name hosts, goes to the log only
+		slog.WarnContext(ctx, "readiness: database ping failed", slog.Any("error", err))
+		check.Status = statusDown
+		check.Error = "ping failed"
+		// drivers report a cancelled ping in their own words, the context knows why
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			check.Error = "ping timed out"
+		}
+		c.JSON(http.StatusServiceUnavailable, readinessReport{Status: statusDown, Database: check})
+		return
+	}
+
+	c.JSON(http.StatusOK, readinessReport{Status: statusUp, Database: check})
+}
+
+func VersionHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, buildinfo.Get())
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pvz/internal/buildinfo"
+	"pvz/internal/notify"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler_Readiness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("database up", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		defer db.Close()
+		mock.ExpectPing()
+
+		router := gin.New()
+		router.GET("/readyz", NewHealthHandler(db, time.Second).Readiness)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var report readinessReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, statusUp, report.Status)
+		assert.Equal(t, statusUp, report.Database.Status)
+		assert.NotNil(t, report.Database.Pool)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database down", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		defer db.Close()
+		mock.ExpectPing().WillReturnError(errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+
+		router := gin.New()
+		router.GET("/readyz", NewHealthHandler(db, time.Second).Readiness)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		var report readinessReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, statusDown, report.Status)
+		assert.Equal(t, "ping failed", report.Database.Error)
+		assert.NotContains(t, w.Body.String(), "10.0.0.5")
+	})
+
+	t.Run("ping timeout", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		defer db.Close()
+		mock.ExpectPing().WillDelayFor(time.Second)
+
+		router := gin.New()
+		router.GET("/readyz", NewHealthHandler(db, 10*time.Millisecond).Readiness)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "ping timed out")
+	})
+}
+
+func TestServiceEndpointsArePublic(t *testing.T) {
+	router := gin.New()
+	setupTestRoutes(router, notify.NewLogNotifier())
+
+	for path, status := range map[string]int{
+		"/healthz": http.StatusOK,
+		"/readyz":  http.StatusServiceUnavailable, // no database in this test
+		"/version": http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, status, w.Code, path)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/version", nil))
+	var info buildinfo.Info
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+	assert.Equal(t, buildinfo.Version, info.Version)
+	assert.NotEmpty(t, info.Commit)
+	assert.NotEmpty(t, info.GoVersion)
+}
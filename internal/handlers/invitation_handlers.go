@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"pvz/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InvitationHandler struct {
+	invitationService services.InvitationServiceInterface
+}
+
+func NewInvitationHandler(invitationService services.InvitationServiceInterface) *InvitationHandler {
+	return &InvitationHandler{invitationService: invitationService}
+}
+
+// Create responds with the invitation code; it cannot be looked up later.
+func (h *InvitationHandler) Create(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"omitempty,email"`
+	}
+
+	// the body is optional: an invitation without an email works for any email
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Invalid request format: "+err.Error())
+			return
+		}
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(c.Request.Context(), req.Email, userID, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+func (h *InvitationHandler) List(c *gin.Context) {
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	invitations, err := h.invitationService.GetInvitations(c.Request.Context(), role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+func (h *InvitationHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("invitationId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := h.invitationService.DeleteInvitation(c.Request.Context(), id, role); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
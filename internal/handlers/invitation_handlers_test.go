@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInvitationService struct {
+	mock.Mock
+}
+
+func (m *MockInvitationService) CreateInvitation(ctx context.Context, email string, userID uuid.UUID, role string) (models.Invitation, error) {
+	args := m.Called(ctx, email, userID, role)
+	return args.Get(0).(models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationService) GetInvitations(ctx context.Context, role string) ([]models.Invitation, error) {
+	args := m.Called(ctx, role)
+	return args.Get(0).([]models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationService) DeleteInvitation(ctx context.Context, id uuid.UUID, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func TestInvitationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(mockService *MockInvitationService) *gin.Engine {
+		handler := NewInvitationHandler(mockService)
+		router := gin.New()
+		router.POST("/invitations", moderatorAuthMock(), handler.Create)
+		router.POST("/employee/invitations", jwtAuthMock(), handler.Create)
+		router.GET("/invitations", moderatorAuthMock(), handler.List)
+		router.DELETE("/invitations/:invitationId", moderatorAuthMock(), handler.Delete)
+		return router
+	}
+	invitation := models.Invitation{
+		ID:        uuid.New(),
+		Code:      "secret-code",
+		Email:     "new@example.com",
+		CreatedBy: testUserID,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(72 * time.Hour),
+	}
+
+	t.Run("create returns the code", func(t *testing.T) {
+		mockService := new(MockInvitationService)
+		mockService.On("CreateInvitation", mock.Anything, "new@example.com", testUserID, "moderator").Return(invitation, nil)
+
+		req := httptest.NewRequest("POST", "/invitations", bytes.NewBufferString(`{"email":"new@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var got models.Invitation
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, "secret-code", got.Code)
+	})
+
+	t.Run("create without a body", func(t *testing.T) {
+		mockService := new(MockInvitationService)
+		mockService.On("CreateInvitation", mock.Anything, "", testUserID, "moderator").Return(invitation, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("POST", "/invitations", nil))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("create with an invalid email", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/invitations", bytes.NewBufferString(`{"email":"not-an-email"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		newRouter(new(MockInvitationService)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("employee", func(t *testing.T) {
+		mockService := new(MockInvitationService)
+		mockService.On("CreateInvitation", mock.Anything, "", testUserID, "employee").Return(models.Invitation{}, services.ErrAccessDenied)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("POST", "/employee/invitations", nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		mockService := new(MockInvitationService)
+		listed := invitation
+		listed.Code = ""
+		mockService.On("GetInvitations", mock.Anything, "moderator").Return([]models.Invitation{listed}, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("GET", "/invitations", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), `"code"`)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockService := new(MockInvitationService)
+		unknownID := uuid.New()
+		mockService.On("DeleteInvitation", mock.Anything, invitation.ID, "moderator").Return(nil)
+		mockService.On("DeleteInvitation", mock.Anything, unknownID, "moderator").Return(repository.ErrInvitationNotFound)
+		router := newRouter(mockService)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("DELETE", "/invitations/"+invitation.ID.String(), nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("DELETE", "/invitations/"+unknownID.String(), nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invitation_not_found"`)
+	})
+}
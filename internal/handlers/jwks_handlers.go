@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+	"pvz/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jwksMaxAge lets verifiers cache the key set. Keys are published before they start signing, so a
+// cached set only misses a key added less than this before its ActiveFrom.
+const jwksMaxAge = "public, max-age=300"
+
+// JWKSHandler serves the public keys other services verify our tokens with. With HS256 the set is empty.
+func JWKSHandler(tokens *services.TokenManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", jwksMaxAge)
+		c.JSON(http.StatusOK, tokens.JWKS())
+	}
+}
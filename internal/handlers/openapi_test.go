@@ -0,0 +1,508 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pvz/api"
+	"pvz/internal/config"
+	"pvz/internal/metrics"
+	"pvz/internal/models"
+	"pvz/internal/notify"
+	"pvz/internal/ratelimit"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPVZService struct {
+	mock.Mock
+}
+
+func (m *MockPVZService) CreatePVZ(ctx context.Context, city, role string) (models.PVZ, error) {
+	args := m.Called(ctx, city, role)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockPVZService) GetPVZList(ctx context.Context, startDate, endDate *time.Time, createdBy, closedBy *uuid.UUID, page, limit int, role string) ([]models.PVZWithReceptions, error) {
+	args := m.Called(ctx, startDate, endDate, createdBy, closedBy, page, limit, role)
+	return args.Get(0).([]models.PVZWithReceptions), args.Error(1)
+}
+
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.JWT.Secret = "test-secret"
+	return cfg
+}
+
+// setupTestRoutes registers the routes the way main does, on top of a nil database.
+func setupTestRoutes(router *gin.Engine, notifier notify.NotifierInterface) {
+	cfg := testConfig()
+	tokens := services.NewTokenManager(cfg.JWT.Secret, time.Hour, "pvz-service", "pvz-api")
+	userService := services.NewUserService(repository.NewUserRepository(nil), repository.NewLoginAttemptRepository(nil),
+		tokens, services.Lockout{}, services.PasswordPolicy{}, cfg.Limits.UserListMax, cfg.Auth.DevMode)
+	SetupRoutes(nil, router, cfg, tokens, metrics.New(nil), notifier, userService, ratelimit.NewMemoryStore())
+}
+
+func loadOpenAPISpec(t *testing.T) (*openapi3.T, routers.Router) {
+	t.Helper()
+
+	doc, err := openapi3.NewLoader().LoadFromData(api.OpenAPISpec)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+	return doc, router
+}
+
+// serveAndValidate checks the request against the spec, serves it and checks the response.
+func serveAndValidate(t *testing.T, specRouter routers.Router, handler http.Handler, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	// the spec is served under /api/v1, the handler under test is mounted at the bare path
+	newRequest := func(prefix string) *http.Request {
+		req := httptest.NewRequest(method, prefix+path, bytes.NewReader(payload))
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return req
+	}
+
+	route, pathParams, err := specRouter.FindRoute(newRequest(APIV1Prefix))
+	require.NoError(t, err, "route %s %s is not in the spec", method, path)
+
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    newRequest(APIV1Prefix),
+		PathParams: pathParams,
+		Route:      route,
+		Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+	}
+	require.NoError(t, openapi3filter.ValidateRequest(context.Background(), requestInput))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newRequest(""))
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestInput,
+		Status:                 w.Code,
+		Header:                 w.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(w.Body.Bytes())),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+	assert.NoError(t, openapi3filter.ValidateResponse(context.Background(), responseInput), w.Body.String())
+	return w
+}
+
+// serviceEndpoints are served next to the API but are not part of it.
+var serviceEndpoints = map[string]bool{
+	"/healthz":               true,
+	"/readyz":                true,
+	"/version":               true,
+	"/metrics":               true,
+	"/openapi.json":          true,
+	"/docs":                  true,
+	"/.well-known/jwks.json": true,
+}
+
+func TestOpenAPI_CoversRoutes(t *testing.T) {
+	doc, _ := loadOpenAPISpec(t)
+
+	router := gin.New()
+	setupTestRoutes(router, notify.NewLogNotifier())
+
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	versioned := make(map[string]bool)
+	legacy := make(map[string]bool)
+	for _, route := range router.Routes() {
+		if serviceEndpoints[route.Path] {
+			continue
+		}
+		segments := strings.Split(route.Path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				segments[i] = "{" + segment[1:] + "}"
+			}
+		}
+		path := strings.Join(segments, "/")
+		if strings.HasPrefix(path, APIV1Prefix+"/") {
+			key := route.Method + " " + strings.TrimPrefix(path, APIV1Prefix)
+			versioned[key] = true
+			assert.True(t, documented[key], "route %s is missing from the spec", key)
+		} else {
+			legacy[route.Method+" "+path] = true
+		}
+	}
+
+	for key := range documented {
+		assert.True(t, versioned[key], "spec documents %s, but no such route is registered under %s", key, APIV1Prefix)
+		assert.True(t, legacy[key], "%s has no deprecated root alias", key)
+	}
+	assert.Equal(t, len(versioned), len(legacy))
+}
+
+func TestLegacyRoutesAreDeprecated(t *testing.T) {
+	router := gin.New()
+	setupTestRoutes(router, notify.NewLogNotifier())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/dictionaries", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v1/dictionaries>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", APIV1Prefix+"/dictionaries", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+
+	// aliases stay behind the same JWT check as the versioned routes
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/pvz", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Deprecation"))
+}
+
+func TestOpenAPI_ServesSpec(t *testing.T) {
+	router := gin.New()
+	router.GET("/openapi.json", OpenAPIHandler)
+	router.GET("/docs", SwaggerUIHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, string(api.OpenAPISpec), w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/docs", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "/openapi.json")
+}
+
+func TestOpenAPI_ResponsesMatchSchema(t *testing.T) {
+	_, specRouter := loadOpenAPISpec(t)
+	pvzID := uuid.New()
+	now := time.Now().UTC()
+
+	t.Run("dictionaries", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/dictionaries", DictionaryHandler)
+
+		w := serveAndValidate(t, specRouter, router, "GET", "/dictionaries", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `{"value":"Москва","name":"Moscow"}`)
+	})
+
+	t.Run("pvz", func(t *testing.T) {
+		mockService := new(MockPVZService)
+		handler := NewPVZHandler(mockService, 10)
+		router := gin.New()
+		router.POST("/pvz", moderatorAuthMock(), handler.CreatePVZ)
+		router.GET("/pvz", moderatorAuthMock(), handler.GetPVZInfo)
+
+		mockService.On("CreatePVZ", mock.Anything, "Казань", "moderator").
+			Return(models.PVZ{ID: pvzID, RegistrationDate: now, City: "Казань"}, nil)
+		mockService.On("CreatePVZ", mock.Anything, "Казань", "employee").
+			Return(models.PVZ{}, services.ErrAccessDenied)
+		employeeRouter := gin.New()
+		employeeRouter.POST("/pvz", jwtAuthMock(), handler.CreatePVZ)
+
+		closedBy := uuid.New()
+		list := []models.PVZWithReceptions{{
+			ID: pvzID, City: "Казань", RegistrationDate: now,
+			Receptions: []models.ReceptionWithProducts{{
+				Reception: models.Reception{DateTime: now, PVZID: pvzID, Status: models.ReceptionStatusClosed, CreatedBy: testUserID, ClosedBy: &closedBy},
+				Products:  []models.Product{{ProductType: "обувь", ReceptionID: uuid.New(), CreatedBy: testUserID}},
+			}},
+		}}
+		mockService.On("GetPVZList", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, 1, 10, "moderator").
+			Return(list, nil)
+
+		w := serveAndValidate(t, specRouter, router, "POST", "/pvz", map[string]string{"city": "Казань"})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		w = serveAndValidate(t, specRouter, employeeRouter, "POST", "/pvz", map[string]string{"city": "Казань"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = serveAndValidate(t, specRouter, router, "GET", "/pvz?startDate="+now.Format(time.RFC3339), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("receptions", func(t *testing.T) {
+		mockService := new(MockReceptionService)
+		handler := NewReceptionHandler(mockService)
+		router := gin.New()
+		router.POST("/reception", jwtAuthMock(), handler.Create)
+		router.PUT("/pvz/:pvzId/close_last_reception", jwtAuthMock(), handler.Close)
+
+		reception := models.Reception{DateTime: now, PVZID: pvzID, Status: models.ReceptionStatusInProgress, CreatedBy: testUserID}
+		mockService.On("CreateReception", mock.Anything, pvzID, testUserID, "employee").Return(reception, nil)
+		closed := reception
+		closed.Status = models.ReceptionStatusClosed
+		closed.ClosedBy = &testUserID
+		mockService.On("CloseReception", mock.Anything, pvzID, testUserID, "employee").Return(closed, nil)
+
+		w := serveAndValidate(t, specRouter, router, "POST", "/reception", map[string]string{"pvzId": pvzID.String()})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		w = serveAndValidate(t, specRouter, router, "PUT", "/pvz/"+pvzID.String()+"/close_last_reception", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("products", func(t *testing.T) {
+		mockService := new(MockProductService)
+		handler := NewProductHandler(mockService)
+		router := gin.New()
+		router.POST("/products", jwtAuthMock(), handler.Add)
+		router.DELETE("/pvz/:pvzId/delete_last_product", jwtAuthMock(), handler.Delete)
+
+		product := models.Product{ProductType: "одежда", ReceptionID: uuid.New(), CreatedBy: testUserID}
+		mockService.On("AddProduct", mock.Anything, "одежда", pvzID, testUserID, "employee").Return(product, nil)
+		mockService.On("DeleteProduct", mock.Anything, pvzID, "employee").Return(nil)
+
+		w := serveAndValidate(t, specRouter, router, "POST", "/products", map[string]string{"type": "одежда", "pvzId": pvzID.String()})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		w = serveAndValidate(t, specRouter, router, "DELETE", "/pvz/"+pvzID.String()+"/delete_last_product", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("auth", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewUserHandler(mockService, 10)
+		router := gin.New()
+		router.POST("/register", handler.Register)
+		router.POST("/login", handler.Login)
+		router.POST("/dummyLogin", handler.DummyLogin)
+		router.GET("/me", jwtAuthMock(), handler.Me)
+
+		user := models.User{ID: uuid.New(), Email: "user@example.com", Role: "employee"}
+		mockService.On("RegisterUser", mock.Anything, user.Email, "password", "employee", services.RegistrationAuth{}).Return(user, nil)
+		mockService.On("LoginUser", mock.Anything, user.Email, "password", mock.Anything).Return("token", nil)
+		mockService.On("LoginUser", mock.Anything, user.Email, "wrong", mock.Anything).Return("", ErrWrongPassword)
+		mockService.On("DummyLogin", "moderator").Return("token", nil)
+
+		w := serveAndValidate(t, specRouter, router, "POST", "/register", map[string]string{"email": user.Email, "password": "password", "role": "employee"})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/login", map[string]string{"email": user.Email, "password": "password"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/login", map[string]string{"email": user.Email, "password": "wrong"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/dummyLogin", map[string]string{"role": "moderator"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.On("GetProfile", mock.Anything, testUserID, "employee").
+			Return(models.Profile{ID: testUserID, Role: "employee", AssignedPVZs: []uuid.UUID{}, Dummy: true}, nil)
+		w = serveAndValidate(t, specRouter, router, "GET", "/me", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		mockService.On("RegisterUser", mock.Anything, "moderator@example.com", "password", "moderator", services.RegistrationAuth{}).
+			Return(models.User{}, services.ErrAccessDenied)
+		mockService.On("DummyLogin", "employee").Return("", services.ErrDummyLoginDisabled)
+		w = serveAndValidate(t, specRouter, router, "POST", "/register", map[string]string{"email": "moderator@example.com", "password": "password", "role": "moderator"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/dummyLogin", map[string]string{"role": "employee"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("passwords", func(t *testing.T) {
+		userService := new(MockUserService)
+		resetService := new(MockPasswordResetService)
+		userHandler := NewUserHandler(userService, 10)
+		resetHandler := NewPasswordResetHandler(resetService)
+		router := gin.New()
+		router.POST("/me/password", jwtAuthMock(), userHandler.ChangePassword)
+		router.POST("/password/reset-request", resetHandler.Request)
+		router.POST("/password/reset", resetHandler.Complete)
+
+		userService.On("ChangePassword", mock.Anything, testUserID, "Old-secret1", "New-secret1").Return(nil)
+		userService.On("ChangePassword", mock.Anything, testUserID, "Old-secret1", "weak").Return(&services.PasswordPolicyError{
+			Violations: []string{services.PasswordTooShort, services.PasswordBreached},
+		})
+		resetService.On("RequestPasswordReset", mock.Anything, "user@example.com").Return(nil)
+		resetService.On("CompletePasswordReset", mock.Anything, "expired", "New-secret1").Return(repository.ErrResetTokenNotFound)
+
+		w := serveAndValidate(t, specRouter, router, "POST", "/me/password", map[string]string{"currentPassword": "Old-secret1", "newPassword": "New-secret1"})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/me/password", map[string]string{"currentPassword": "Old-secret1", "newPassword": "weak"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/password/reset-request", map[string]string{"email": "user@example.com"})
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/password/reset", map[string]string{"token": "expired", "password": "New-secret1"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("users", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewUserHandler(mockService, 10)
+		router := gin.New()
+		router.POST("/users/:userId/unlock", moderatorAuthMock(), handler.Unlock)
+		router.GET("/users/:userId/login-history", moderatorAuthMock(), handler.LoginHistory)
+
+		userID, unknownID := uuid.New(), uuid.New()
+		mockService.On("UnlockUser", mock.Anything, userID, "moderator").Return(nil)
+		mockService.On("UnlockUser", mock.Anything, unknownID, "moderator").Return(repository.ErrUserNotFound)
+		mockService.On("GetLoginHistory", mock.Anything, userID, "moderator").Return([]models.LoginAttempt{
+			{ID: uuid.New(), UserID: &userID, Email: "user@example.com", IP: "192.0.2.10", UserAgent: "curl/8.0",
+				FailureReason: models.LoginFailureWrongPassword, CreatedAt: now},
+			{ID: uuid.New(), UserID: &userID, Email: "user@example.com", IP: "192.0.2.10", Success: true, CreatedAt: now},
+		}, nil)
+
+		w := serveAndValidate(t, specRouter, router, "POST", "/users/"+userID.String()+"/unlock", nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/users/"+unknownID.String()+"/unlock", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = serveAndValidate(t, specRouter, router, "GET", "/users/"+userID.String()+"/login-history", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invitations", func(t *testing.T) {
+		mockService := new(MockInvitationService)
+		handler := NewInvitationHandler(mockService)
+		router := gin.New()
+		router.POST("/invitations", moderatorAuthMock(), handler.Create)
+		router.GET("/invitations", moderatorAuthMock(), handler.List)
+		router.DELETE("/invitations/:invitationId", moderatorAuthMock(), handler.Delete)
+
+		usedBy := uuid.New()
+		invitation := models.Invitation{ID: uuid.New(), Email: "new@example.com", CreatedBy: testUserID, CreatedAt: now, ExpiresAt: now.Add(72 * time.Hour)}
+		created := invitation
+		created.Code = "secret-code"
+		used := models.Invitation{ID: uuid.New(), CreatedBy: testUserID, CreatedAt: now, ExpiresAt: now, UsedBy: &usedBy, UsedAt: &now}
+		mockService.On("CreateInvitation", mock.Anything, "new@example.com", testUserID, "moderator").Return(created, nil)
+		mockService.On("GetInvitations", mock.Anything, "moderator").Return([]models.Invitation{invitation, used}, nil)
+		mockService.On("DeleteInvitation", mock.Anything, invitation.ID, "moderator").Return(nil)
+		mockService.On("DeleteInvitation", mock.Anything, used.ID, "moderator").Return(repository.ErrInvitationNotFound)
+
+		w := serveAndValidate(t, specRouter, router, "POST", "/invitations", map[string]string{"email": "new@example.com"})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		w = serveAndValidate(t, specRouter, router, "GET", "/invitations", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = serveAndValidate(t, specRouter, router, "DELETE", "/invitations/"+invitation.ID.String(), nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = serveAndValidate(t, specRouter, router, "DELETE", "/invitations/"+used.ID.String(), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("api keys", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		handler := NewAPIKeyHandler(mockService)
+		router := gin.New()
+		router.POST("/api-keys", moderatorAuthMock(), handler.Create)
+		router.GET("/api-keys", moderatorAuthMock(), handler.List)
+		router.POST("/api-keys/:apiKeyId/rotate", moderatorAuthMock(), handler.Rotate)
+		router.DELETE("/api-keys/:apiKeyId", moderatorAuthMock(), handler.Revoke)
+
+		key := models.APIKey{ID: uuid.New(), Name: "wms", Prefix: "pvz_abcdefgh", Permissions: []string{models.PermissionReceptionsWrite},
+			PVZIDs: []uuid.UUID{uuid.New()}, CreatedBy: testUserID, CreatedAt: now, ExpiresAt: &now}
+		issued := key
+		issued.Key = "pvz_abcdefghsecret"
+		revoked := models.APIKey{ID: uuid.New(), Name: "old", Prefix: "pvz_hgfedcba", Permissions: []string{models.PermissionProductsWrite},
+			PVZIDs: []uuid.UUID{}, CreatedBy: testUserID, CreatedAt: now, RotatedAt: &now, RevokedAt: &now}
+		mockService.On("CreateAPIKey", mock.Anything, mock.Anything, testUserID, "moderator").Return(issued, nil)
+		mockService.On("GetAPIKeys", mock.Anything, "moderator").Return([]models.APIKey{key, revoked}, nil)
+		mockService.On("RotateAPIKey", mock.Anything, key.ID, "moderator").Return(issued, nil)
+		mockService.On("RotateAPIKey", mock.Anything, revoked.ID, "moderator").Return(models.APIKey{}, repository.ErrAPIKeyNotFound)
+		mockService.On("RevokeAPIKey", mock.Anything, key.ID, "moderator").Return(nil)
+
+		w := serveAndValidate(t, specRouter, router, "POST", "/api-keys", map[string]interface{}{
+			"name": "wms", "permissions": []string{"receptions:write"}, "pvzIds": key.PVZIDs, "expiresAt": now,
+		})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		w = serveAndValidate(t, specRouter, router, "GET", "/api-keys", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/api-keys/"+key.ID.String()+"/rotate", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/api-keys/"+revoked.ID.String()+"/rotate", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = serveAndValidate(t, specRouter, router, "DELETE", "/api-keys/"+key.ID.String(), nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("users", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewUserHandler(mockService, 10)
+		router := gin.New()
+		router.Use(moderatorAuthMock())
+		router.GET("/users", handler.List)
+		router.GET("/users/:userId", handler.Get)
+		router.PUT("/users/:userId/role", handler.ChangeRole)
+		router.POST("/users/:userId/deactivate", handler.Deactivate)
+		router.POST("/users/:userId/reactivate", handler.Reactivate)
+		router.DELETE("/users/:userId", handler.Delete)
+
+		deactivated := true
+		user := models.User{ID: uuid.New(), Email: "gone@example.com", Role: "employee", DeactivatedAt: &now}
+		mockService.On("GetUsers", mock.Anything, repository.UserFilter{Role: "employee", Deactivated: &deactivated}, 1, 10, "moderator").
+			Return([]models.User{user}, nil)
+		mockService.On("GetUser", mock.Anything, user.ID, "moderator").Return(user, nil)
+		mockService.On("ChangeRole", mock.Anything, user.ID, "moderator", testUserID, "moderator").Return(nil)
+		mockService.On("SetDeactivated", mock.Anything, user.ID, false, testUserID, "moderator").Return(nil)
+		mockService.On("SetDeactivated", mock.Anything, testUserID, true, testUserID, "moderator").Return(services.ErrOwnAccount)
+		mockService.On("DeleteUser", mock.Anything, user.ID, testUserID, "moderator").Return(repository.ErrUserNotFound)
+
+		w := serveAndValidate(t, specRouter, router, "GET", "/users?role=employee&status=deactivated", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = serveAndValidate(t, specRouter, router, "GET", "/users/"+user.ID.String(), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = serveAndValidate(t, specRouter, router, "PUT", "/users/"+user.ID.String()+"/role", map[string]string{"role": "moderator"})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/users/"+user.ID.String()+"/reactivate", nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = serveAndValidate(t, specRouter, router, "POST", "/users/"+testUserID.String()+"/deactivate", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = serveAndValidate(t, specRouter, router, "DELETE", "/users/"+user.ID.String(), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("webhooks", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+		handler := NewWebhookHandler(mockService)
+		router := gin.New()
+		router.GET("/webhooks", moderatorAuthMock(), handler.List)
+		router.GET("/webhooks/:subscriptionId/deliveries", moderatorAuthMock(), handler.Deliveries)
+		router.DELETE("/webhooks/:subscriptionId", moderatorAuthMock(), handler.Delete)
+
+		subscriptionID := uuid.New()
+		mockService.On("GetSubscriptions", mock.Anything, "moderator").Return([]models.WebhookSubscription{
+			{ID: subscriptionID, URL: "https://partner.example/hook", EventType: models.EventProductAdded, CreatedBy: testUserID, CreatedAt: now},
+		}, nil)
+		responseCode := 500
+		mockService.On("GetDeliveries", mock.Anything, subscriptionID, "moderator").Return([]models.WebhookDelivery{
+			{ID: uuid.New(), SubscriptionID: subscriptionID, EventID: uuid.New(), EventType: models.EventProductAdded,
+				Payload: json.RawMessage(`{"type":"обувь"}`), Status: models.DeliveryStatusPending, Attempts: 1,
+				ResponseCode: &responseCode, NextAttemptAt: now, CreatedAt: now},
+		}, nil)
+		mockService.On("DeleteSubscription", mock.Anything, subscriptionID, "moderator").Return(repository.ErrSubscriptionNotFound)
+
+		w := serveAndValidate(t, specRouter, router, "GET", "/webhooks", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = serveAndValidate(t, specRouter, router, "GET", "/webhooks/"+subscriptionID.String()+"/deliveries", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = serveAndValidate(t, specRouter, router, "DELETE", "/webhooks/"+subscriptionID.String(), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
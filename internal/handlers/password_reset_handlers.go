@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"pvz/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetHandler struct {
+	resetService services.PasswordResetServiceInterface
+}
+
+func NewPasswordResetHandler(resetService services.PasswordResetServiceInterface) *PasswordResetHandler {
+	return &PasswordResetHandler{resetService: resetService}
+}
+
+// Request answers 202 whether or not the email is registered.
+func (h *PasswordResetHandler) Request(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	if err := h.resetService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+func (h *PasswordResetHandler) Complete(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	if err := h.resetService.CompletePasswordReset(c.Request.Context(), req.Token, req.Password); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pvz/internal/repository"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPasswordResetService struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetService) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetService) CompletePasswordReset(ctx context.Context, token, password string) error {
+	args := m.Called(ctx, token, password)
+	return args.Error(0)
+}
+
+func TestPasswordResetHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(mockService *MockPasswordResetService) *gin.Engine {
+		handler := NewPasswordResetHandler(mockService)
+		router := gin.New()
+		router.POST("/password/reset-request", handler.Request)
+		router.POST("/password/reset", handler.Complete)
+		return router
+	}
+	post := func(router *gin.Engine, path string, body map[string]string) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("request is accepted", func(t *testing.T) {
+		mockService := new(MockPasswordResetService)
+		mockService.On("RequestPasswordReset", mock.Anything, "user@example.com").Return(nil)
+
+		w := post(newRouter(mockService), "/password/reset-request", map[string]string{"email": "user@example.com"})
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("request with invalid email", func(t *testing.T) {
+		w := post(newRouter(new(MockPasswordResetService)), "/password/reset-request", map[string]string{"email": "not-an-email"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		mockService := new(MockPasswordResetService)
+		mockService.On("CompletePasswordReset", mock.Anything, "token", "New-secret1").Return(nil)
+
+		w := post(newRouter(mockService), "/password/reset", map[string]string{"token": "token", "password": "New-secret1"})
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("complete with invalid token", func(t *testing.T) {
+		mockService := new(MockPasswordResetService)
+		mockService.On("CompletePasswordReset", mock.Anything, "expired", "New-secret1").Return(repository.ErrResetTokenNotFound)
+
+		w := post(newRouter(mockService), "/password/reset", map[string]string{"token": "expired", "password": "New-secret1"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invalid_reset_token"`)
+	})
+}
+
+func TestPasswordResetIsOffWithoutNotifier(t *testing.T) {
+	router := gin.New()
+	setupTestRoutes(router, nil)
+
+	for _, path := range []string{"/api/v1/password/reset-request", "/api/v1/password/reset"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewBufferString(`{"email":"user@example.com"}`)))
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+}
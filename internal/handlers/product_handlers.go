@@ -1,9 +1,7 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
-	"pvz/internal/repository"
 	"pvz/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -25,32 +23,37 @@ func (h *ProductHandler) Add(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		respondBadRequest(c, "Invalid request format: "+err.Error())
 		return
 	}
 
 	id, err := uuid.Parse(req.PVZID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	if err := checkPVZScope(c, id); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	role, err := getUserRole(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	product, err := h.productService.AddProduct(c.Request.Context(), req.ProductType, id, role)
+	product, err := h.productService.AddProduct(c.Request.Context(), req.ProductType, id, userID, role)
 
 	if err != nil {
-		if errors.Is(err, services.ErrAccessDenied) {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		} else if errors.Is(err, repository.ErrNoActiveReception) {
-			c.JSON(http.StatusBadRequest, err.Error())
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		respondError(c, err)
 		return
 	}
 
@@ -62,27 +65,26 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 
 	pvzId, err := uuid.Parse(pvzIdRaw)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	if err := checkPVZScope(c, pvzId); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	role, err := getUserRole(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	err = h.productService.DeleteProduct(c.Request.Context(), pvzId, role)
 	if err != nil {
-		if err == services.ErrAccessDenied {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		} else if err == repository.ErrPVZNotFound {
-			c.JSON(http.StatusBadRequest, err.Error())
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message:": "product deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "product deleted successfully"})
 }
@@ -21,8 +21,8 @@ type MockProductService struct {
 	mock.Mock
 }
 
-func (m *MockProductService) AddProduct(ctx context.Context, productType string, pvzID uuid.UUID, role string) (models.Product, error) {
-	args := m.Called(ctx, productType, pvzID, role)
+func (m *MockProductService) AddProduct(ctx context.Context, productType string, pvzID, userID uuid.UUID, role string) (models.Product, error) {
+	args := m.Called(ctx, productType, pvzID, userID, role)
 	return args.Get(0).(models.Product), args.Error(1)
 }
 
@@ -48,9 +48,10 @@ func TestProductHandler_Add(t *testing.T) {
 		expectedProduct := models.Product{
 			ProductType: validBody["type"],
 			ReceptionID: uuid.New(),
+			CreatedBy:   testUserID,
 		}
 
-		mockService.On("AddProduct", mock.Anything, "электроника", pvzID, "employee").Return(expectedProduct, nil)
+		mockService.On("AddProduct", mock.Anything, "электроника", pvzID, testUserID, "employee").Return(expectedProduct, nil)
 
 		jsonBody, _ := json.Marshal(validBody)
 		req := httptest.NewRequest("POST", "/products", bytes.NewBuffer(jsonBody))
@@ -64,6 +65,7 @@ func TestProductHandler_Add(t *testing.T) {
 		json.Unmarshal(w.Body.Bytes(), &response)
 		assert.Equal(t, expectedProduct.ProductType, response.ProductType)
 		assert.Equal(t, expectedProduct.ReceptionID, response.ReceptionID)
+		assert.Equal(t, testUserID, response.CreatedBy)
 		mockService.AssertExpectations(t)
 	})
 
@@ -86,7 +88,7 @@ func TestProductHandler_Add(t *testing.T) {
 
 	t.Run("no active reception for PVZ", func(t *testing.T) {
 		mockService.ExpectedCalls = []*mock.Call{}
-		mockService.On("AddProduct", mock.Anything, "электроника", pvzID, "employee").Return(models.Product{}, repository.ErrNoActiveReception)
+		mockService.On("AddProduct", mock.Anything, "электроника", pvzID, testUserID, "employee").Return(models.Product{}, repository.ErrNoActiveReception)
 
 		jsonBody, _ := json.Marshal(validBody)
 		req := httptest.NewRequest("POST", "/products", bytes.NewBuffer(jsonBody))
@@ -1,21 +1,22 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 	"pvz/internal/services"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type PVZHandler struct {
-	pvzService services.PVZServiceInterface
+	pvzService   services.PVZServiceInterface
+	defaultLimit int
 }
 
-func NewPVZHandler(pvzService services.PVZServiceInterface) *PVZHandler {
-	return &PVZHandler{pvzService: pvzService}
+func NewPVZHandler(pvzService services.PVZServiceInterface, defaultLimit int) *PVZHandler {
+	return &PVZHandler{pvzService: pvzService, defaultLimit: defaultLimit}
 }
 
 func (h *PVZHandler) CreatePVZ(c *gin.Context) {
@@ -23,28 +24,20 @@ func (h *PVZHandler) CreatePVZ(c *gin.Context) {
 		City string `json:"city" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		respondBadRequest(c, "Invalid request format: "+err.Error())
 		return
 	}
 
 	role, err := getUserRole(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	pvz, err := h.pvzService.CreatePVZ(c.Request.Context(), req.City, role)
 	if err != nil {
-		if errors.Is(err, services.ErrAccessDenied) {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-			return
-		} else if errors.Is(err, services.ErrCityNotAllowed) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+		respondError(c, err)
+		return
 	}
 
 	c.JSON(http.StatusCreated, pvz)
@@ -53,18 +46,20 @@ func (h *PVZHandler) CreatePVZ(c *gin.Context) {
 func (h *PVZHandler) GetPVZInfo(c *gin.Context) {
 	startDateStr := c.DefaultQuery("startDate", "")
 	endDateStr := c.DefaultQuery("endDate", "")
+	createdByStr := c.DefaultQuery("createdBy", "")
+	closedByStr := c.DefaultQuery("closedBy", "")
 	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
+	limitStr := c.DefaultQuery("limit", strconv.Itoa(h.defaultLimit))
 
 	page, err := strconv.Atoi(pageStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page is not int"})
+		respondBadRequest(c, "invalid page is not int")
 		return
 	}
 
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit is not int"})
+		respondBadRequest(c, "invalid limit is not int")
 		return
 	}
 
@@ -72,7 +67,7 @@ func (h *PVZHandler) GetPVZInfo(c *gin.Context) {
 	if startDateStr != "" {
 		parsedStartDate, err := time.Parse(time.RFC3339, startDateStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid startDate format"})
+			respondBadRequest(c, "invalid startDate format")
 			return
 		}
 		startDate = &parsedStartDate
@@ -81,27 +76,40 @@ func (h *PVZHandler) GetPVZInfo(c *gin.Context) {
 	if endDateStr != "" {
 		parsedEndDate, err := time.Parse(time.RFC3339, endDateStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid endDate format"})
+			respondBadRequest(c, "invalid endDate format")
 			return
 		}
 		endDate = &parsedEndDate
 	}
+
+	var createdBy, closedBy *uuid.UUID
+	if createdByStr != "" {
+		parsedCreatedBy, err := uuid.Parse(createdByStr)
+		if err != nil {
+			respondBadRequest(c, "invalid createdBy format")
+			return
+		}
+		createdBy = &parsedCreatedBy
+	}
+
+	if closedByStr != "" {
+		parsedClosedBy, err := uuid.Parse(closedByStr)
+		if err != nil {
+			respondBadRequest(c, "invalid closedBy format")
+			return
+		}
+		closedBy = &parsedClosedBy
+	}
+
 	role, err := getUserRole(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	pvzs, err := h.pvzService.GetPVZList(c.Request.Context(), startDate, endDate, page, limit, role)
+	pvzs, err := h.pvzService.GetPVZList(c.Request.Context(), startDate, endDate, createdBy, closedBy, page, limit, role)
 	if err != nil {
-		switch err {
-		case services.ErrAccessDenied:
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		case services.ErrPageParamIsInvalid, services.ErrLimitParamIsInvalid, services.ErrStartLaterThenEnd:
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		respondError(c, err)
 		return
 	}
 
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"net/http"
-	"pvz/internal/repository"
 	"pvz/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -23,32 +22,37 @@ func (h *ReceptionHandler) Create(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		respondBadRequest(c, "Invalid request format: "+err.Error())
 		return
 	}
 
 	id, err := uuid.Parse(req.PVZID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	if err := checkPVZScope(c, id); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	role, err := getUserRole(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	reception, err := h.receptionService.CreateReception(c.Request.Context(), id, role)
+	reception, err := h.receptionService.CreateReception(c.Request.Context(), id, userID, role)
 
 	if err != nil {
-		if err == services.ErrAccessDenied {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		} else if err == repository.ErrActiveReceptionExists || err == repository.ErrPVZNotFound {
-			c.JSON(http.StatusBadRequest, err.Error())
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		respondError(c, err)
 		return
 	}
 
@@ -60,26 +64,31 @@ func (h *ReceptionHandler) Close(c *gin.Context) {
 
 	pvzId, err := uuid.Parse(pvzIdRaw)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	if err := checkPVZScope(c, pvzId); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	role, err := getUserRole(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	reception, err := h.receptionService.CloseReception(c.Request.Context(), pvzId, role)
+	reception, err := h.receptionService.CloseReception(c.Request.Context(), pvzId, userID, role)
 
 	if err != nil {
-		if err == services.ErrAccessDenied {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		} else if err == repository.ErrNoActiveReception {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		respondError(c, err)
 		return
 	}
 
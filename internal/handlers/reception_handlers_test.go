@@ -22,13 +22,18 @@ type MockReceptionService struct {
 	mock.Mock
 }
 
-func (m *MockReceptionService) CreateReception(ctx context.Context, pvzID uuid.UUID, role string) (models.Reception, error) {
-	args := m.Called(ctx, pvzID, role)
+func (m *MockReceptionService) CreateReception(ctx context.Context, pvzID, userID uuid.UUID, role string) (models.Reception, error) {
+	args := m.Called(ctx, pvzID, userID, role)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
-func (m *MockReceptionService) CloseReception(ctx context.Context, pvzID uuid.UUID, role string) (models.Reception, error) {
-	args := m.Called(ctx, pvzID, role)
+func (m *MockReceptionService) CloseReception(ctx context.Context, pvzID, userID uuid.UUID, role string) (models.Reception, error) {
+	args := m.Called(ctx, pvzID, userID, role)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
+func (m *MockReceptionService) ForceCloseReception(ctx context.Context, pvzID, closedBy uuid.UUID) (models.Reception, error) {
+	args := m.Called(ctx, pvzID, closedBy)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
@@ -44,13 +49,14 @@ func TestReceptionHandler_Create(t *testing.T) {
 
 	t.Run("successful reception create", func(t *testing.T) {
 		expectedReception := models.Reception{
-			ID:       uuid.New(),
-			DateTime: time.Now(),
-			PVZID:    pvzID,
-			Status:   models.ReceptionStatusInProgress,
+			ID:        uuid.New(),
+			DateTime:  time.Now(),
+			PVZID:     pvzID,
+			Status:    models.ReceptionStatusInProgress,
+			CreatedBy: testUserID,
 		}
 
-		mockService.On("CreateReception", mock.Anything, pvzID, "employee").Return(expectedReception, nil)
+		mockService.On("CreateReception", mock.Anything, pvzID, testUserID, "employee").Return(expectedReception, nil)
 
 		jsonBody, _ := json.Marshal(validBody)
 		req := httptest.NewRequest("POST", "/receptions", bytes.NewBuffer(jsonBody))
@@ -65,6 +71,7 @@ func TestReceptionHandler_Create(t *testing.T) {
 		assert.NotEmpty(t, response.DateTime)
 		assert.Equal(t, expectedReception.PVZID, response.PVZID)
 		assert.Equal(t, expectedReception.Status, response.Status)
+		assert.Equal(t, testUserID, response.CreatedBy)
 		mockService.AssertExpectations(t)
 	})
 
@@ -84,7 +91,7 @@ func TestReceptionHandler_Create(t *testing.T) {
 
 	t.Run("active reception already exists", func(t *testing.T) {
 		mockService.ExpectedCalls = []*mock.Call{}
-		mockService.On("CreateReception", mock.Anything, pvzID, "employee").Return(models.Reception{}, repository.ErrActiveReceptionExists)
+		mockService.On("CreateReception", mock.Anything, pvzID, testUserID, "employee").Return(models.Reception{}, repository.ErrActiveReceptionExists)
 
 		jsonBody, _ := json.Marshal(validBody)
 		req := httptest.NewRequest("POST", "/receptions", bytes.NewBuffer(jsonBody))
@@ -97,6 +104,23 @@ func TestReceptionHandler_Create(t *testing.T) {
 		assert.Contains(t, w.Body.String(), repository.ErrActiveReceptionExists.Error())
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("PVZ outside the API key scope", func(t *testing.T) {
+		scoped := gin.New()
+		scoped.POST("/receptions", jwtAuthMock(), func(c *gin.Context) {
+			c.Set("pvz_scope", []uuid.UUID{uuid.New()})
+		}, handler.Create)
+
+		jsonBody, _ := json.Marshal(validBody)
+		req := httptest.NewRequest("POST", "/receptions", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		scoped.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"access_denied"`)
+	})
 }
 
 func TestReceptionHandler_Close(t *testing.T) {
@@ -117,7 +141,7 @@ func TestReceptionHandler_Close(t *testing.T) {
 			Status:   models.ReceptionStatusInProgress,
 		}
 
-		mockService.On("CloseReception", mock.Anything, pvzID, "employee").Return(expectedReception, nil)
+		mockService.On("CloseReception", mock.Anything, pvzID, testUserID, "employee").Return(expectedReception, nil)
 
 		req := httptest.NewRequest("PUT", validPath, nil)
 		w := httptest.NewRecorder()
@@ -146,7 +170,7 @@ func TestReceptionHandler_Close(t *testing.T) {
 
 	t.Run("no active reception", func(t *testing.T) {
 		mockService.ExpectedCalls = []*mock.Call{}
-		mockService.On("CloseReception", mock.Anything, pvzID, "employee").Return(models.Reception{}, repository.ErrNoActiveReception)
+		mockService.On("CloseReception", mock.Anything, pvzID, testUserID, "employee").Return(models.Reception{}, repository.ErrNoActiveReception)
 
 		req := httptest.NewRequest("PUT", validPath, nil)
 		w := httptest.NewRecorder()
@@ -159,8 +183,11 @@ func TestReceptionHandler_Close(t *testing.T) {
 	})
 }
 
+var testUserID = uuid.New()
+
 func jwtAuthMock() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Set("user_id", testUserID)
 		c.Set("role", "employee")
 		c.Next()
 	}
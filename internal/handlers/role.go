@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"fmt"
+	"pvz/internal/services"
+	"slices"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 func getUserRole(c *gin.Context) (string, error) {
@@ -21,3 +24,45 @@ func getUserRole(c *gin.Context) (string, error) {
 
 	return role, nil
 }
+
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	const userIDKey = "user_id"
+
+	userIDValue, exists := c.Get(userIDKey)
+	if !exists {
+		return uuid.Nil, fmt.Errorf("user id not found in context")
+	}
+
+	userID, ok := userIDValue.(uuid.UUID)
+	if !ok {
+		return uuid.Nil, fmt.Errorf("invalid user id type: expected uuid.UUID, got %T", userIDValue)
+	}
+
+	return userID, nil
+}
+
+// getCaller returns the id and role of the authenticated user.
+func getCaller(c *gin.Context) (uuid.UUID, string, error) {
+	userID, err := getUserID(c)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	role, err := getUserRole(c)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	return userID, role, nil
+}
+
+// checkPVZScope refuses PVZs outside the ones an API key is limited to. Users and unlimited keys
+// have no "pvz_scope" and may act at any PVZ their role allows.
+func checkPVZScope(c *gin.Context, pvzID uuid.UUID) error {
+	scope, exists := c.Get("pvz_scope")
+	if !exists {
+		return nil
+	}
+	if pvzIDs, ok := scope.([]uuid.UUID); ok && slices.Contains(pvzIDs, pvzID) {
+		return nil
+	}
+	return services.ErrAccessDenied
+}
@@ -2,40 +2,192 @@ package handlers
 
 import (
 	"database/sql"
+	"net/http"
+	"pvz/internal/config"
+	"pvz/internal/metrics"
 	"pvz/internal/middleware"
+	"pvz/internal/models"
+	"pvz/internal/notify"
+	"pvz/internal/ratelimit"
 	"pvz/internal/repository"
 	"pvz/internal/services"
+	"pvz/internal/tracing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func SetupRoutes(db *sql.DB, r *gin.Engine) {
-	middleware.InitSecretKey()
+const APIV1Prefix = "/api/v1"
+
+// Root aliases of the v1 routes are announced as deprecated and removed after the sunset date.
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// SetupRoutes registers the HTTP API. The user service and the rate limit store are shared with the gRPC
+// server, so both APIs check tokens the same way and count logins against one budget.
+func SetupRoutes(db *sql.DB, r *gin.Engine, cfg *config.Config, tokens *services.TokenManager, m *metrics.Metrics,
+	notifier notify.NotifierInterface, userService *services.UserService, limits ratelimit.Store) {
+	r.Use(
+		otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(tracedRequest)),
+		middleware.RequestLogger(),
+		m.Middleware(),
+		middleware.Recovery(),
+	)
+
 	userRepo := repository.NewUserRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
 	pvzRepo := repository.NewPWZRepository(db)
 	receptionRepo := repository.NewReceptionRepository(db)
 	productRepo := repository.NewProductRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+
+	passwordPolicy := services.PasswordPolicy{MinLength: cfg.Password.MinLength, RequiredClasses: cfg.Password.RequiredClasses}
+	invitationService := services.NewInvitationService(invitationRepo, cfg.Auth.InvitationTTL)
+	pvzService := services.NewPVZService(pvzRepo, cfg.Limits.PVZListMax, m)
+	receptionService := services.NewReceptionService(receptionRepo, m)
+	productService := services.NewProductService(productRepo, m)
+	webhookService := services.NewWebhookService(webhookRepo)
+	eventService := services.NewEventService(outboxRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, cfg.Auth.APIKeyRotationGrace)
+
+	h := &v1Handlers{
+		user:       NewUserHandler(userService, cfg.Limits.UserListDefault),
+		invitation: NewInvitationHandler(invitationService),
+		pvz:        NewPVZHandler(pvzService, cfg.Limits.PVZListDefault),
+		reception:  NewReceptionHandler(receptionService),
+		product:    NewProductHandler(productService),
+		webhook:    NewWebhookHandler(webhookService),
+		event:      NewEventHandler(eventService, time.Second),
+		apiKey:     NewAPIKeyHandler(apiKeyService),
+
+		auth: middleware.JWTMiddleware(tokens, userService),
+		authOrAPIKey: func(permission string) gin.HandlerFunc {
+			return middleware.JWTOrAPIKeyMiddleware(tokens, userService, apiKeyService, permission)
+		},
+		optionalAuth: middleware.OptionalJWTMiddleware(tokens, userService),
+		idempotency:  middleware.Idempotency(idempotencyRepo, cfg.Idempotency.TTL, cfg.Idempotency.LockTTL),
+		authLimit:    rateLimit(limits, "auth", cfg.RateLimit.Auth),
+		readLimit:    rateLimit(limits, "read", cfg.RateLimit.Read),
+		writeLimit:   rateLimit(limits, "write", cfg.RateLimit.Write),
+	}
+
+	// a reset token has to reach the user somehow; without a notifier password reset is off
+	if notifier != nil {
+		h.password = NewPasswordResetHandler(services.NewPasswordResetService(userRepo, passwordResetRepo, notifier, passwordPolicy, cfg.Password.ResetTTL))
+	}
+
+	health := NewHealthHandler(db, cfg.Health.ReadinessTimeout)
+
+	// service endpoints stay outside the versioned API and never require a token
+	r.GET("/healthz", health.Liveness)
+	r.GET("/readyz", health.Readiness)
+	r.GET("/version", VersionHandler)
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+	r.GET("/openapi.json", OpenAPIHandler)
+	r.GET("/docs", SwaggerUIHandler)
+	r.GET("/.well-known/jwks.json", JWKSHandler(tokens))
+
+	registerV1Routes(r.Group(APIV1Prefix), h)
+	// the unversioned paths the API was first published with
+	registerV1Routes(r.Group("/", middleware.Deprecated(legacyDeprecatedAt, legacySunsetAt, APIV1Prefix)), h)
+}
+
+// untracedPaths are polled by probes and scrapers; tracing them would only bury the API traces.
+var untracedPaths = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+func tracedRequest(r *http.Request) bool {
+	return !untracedPaths[r.URL.Path]
+}
+
+func rateLimit(store ratelimit.Store, group string, rate config.Rate) gin.HandlerFunc {
+	if rate.Unlimited() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.RateLimit(store, group, ratelimit.Limit{Requests: rate.Requests, Period: rate.Period})
+}
+
+// v1Handlers are the handlers behind /api/v1. A future /api/v2 gets its own handler set and
+// register function on top of the same services, so v1 response shapes stay frozen.
+type v1Handlers struct {
+	user       *UserHandler
+	password   *PasswordResetHandler
+	invitation *InvitationHandler
+	pvz        *PVZHandler
+	reception  *ReceptionHandler
+	product    *ProductHandler
+	webhook    *WebhookHandler
+	event      *EventHandler
+	apiKey     *APIKeyHandler
+
+	auth gin.HandlerFunc
+	// authOrAPIKey also accepts API keys that have the permission, for the routes integrations call.
+	authOrAPIKey func(permission string) gin.HandlerFunc
+	optionalAuth gin.HandlerFunc
+	idempotency  gin.HandlerFunc
+	authLimit    gin.HandlerFunc
+	readLimit    gin.HandlerFunc
+	writeLimit   gin.HandlerFunc
+}
+
+func registerV1Routes(g *gin.RouterGroup, h *v1Handlers) {
+	g.GET("/dictionaries", h.readLimit, DictionaryHandler)
+
+	// no idempotency here: anonymous callers have no key namespace of their own, and the responses
+	// carry tokens that must not be stored
+	public := g.Group("", h.authLimit)
+	public.POST("/dummyLogin", h.user.DummyLogin)
+	public.POST("/register", h.optionalAuth, h.user.Register)
+	public.POST("/login", h.user.Login)
+	if h.password != nil {
+		public.POST("/password/reset-request", h.password.Request)
+		public.POST("/password/reset", h.password.Complete)
+	}
 
-	userService := services.NewUserService(userRepo)
-	pvzService := services.NewPVZService(pvzRepo)
-	receptionService := services.NewReceptionService(receptionRepo)
-	productService := services.NewProductService(productRepo)
+	reads := g.Group("", h.auth, h.readLimit)
+	reads.GET("/me", h.user.Me)
+	reads.GET("/pvz", h.pvz.GetPVZInfo)
+	reads.GET("/pvz/:pvzId/events", h.event.Stream)
+	reads.GET("/webhooks", h.webhook.List)
+	reads.GET("/webhooks/:subscriptionId/deliveries", h.webhook.Deliveries)
+	reads.GET("/users", h.user.List)
+	reads.GET("/users/:userId", h.user.Get)
+	reads.GET("/users/:userId/login-history", h.user.LoginHistory)
+	reads.GET("/invitations", h.invitation.List)
+	reads.GET("/api-keys", h.apiKey.List)
 
-	userHandler := NewUserHandler(userService)
-	PVZHandler := NewPVZHandler(pvzService)
-	receptionHandler := NewReceptionHandler(receptionService)
-	productHandler := NewProductHandler(productService)
+	// limits run before idempotency, so rejected requests are never stored for replay
+	writes := g.Group("", h.auth, h.writeLimit, h.idempotency)
+	writes.POST("/pvz", h.pvz.CreatePVZ)
+	writes.POST("/webhooks", h.webhook.Create)
+	writes.DELETE("/webhooks/:subscriptionId", h.webhook.Delete)
+	writes.POST("/webhooks/deliveries/:deliveryId/redeliver", h.webhook.Redeliver)
+	writes.PUT("/users/:userId/role", h.user.ChangeRole)
+	writes.POST("/users/:userId/deactivate", h.user.Deactivate)
+	writes.POST("/users/:userId/reactivate", h.user.Reactivate)
+	writes.DELETE("/users/:userId", h.user.Delete)
+	writes.POST("/users/:userId/unlock", h.user.Unlock)
+	writes.POST("/me/password", h.user.ChangePassword)
+	writes.DELETE("/invitations/:invitationId", h.invitation.Delete)
+	writes.DELETE("/api-keys/:apiKeyId", h.apiKey.Revoke)
 
-	r.POST("/dummyLogin", DummyLoginHandler)
-	r.POST("/register", userHandler.Register)
-	r.POST("/login", userHandler.Login)
+	// these responses carry secrets that are stored only hashed, so they are never kept for replay
+	secrets := g.Group("", h.auth, h.writeLimit)
+	secrets.POST("/invitations", h.invitation.Create)
+	secrets.POST("/api-keys", h.apiKey.Create)
+	secrets.POST("/api-keys/:apiKeyId/rotate", h.apiKey.Rotate)
 
-	r.Use(middleware.JWTMiddleware())
+	receptions := g.Group("", h.authOrAPIKey(models.PermissionReceptionsWrite), h.writeLimit, h.idempotency)
+	receptions.POST("/reception", h.reception.Create)
+	receptions.PUT("/pvz/:pvzId/close_last_reception", h.reception.Close)
 
-	r.POST("/pvz", PVZHandler.CreatePVZ)
-	r.GET("/pvz", PVZHandler.GetPVZInfo)
-	r.PUT("/pvz/:pvzId/close_last_reception", receptionHandler.Close)
-	r.DELETE("/pvz/:pvzId/delete_last_product", productHandler.Delete)
-	r.POST("/reception", receptionHandler.Create)
-	r.POST("/products", productHandler.Add)
+	products := g.Group("", h.authOrAPIKey(models.PermissionProductsWrite), h.writeLimit, h.idempotency)
+	products.POST("/products", h.product.Add)
+	products.DELETE("/pvz/:pvzId/delete_last_product", h.product.Delete)
 }
@@ -2,38 +2,42 @@ package handlers
 
 import (
 	"net/http"
+	"pvz/internal/repository"
 	"pvz/internal/services"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type UserHandler struct {
-	userService services.UserServiceInterface
+	userService  services.UserServiceInterface
+	defaultLimit int
 }
 
-func NewUserHandler(userService services.UserServiceInterface) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService services.UserServiceInterface, defaultLimit int) *UserHandler {
+	return &UserHandler{userService: userService, defaultLimit: defaultLimit}
 }
 
+// Register creates an account. The token is optional here: a moderator's token, like an invitation
+// code, allows registering another moderator.
 func (u *UserHandler) Register(c *gin.Context) {
 	var req struct {
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required"`
-		Role     string `json:"role" binding:"required,oneof=employee moderator"`
+		Email          string `json:"email" binding:"required,email"`
+		Password       string `json:"password" binding:"required"`
+		Role           string `json:"role" binding:"required,oneof=employee moderator"`
+		InvitationCode string `json:"invitationCode"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		respondBadRequest(c, "Invalid request format: "+err.Error())
 		return
 	}
 
-	user, err := u.userService.RegisterUser(c.Request.Context(), req.Email, req.Password, req.Role)
+	auth := services.RegistrationAuth{CallerRole: c.GetString("role"), InvitationCode: req.InvitationCode}
+	user, err := u.userService.RegisterUser(c.Request.Context(), req.Email, req.Password, req.Role, auth)
 	if err != nil {
-		if err == ErrUserExists {
-			c.JSON(http.StatusBadRequest, gin.H{"error: ": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error: ": err.Error()})
-		}
+		respondError(c, err)
 		return
 	}
 
@@ -47,37 +51,264 @@ func (u *UserHandler) Login(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		respondBadRequest(c, "Invalid request format: "+err.Error())
 		return
 	}
 
-	token, err := u.userService.LoginUser(c.Request.Context(), req.Email, req.Password)
+	client := services.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	token, err := u.userService.LoginUser(c.Request.Context(), req.Email, req.Password, client)
 	if err != nil {
-		if err == ErrWrongPassword || err == ErrUserDoesntExist {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"token": token})
 }
 
-func DummyLoginHandler(c *gin.Context) {
+func (u *UserHandler) DummyLogin(c *gin.Context) {
 	var req struct {
 		Role string `json:"role" binding:"required,oneof=employee moderator"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		respondBadRequest(c, "Invalid request format: "+err.Error())
 		return
 	}
 
-	token, err := services.DummyLogin(req.Role)
+	token, err := u.userService.DummyLogin(req.Role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"token": token})
 }
+
+// ChangePassword sets a new password for the authenticated user, who must give the current one.
+func (u *UserHandler) ChangePassword(c *gin.Context) {
+	var req struct {
+		CurrentPassword string `json:"currentPassword" binding:"required"`
+		NewPassword     string `json:"newPassword" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := u.userService.ChangePassword(c.Request.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Me describes the authenticated user, so clients need not decode the token to learn their role.
+func (u *UserHandler) Me(c *gin.Context) {
+	userID, role, err := getCaller(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	profile, err := u.userService.GetProfile(c.Request.Context(), userID, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// Unlock lifts the lockout of an account after too many wrong passwords.
+func (u *UserHandler) Unlock(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := u.userService.UnlockUser(c.Request.Context(), id, role); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (u *UserHandler) LoginHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	attempts, err := u.userService.GetLoginHistory(c.Request.Context(), id, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attempts)
+}
+
+// List returns the users that match the role, email and status filters, a page at a time.
+func (u *UserHandler) List(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		respondBadRequest(c, "invalid page is not int")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(u.defaultLimit)))
+	if err != nil {
+		respondBadRequest(c, "invalid limit is not int")
+		return
+	}
+
+	filter := repository.UserFilter{Role: c.Query("role"), Email: c.Query("email")}
+	switch status := c.Query("status"); status {
+	case "":
+	case "active", "deactivated":
+		deactivated := status == "deactivated"
+		filter.Deactivated = &deactivated
+	default:
+		respondBadRequest(c, "invalid status, expected active or deactivated")
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	users, err := u.userService.GetUsers(c.Request.Context(), filter, page, limit, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func (u *UserHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	user, err := u.userService.GetUser(c.Request.Context(), id, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangeRole gives a user another role; the user has to log in again to get it in a token.
+func (u *UserHandler) ChangeRole(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required,oneof=employee moderator"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	userID, role, err := getCaller(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := u.userService.ChangeRole(c.Request.Context(), id, req.Role, userID, role); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Deactivate refuses the user's logins and tokens until the account is reactivated.
+func (u *UserHandler) Deactivate(c *gin.Context) {
+	u.setDeactivated(c, true)
+}
+
+func (u *UserHandler) Reactivate(c *gin.Context) {
+	u.setDeactivated(c, false)
+}
+
+func (u *UserHandler) setDeactivated(c *gin.Context, deactivated bool) {
+	id, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	userID, role, err := getCaller(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := u.userService.SetDeactivated(c.Request.Context(), id, deactivated, userID, role); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (u *UserHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	userID, role, err := getCaller(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := u.userService.DeleteUser(c.Request.Context(), id, userID, role); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -6,11 +6,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -19,19 +22,74 @@ type MockUserService struct {
 	mock.Mock
 }
 
-func (m *MockUserService) RegisterUser(ctx context.Context, email, password, role string) (models.User, error) {
-	args := m.Called(ctx, email, password, role)
+func (m *MockUserService) RegisterUser(ctx context.Context, email, password, role string, auth services.RegistrationAuth) (models.User, error) {
+	args := m.Called(ctx, email, password, role, auth)
 	return args.Get(0).(models.User), args.Error(1)
 }
 
-func (m *MockUserService) LoginUser(ctx context.Context, email, password string) (string, error) {
+func (m *MockUserService) DummyLogin(role string) (string, error) {
+	args := m.Called(role)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserService) ResetPassword(ctx context.Context, email, password string) error {
 	args := m.Called(ctx, email, password)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ChangePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error {
+	args := m.Called(ctx, id, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) LoginUser(ctx context.Context, email, password string, client services.ClientInfo) (string, error) {
+	args := m.Called(ctx, email, password, client)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockUserService) UnlockUser(ctx context.Context, id uuid.UUID, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetLoginHistory(ctx context.Context, id uuid.UUID, role string) ([]models.LoginAttempt, error) {
+	args := m.Called(ctx, id, role)
+	return args.Get(0).([]models.LoginAttempt), args.Error(1)
+}
+
+func (m *MockUserService) GetUsers(ctx context.Context, filter repository.UserFilter, page, limit int, role string) ([]models.User, error) {
+	args := m.Called(ctx, filter, page, limit, role)
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockUserService) GetUser(ctx context.Context, id uuid.UUID, role string) (models.User, error) {
+	args := m.Called(ctx, id, role)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockUserService) ChangeRole(ctx context.Context, id uuid.UUID, newRole string, userID uuid.UUID, role string) error {
+	args := m.Called(ctx, id, newRole, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockUserService) SetDeactivated(ctx context.Context, id uuid.UUID, deactivated bool, userID uuid.UUID, role string) error {
+	args := m.Called(ctx, id, deactivated, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockUserService) DeleteUser(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) error {
+	args := m.Called(ctx, id, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetProfile(ctx context.Context, userID uuid.UUID, role string) (models.Profile, error) {
+	args := m.Called(ctx, userID, role)
+	return args.Get(0).(models.Profile), args.Error(1)
+}
+
 func TestRegisterHandler(t *testing.T) {
 	mockService := new(MockUserService)
-	handler := NewUserHandler(mockService)
+	handler := NewUserHandler(mockService, 10)
 
 	router := gin.Default()
 	router.POST("/register", handler.Register)
@@ -41,7 +99,7 @@ func TestRegisterHandler(t *testing.T) {
 			Email: "test@example.com",
 			Role:  "employee",
 		}
-		mockService.On("RegisterUser", mock.Anything, "test@example.com", "password123", "employee").
+		mockService.On("RegisterUser", mock.Anything, "test@example.com", "password123", "employee", services.RegistrationAuth{}).
 			Return(expectedUser, nil)
 
 		body := map[string]string{
@@ -83,7 +141,7 @@ func TestRegisterHandler(t *testing.T) {
 
 	t.Run("user already exists", func(t *testing.T) {
 		mockService.ExpectedCalls = []*mock.Call{}
-		mockService.On("RegisterUser", mock.Anything, "exists@example.com", "password123", "employee").
+		mockService.On("RegisterUser", mock.Anything, "exists@example.com", "password123", "employee", services.RegistrationAuth{}).
 			Return(models.User{}, ErrUserExists)
 
 		body := map[string]string{
@@ -102,17 +160,50 @@ func TestRegisterHandler(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 		assert.Contains(t, w.Body.String(), ErrUserExists.Error())
 	})
+
+	t.Run("moderator with an invitation code", func(t *testing.T) {
+		mockService.On("RegisterUser", mock.Anything, "invited@example.com", "password123", "moderator",
+			services.RegistrationAuth{InvitationCode: "used"}).Return(models.User{}, repository.ErrInvalidInvitation)
+
+		jsonBody, _ := json.Marshal(map[string]string{
+			"email": "invited@example.com", "password": "password123", "role": "moderator", "invitationCode": "used",
+		})
+		req := httptest.NewRequest("POST", "/register", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invalid_invitation"`)
+	})
+
+	t.Run("moderator registered by a moderator", func(t *testing.T) {
+		moderatorRouter := gin.New()
+		moderatorRouter.POST("/register", moderatorAuthMock(), handler.Register)
+		mockService.On("RegisterUser", mock.Anything, "new@example.com", "password123", "moderator",
+			services.RegistrationAuth{CallerRole: "moderator"}).Return(models.User{Email: "new@example.com", Role: "moderator"}, nil)
+
+		jsonBody, _ := json.Marshal(map[string]string{"email": "new@example.com", "password": "password123", "role": "moderator"})
+		req := httptest.NewRequest("POST", "/register", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		moderatorRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
 }
 
 func TestLoginHandler(t *testing.T) {
 	mockService := new(MockUserService)
-	handler := NewUserHandler(mockService)
+	handler := NewUserHandler(mockService, 10)
 
 	router := gin.Default()
 	router.POST("/login", handler.Login)
 
 	t.Run("successful login", func(t *testing.T) {
-		mockService.On("LoginUser", mock.Anything, "test@example.com", "password123").
+		mockService.On("LoginUser", mock.Anything, "test@example.com", "password123", mock.Anything).
 			Return("fake-jwt-token", nil)
 
 		body := map[string]string{
@@ -133,7 +224,7 @@ func TestLoginHandler(t *testing.T) {
 	})
 
 	t.Run("wrong password", func(t *testing.T) {
-		mockService.On("LoginUser", mock.Anything, "test@example.com", "wrong").
+		mockService.On("LoginUser", mock.Anything, "test@example.com", "wrong", mock.Anything).
 			Return("", ErrWrongPassword)
 
 		body := map[string]string{
@@ -154,7 +245,7 @@ func TestLoginHandler(t *testing.T) {
 	})
 
 	t.Run("User not found", func(t *testing.T) {
-		mockService.On("LoginUser", mock.Anything, "nonexistent@example.com", "password123").
+		mockService.On("LoginUser", mock.Anything, "nonexistent@example.com", "password123", mock.Anything).
 			Return("", ErrUserDoesntExist)
 
 		body := map[string]string{
@@ -173,17 +264,208 @@ func TestLoginHandler(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "Invalid email or password")
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("locked account looks like wrong password", func(t *testing.T) {
+		client := services.ClientInfo{IP: "192.0.2.10", UserAgent: "pvz-app/1.0"}
+		mockService.On("LoginUser", mock.Anything, "locked@example.com", "password123", client).
+			Return("", services.ErrAccountLocked)
+
+		jsonBody, _ := json.Marshal(map[string]string{"email": "locked@example.com", "password": "password123"})
+		req := httptest.NewRequest("POST", "/login", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "pvz-app/1.0")
+		req.RemoteAddr = "192.0.2.10:4321"
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid email or password")
+		assert.NotContains(t, w.Body.String(), "lock")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("deactivated account", func(t *testing.T) {
+		mockService.On("LoginUser", mock.Anything, "gone@example.com", "password123", mock.Anything).
+			Return("", services.ErrAccountDeactivated)
+
+		jsonBody, _ := json.Marshal(map[string]string{"email": "gone@example.com", "password": "password123"})
+		req := httptest.NewRequest("POST", "/login", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"account_deactivated"`)
+	})
+}
+
+func TestUnlockHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := uuid.New()
+
+	newRouter := func(mockService *MockUserService) *gin.Engine {
+		router := gin.New()
+		router.POST("/users/:userId/unlock", moderatorAuthMock(), NewUserHandler(mockService, 10).Unlock)
+		return router
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("UnlockUser", mock.Anything, id, "moderator").Return(nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("POST", "/users/"+id.String()+"/unlock", nil))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("UnlockUser", mock.Anything, id, "moderator").Return(repository.ErrUserNotFound)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("POST", "/users/"+id.String()+"/unlock", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"user_not_found"`)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newRouter(new(MockUserService)).ServeHTTP(w, httptest.NewRequest("POST", "/users/not-a-uuid/unlock", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(mockService *MockUserService) *gin.Engine {
+		router := gin.New()
+		router.POST("/me/password", jwtAuthMock(), NewUserHandler(mockService, 10).ChangePassword)
+		return router
+	}
+	changePassword := func(router *gin.Engine, current, next string) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(map[string]string{"currentPassword": current, "newPassword": next})
+		req := httptest.NewRequest("POST", "/me/password", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("ChangePassword", mock.Anything, testUserID, "Old-secret1", "New-secret1").Return(nil)
+
+		w := changePassword(newRouter(mockService), "Old-secret1", "New-secret1")
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("ChangePassword", mock.Anything, testUserID, "wrong", "New-secret1").Return(services.ErrWrongCurrentPassword)
+
+		w := changePassword(newRouter(mockService), "wrong", "New-secret1")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"wrong_current_password"`)
+	})
+
+	t.Run("weak password lists violations", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("ChangePassword", mock.Anything, testUserID, "Old-secret1", "short").Return(&services.PasswordPolicyError{
+			Violations: []string{services.PasswordTooShort, services.PasswordMissingUpper},
+		})
+
+		w := changePassword(newRouter(mockService), "Old-secret1", "short")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var got struct {
+			Code       string   `json:"code"`
+			Violations []string `json:"violations"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, "weak_password", got.Code)
+		assert.Equal(t, []string{"too_short", "missing_upper"}, got.Violations)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		w := changePassword(newRouter(new(MockUserService)), "", "New-secret1")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestMeHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	pvzID := uuid.New()
+	mockService := new(MockUserService)
+	router := gin.New()
+	router.GET("/me", jwtAuthMock(), NewUserHandler(mockService, 10).Me)
+
+	mockService.On("GetProfile", mock.Anything, testUserID, "employee").Return(models.Profile{
+		ID: testUserID, Email: "user@example.com", Role: "employee", AssignedPVZs: []uuid.UUID{pvzID},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/me", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"`+testUserID.String()+`","email":"user@example.com","role":"employee","assignedPvzs":["`+pvzID.String()+`"]}`,
+		w.Body.String())
+}
+
+func TestLoginHistoryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := uuid.New()
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService, 10)
+	router := gin.New()
+	router.GET("/moderator/users/:userId/login-history", moderatorAuthMock(), handler.LoginHistory)
+	router.GET("/employee/users/:userId/login-history", jwtAuthMock(), handler.LoginHistory)
+
+	attempts := []models.LoginAttempt{{
+		ID:            uuid.New(),
+		UserID:        &id,
+		Email:         "user@example.com",
+		IP:            "192.0.2.10",
+		FailureReason: models.LoginFailureWrongPassword,
+		CreatedAt:     time.Now(),
+	}}
+	mockService.On("GetLoginHistory", mock.Anything, id, "moderator").Return(attempts, nil)
+	mockService.On("GetLoginHistory", mock.Anything, id, "employee").Return([]models.LoginAttempt(nil), services.ErrAccessDenied)
+
+	t.Run("moderator", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/moderator/users/"+id.String()+"/login-history", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var got []models.LoginAttempt
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Len(t, got, 1)
+		assert.Equal(t, models.LoginFailureWrongPassword, got[0].FailureReason)
+	})
+
+	t.Run("employee", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/employee/users/"+id.String()+"/login-history", nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
 }
 
 func TestDummyLoginHandler(t *testing.T) {
-	originalSecret := os.Getenv("JWT_SECRET")
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer func() {
-		os.Setenv("JWT_SECRET", originalSecret)
-	}()
+	handler := NewUserHandler(services.NewUserService(nil, nil, services.NewTokenManager("test-secret", time.Hour, "pvz-service", "pvz-api"), services.Lockout{}, services.PasswordPolicy{}, 30, true), 10)
 
 	router := gin.Default()
-	router.POST("/dummyLogin", DummyLoginHandler)
+	router.POST("/dummyLogin", handler.DummyLogin)
 
 	t.Run("Успешный Dummy-логин для employee", func(t *testing.T) {
 		body := map[string]string{
@@ -238,4 +520,188 @@ func TestDummyLoginHandler(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 		assert.Contains(t, w.Body.String(), "Invalid request format")
 	})
+
+	t.Run("outside dev mode", func(t *testing.T) {
+		handler := NewUserHandler(services.NewUserService(nil, nil, services.NewTokenManager("test-secret", time.Hour, "pvz-service", "pvz-api"), services.Lockout{}, services.PasswordPolicy{}, 30, false), 10)
+		router := gin.New()
+		router.POST("/dummyLogin", handler.DummyLogin)
+
+		req := httptest.NewRequest("POST", "/dummyLogin", bytes.NewBufferString(`{"role":"moderator"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"dummy_login_disabled"`)
+	})
+}
+
+func TestListUsersHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	users := []models.User{{ID: uuid.New(), Email: "pvz@example.com", Role: "employee"}}
+
+	newRouter := func(mockService *MockUserService) *gin.Engine {
+		router := gin.New()
+		router.GET("/users", moderatorAuthMock(), NewUserHandler(mockService, 10).List)
+		return router
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("GetUsers", mock.Anything, repository.UserFilter{}, 1, 10, "moderator").Return(users, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var got []models.User
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, users[0].ID, got[0].ID)
+		assert.NotContains(t, w.Body.String(), "password")
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		mockService := new(MockUserService)
+		deactivated := true
+		filter := repository.UserFilter{Role: "employee", Email: "pvz", Deactivated: &deactivated}
+		mockService.On("GetUsers", mock.Anything, filter, 2, 5, "moderator").Return([]models.User{}, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("GET", "/users?role=employee&email=pvz&status=deactivated&page=2&limit=5", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, "[]", w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newRouter(new(MockUserService)).ServeHTTP(w, httptest.NewRequest("GET", "/users?status=locked", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("GetUsers", mock.Anything, repository.UserFilter{}, 1, 100, "moderator").Return([]models.User(nil), &services.LimitError{Max: 50})
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("GET", "/users?limit=100", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invalid_limit"`)
+	})
+}
+
+func TestGetUserHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := uuid.New()
+	router := func(mockService *MockUserService) *gin.Engine {
+		router := gin.New()
+		router.GET("/users/:userId", moderatorAuthMock(), NewUserHandler(mockService, 10).Get)
+		return router
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockUserService)
+		deactivatedAt := time.Now().UTC()
+		mockService.On("GetUser", mock.Anything, id, "moderator").Return(models.User{ID: id, Role: "employee", DeactivatedAt: &deactivatedAt}, nil)
+
+		w := httptest.NewRecorder()
+		router(mockService).ServeHTTP(w, httptest.NewRequest("GET", "/users/"+id.String(), nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"deactivatedAt"`)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("GetUser", mock.Anything, id, "moderator").Return(models.User{}, repository.ErrUserNotFound)
+
+		w := httptest.NewRecorder()
+		router(mockService).ServeHTTP(w, httptest.NewRequest("GET", "/users/"+id.String(), nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestManageUserHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := uuid.New()
+
+	newRouter := func(mockService *MockUserService) *gin.Engine {
+		handler := NewUserHandler(mockService, 10)
+		router := gin.New()
+		router.Use(moderatorAuthMock())
+		router.PUT("/users/:userId/role", handler.ChangeRole)
+		router.POST("/users/:userId/deactivate", handler.Deactivate)
+		router.POST("/users/:userId/reactivate", handler.Reactivate)
+		router.DELETE("/users/:userId", handler.Delete)
+		return router
+	}
+
+	t.Run("change role", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("ChangeRole", mock.Anything, id, "moderator", testUserID, "moderator").Return(nil)
+
+		req := httptest.NewRequest("PUT", "/users/"+id.String()+"/role", bytes.NewBufferString(`{"role":"moderator"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/users/"+id.String()+"/role", bytes.NewBufferString(`{"role":"admin"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		newRouter(new(MockUserService)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("deactivate and reactivate", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("SetDeactivated", mock.Anything, id, true, testUserID, "moderator").Return(nil)
+		mockService.On("SetDeactivated", mock.Anything, id, false, testUserID, "moderator").Return(nil)
+		router := newRouter(mockService)
+
+		for _, action := range []string{"deactivate", "reactivate"} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/users/"+id.String()+"/"+action, nil))
+			assert.Equal(t, http.StatusNoContent, w.Code, action)
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("own account", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("SetDeactivated", mock.Anything, testUserID, true, testUserID, "moderator").Return(services.ErrOwnAccount)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("POST", "/users/"+testUserID.String()+"/deactivate", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"own_account"`)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("DeleteUser", mock.Anything, id, testUserID, "moderator").Return(nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, httptest.NewRequest("DELETE", "/users/"+id.String(), nil))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newRouter(new(MockUserService)).ServeHTTP(w, httptest.NewRequest("DELETE", "/users/not-a-uuid", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
 }
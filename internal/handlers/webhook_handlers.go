@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"pvz/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WebhookHandler struct {
+	webhookService services.WebhookServiceInterface
+}
+
+func NewWebhookHandler(webhookService services.WebhookServiceInterface) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req struct {
+		URL       string `json:"url" binding:"required"`
+		EventType string `json:"eventType" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(c.Request.Context(), req.URL, req.EventType, userID, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	subscriptions, err := h.webhookService.GetSubscriptions(c.Request.Context(), role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("subscriptionId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	err = h.webhookService.DeleteSubscription(c.Request.Context(), id, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "webhook subscription deleted successfully"})
+}
+
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("subscriptionId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(c.Request.Context(), id, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("deliveryId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), id, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
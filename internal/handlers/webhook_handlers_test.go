@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateSubscription(ctx context.Context, targetURL, eventType string, userID uuid.UUID, role string) (models.WebhookSubscription, error) {
+	args := m.Called(ctx, targetURL, eventType, userID, role)
+	return args.Get(0).(models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) GetSubscriptions(ctx context.Context, role string) ([]models.WebhookSubscription, error) {
+	args := m.Called(ctx, role)
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteSubscription(ctx context.Context, id uuid.UUID, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) GetDeliveries(ctx context.Context, subscriptionID uuid.UUID, role string) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, role)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID, role string) (models.WebhookDelivery, error) {
+	args := m.Called(ctx, deliveryID, role)
+	return args.Get(0).(models.WebhookDelivery), args.Error(1)
+}
+
+func moderatorAuthMock() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user_id", testUserID)
+		c.Set("role", "moderator")
+		c.Next()
+	}
+}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	router := gin.Default()
+	router.POST("/webhooks", moderatorAuthMock(), handler.Create)
+
+	body := map[string]string{"url": "https://partner.example/hook", "eventType": models.EventPVZCreated}
+
+	t.Run("successful subscription", func(t *testing.T) {
+		expected := models.WebhookSubscription{ID: uuid.New(), URL: body["url"], EventType: body["eventType"], Secret: "s3cr3t"}
+		mockService.On("CreateSubscription", mock.Anything, body["url"], body["eventType"], testUserID, "moderator").Return(expected, nil)
+
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", "/webhooks", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response models.WebhookSubscription
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, expected.ID, response.ID)
+		assert.Equal(t, "s3cr3t", response.Secret)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("not allowed event type", func(t *testing.T) {
+		mockService.ExpectedCalls = []*mock.Call{}
+		mockService.On("CreateSubscription", mock.Anything, body["url"], body["eventType"], testUserID, "moderator").
+			Return(models.WebhookSubscription{}, services.ErrEventTypeNotAllowed)
+
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", "/webhooks", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), services.ErrEventTypeNotAllowed.Error())
+	})
+}
+
+func TestWebhookHandler_Redeliver(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	router := gin.Default()
+	router.POST("/webhooks/deliveries/:deliveryId/redeliver", moderatorAuthMock(), handler.Redeliver)
+
+	deliveryID := uuid.New()
+	path := "/webhooks/deliveries/" + deliveryID.String() + "/redeliver"
+
+	t.Run("successful redelivery", func(t *testing.T) {
+		mockService.On("Redeliver", mock.Anything, deliveryID, "moderator").
+			Return(models.WebhookDelivery{ID: deliveryID, Status: models.DeliveryStatusPending}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), models.DeliveryStatusPending)
+	})
+
+	t.Run("delivery not found", func(t *testing.T) {
+		mockService.ExpectedCalls = []*mock.Call{}
+		mockService.On("Redeliver", mock.Anything, deliveryID, "moderator").
+			Return(models.WebhookDelivery{}, repository.ErrDeliveryNotFound)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
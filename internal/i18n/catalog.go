@@ -0,0 +1,109 @@
+package i18n
+
+// catalog is keyed by the problem codes from the problem package. English messages keep the
+// texts of the service and repository errors, which clients saw before the catalog existed.
+// Messages with verbs are filled in by problem.Writef.
+var catalog = map[Locale]map[string]message{
+	EN: {
+		"invalid_request":                 {"Invalid request", "The request is malformed."},
+		"unauthorized":                    {"Unauthorized", "A valid bearer token is required."},
+		"invalid_credentials":             {"Invalid credentials", "Invalid email or password"},
+		"access_denied":                   {"Access denied", "access denied"},
+		"city_not_allowed":                {"City not allowed", "not allowed city"},
+		"product_type_not_allowed":        {"Product type not allowed", "not allowed product type"},
+		"invalid_date_range":              {"Invalid date range", "start date_time is later then end date_time"},
+		"invalid_page":                    {"Invalid page", "page parametr is invalid"},
+		"invalid_limit":                   {"Invalid limit", "limit must be between 1 and %d"},
+		"invalid_role":                    {"Invalid role", "role is invalid"},
+		"event_type_not_allowed":          {"Event type not allowed", "not allowed event type"},
+		"invalid_webhook_url":             {"Invalid webhook URL", "webhook url is invalid"},
+		"user_exists":                     {"User exists", "user already exists"},
+		"pvz_not_found":                   {"PVZ not found", "pvz not found"},
+		"active_reception_exists":         {"Active reception exists", "active reception already exists"},
+		"no_active_reception":             {"No active reception", "no active reception"},
+		"empty_reception":                 {"Empty reception", "no products in reception"},
+		"reception_conflict":              {"Reception conflict", "reception conflict"},
+		"subscription_not_found":          {"Subscription not found", "webhook subscription not found"},
+		"delivery_not_found":              {"Delivery not found", "webhook delivery not found"},
+		"user_not_found":                  {"User not found", "user not found"},
+		"weak_password":                   {"Weak password", "The password does not meet the password policy, see violations."},
+		"wrong_current_password":          {"Wrong current password", "The current password is wrong."},
+		"invalid_reset_token":             {"Invalid reset token", "The password reset token is unknown, used or expired."},
+		"dummy_login_disabled":            {"Dummy login disabled", "Dummy login is only available in dev mode."},
+		"invalid_invitation":              {"Invalid invitation", "The invitation code is unknown, used, expired or issued for another email."},
+		"invitation_not_found":            {"Invitation not found", "invitation not found"},
+		"account_deactivated":             {"Account deactivated", "account is deactivated"},
+		"own_account":                     {"Own account", "moderators cannot change the role of, deactivate or delete their own account"},
+		"idempotency_key_reused":          {"Idempotency key reused", "This Idempotency-Key was already used with a different request."},
+		"idempotency_request_in_progress": {"Request in progress", "A request with this Idempotency-Key is still being processed, retry later."},
+		"rate_limited":                    {"Too many requests", "Request rate limit exceeded, retry after the time in Retry-After."},
+		"permission_not_allowed":          {"Permission not allowed", "not allowed permission"},
+		"invalid_expiry":                  {"Invalid expiry", "expiry is not in the future"},
+		"api_key_not_found":               {"API key not found", "api key not found"},
+		"internal_error":                  {"Internal error", "internal server error"},
+	},
+	RU: {
+		"invalid_request":                 {"Некорректный запрос", "Запрос имеет неверный формат."},
+		"unauthorized":                    {"Требуется авторизация", "Нужен действительный bearer-токен."},
+		"invalid_credentials":             {"Неверные учётные данные", "Неверный email или пароль"},
+		"access_denied":                   {"Доступ запрещён", "Вашей роли это действие недоступно."},
+		"city_not_allowed":                {"Город недоступен", "ПВЗ можно открыть только в Москве, Санкт-Петербурге или Казани."},
+		"product_type_not_allowed":        {"Тип товара недоступен", "Тип товара должен быть: электроника, одежда или обувь."},
+		"invalid_date_range":              {"Неверный период", "Дата начала позже даты окончания."},
+		"invalid_page":                    {"Неверная страница", "Номер страницы должен быть положительным."},
+		"invalid_limit":                   {"Неверный лимит", "Лимит должен быть от 1 до %d."},
+		"invalid_role":                    {"Неверная роль", "Роль должна быть employee или moderator."},
+		"event_type_not_allowed":          {"Тип события недоступен", "На этот тип события нельзя подписаться."},
+		"invalid_webhook_url":             {"Неверный адрес вебхука", "Адрес вебхука должен быть абсолютным http или https URL."},
+		"user_exists":                     {"Пользователь существует", "Пользователь с таким email уже зарегистрирован."},
+		"pvz_not_found":                   {"ПВЗ не найден", "ПВЗ не найден."},
+		"active_reception_exists":         {"Есть открытая приёмка", "В этом ПВЗ уже есть незакрытая приёмка."},
+		"no_active_reception":             {"Нет открытой приёмки", "В этом ПВЗ нет незакрытой приёмки."},
+		"empty_reception":                 {"Приёмка пуста", "В открытой приёмке нет товаров."},
+		"reception_conflict":              {"Конфликт приёмки", "Приёмка была изменена параллельно, повторите запрос."},
+		"subscription_not_found":          {"Подписка не найдена", "Подписка на вебхук не найдена."},
+		"delivery_not_found":              {"Доставка не найдена", "Доставка вебхука не найдена."},
+		"user_not_found":                  {"Пользователь не найден", "Пользователь не найден."},
+		"weak_password":                   {"Слабый пароль", "Пароль не соответствует требованиям, см. violations."},
+		"wrong_current_password":          {"Неверный текущий пароль", "Текущий пароль указан неверно."},
+		"invalid_reset_token":             {"Неверный токен сброса", "Токен сброса пароля неизвестен, уже использован или истёк."},
+		"dummy_login_disabled":            {"Тестовый вход отключён", "Тестовый вход доступен только в режиме разработки."},
+		"invalid_invitation":              {"Неверное приглашение", "Код приглашения неизвестен, уже использован, истёк или выдан на другой email."},
+		"invitation_not_found":            {"Приглашение не найдено", "Приглашение не найдено."},
+		"account_deactivated":             {"Аккаунт деактивирован", "Аккаунт деактивирован модератором."},
+		"own_account":                     {"Собственный аккаунт", "Модератор не может менять роль, деактивировать или удалять свой аккаунт."},
+		"idempotency_key_reused":          {"Ключ идемпотентности занят", "Этот Idempotency-Key уже использован с другим запросом."},
+		"idempotency_request_in_progress": {"Запрос выполняется", "Запрос с этим Idempotency-Key ещё обрабатывается, повторите позже."},
+		"rate_limited":                    {"Слишком много запросов", "Превышен лимит запросов, повторите через время из Retry-After."},
+		"permission_not_allowed":          {"Разрешение недоступно", "API-ключу можно выдать только receptions:write и products:write."},
+		"invalid_expiry":                  {"Неверный срок действия", "Срок действия должен быть в будущем."},
+		"api_key_not_found":               {"API-ключ не найден", "API-ключ не найден или уже отозван."},
+		"internal_error":                  {"Внутренняя ошибка", "Внутренняя ошибка сервера"},
+	},
+}
+
+var cityNames = map[Locale]map[string]string{
+	EN: {
+		"Москва":          "Moscow",
+		"Санкт-Петербург": "Saint Petersburg",
+		"Казань":          "Kazan",
+	},
+	RU: {
+		"Москва":          "Москва",
+		"Санкт-Петербург": "Санкт-Петербург",
+		"Казань":          "Казань",
+	},
+}
+
+var productTypeNames = map[Locale]map[string]string{
+	EN: {
+		"электроника": "Electronics",
+		"одежда":      "Clothes",
+		"обувь":       "Shoes",
+	},
+	RU: {
+		"электроника": "Электроника",
+		"одежда":      "Одежда",
+		"обувь":       "Обувь",
+	},
+}
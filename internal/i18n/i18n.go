@@ -0,0 +1,70 @@
+// Package i18n holds the ru/en message catalog: problem titles and messages keyed by error code,
+// and display names for the cities and product types the service accepts.
+package i18n
+
+import (
+	"golang.org/x/text/language"
+)
+
+type Locale string
+
+const (
+	RU Locale = "ru"
+	EN Locale = "en"
+
+	// Default is used when Accept-Language is absent or matches nothing. Error texts were English
+	// before localization, so clients that send no header keep getting them.
+	Default = EN
+)
+
+// the first tag is the fallback of the matcher
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Russian})
+
+// FromAcceptLanguage picks the best supported locale for an Accept-Language header value.
+func FromAcceptLanguage(header string) Locale {
+	if header == "" {
+		return Default
+	}
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	if index == 1 {
+		return RU
+	}
+	return EN
+}
+
+type message struct {
+	title string
+	text  string
+}
+
+// Message returns the localized title and message for an error code. ok is false for unknown codes.
+func Message(locale Locale, code string) (title, text string, ok bool) {
+	m, ok := catalog[locale][code]
+	if !ok {
+		m, ok = catalog[Default][code]
+	}
+	return m.title, m.text, ok
+}
+
+// CityName returns the display name of a city value, or the value itself if it is unknown.
+func CityName(locale Locale, city string) string {
+	if name, ok := cityNames[locale][city]; ok {
+		return name
+	}
+	return city
+}
+
+// ProductTypeName returns the display name of a product type value, or the value itself if it is unknown.
+func ProductTypeName(locale Locale, productType string) string {
+	if name, ok := productTypeNames[locale][productType]; ok {
+		return name
+	}
+	return productType
+}
@@ -0,0 +1,64 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"pvz/internal/i18n"
+	"pvz/internal/problem"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromAcceptLanguage(t *testing.T) {
+	cases := []struct {
+		header string
+		want   i18n.Locale
+	}{
+		{"", i18n.EN},
+		{"ru", i18n.RU},
+		{"ru-RU,ru;q=0.9,en;q=0.8", i18n.RU},
+		{"en-US,en;q=0.9,ru;q=0.8", i18n.EN},
+		{"de-DE,ru;q=0.5", i18n.RU},
+		{"fr", i18n.EN},
+		{"not a header;;", i18n.EN},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.header, func(t *testing.T) {
+			assert.Equal(t, tc.want, i18n.FromAcceptLanguage(tc.header))
+		})
+	}
+}
+
+func TestCatalogCoversProblemCodes(t *testing.T) {
+	codes := []string{
+		problem.CodeInvalidRequest, problem.CodeUnauthorized, problem.CodeInvalidCredentials, problem.CodeAccessDenied,
+		problem.CodeCityNotAllowed, problem.CodeProductTypeNotAllowed, problem.CodeInvalidDateRange, problem.CodeInvalidPage,
+		problem.CodeInvalidLimit, problem.CodeInvalidRole, problem.CodeEventTypeNotAllowed, problem.CodeInvalidWebhookURL,
+		problem.CodeUserExists, problem.CodePVZNotFound, problem.CodeActiveReceptionExists, problem.CodeNoActiveReception,
+		problem.CodeEmptyReception, problem.CodeReceptionConflict, problem.CodeSubscriptionNotFound,
+		problem.CodeDeliveryNotFound, problem.CodeIdempotencyKeyReused, problem.CodeIdempotencyRequestInProgress,
+		problem.CodeRateLimited, problem.CodeUserNotFound, problem.CodeWeakPassword,
+		problem.CodeWrongCurrentPassword, problem.CodeInvalidResetToken, problem.CodeDummyLoginDisabled,
+		problem.CodeInvalidInvitation, problem.CodeInvitationNotFound, problem.CodeAccountDeactivated,
+		problem.CodeOwnAccount, problem.CodePermissionNotAllowed, problem.CodeInvalidExpiry, problem.CodeAPIKeyNotFound,
+		problem.CodeInternal,
+	}
+
+	for _, locale := range []i18n.Locale{i18n.EN, i18n.RU} {
+		for _, code := range codes {
+			title, text, ok := i18n.Message(locale, code)
+			assert.True(t, ok, "%s: no message for %s", locale, code)
+			assert.NotEmpty(t, title, "%s: empty title for %s", locale, code)
+			assert.NotEmpty(t, text, "%s: empty message for %s", locale, code)
+		}
+	}
+}
+
+func TestDisplayNames(t *testing.T) {
+	assert.Equal(t, "Saint Petersburg", i18n.CityName(i18n.EN, "Санкт-Петербург"))
+	assert.Equal(t, "Казань", i18n.CityName(i18n.RU, "Казань"))
+	assert.Equal(t, "Тверь", i18n.CityName(i18n.EN, "Тверь"))
+	assert.Equal(t, "Shoes", i18n.ProductTypeName(i18n.EN, "обувь"))
+	assert.Equal(t, "Электроника", i18n.ProductTypeName(i18n.RU, "электроника"))
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"pvz/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestLogger(t *testing.T, level string) (*slog.Logger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, err := New(&buf, config.LogConfig{Level: level, Format: "json"})
+	require.NoError(t, err)
+	return logger, &buf
+}
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestNew(t *testing.T) {
+	t.Run("filters by level", func(t *testing.T) {
+		logger, buf := newTestLogger(t, "warn")
+
+		logger.Info("hidden")
+		assert.Empty(t, buf.String())
+		logger.Warn("shown")
+		assert.Equal(t, "WARN", decode(t, buf)["level"])
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		_, err := New(&bytes.Buffer{}, config.LogConfig{Level: "verbose", Format: "json"})
+		assert.Error(t, err)
+		_, err = New(&bytes.Buffer{}, config.LogConfig{Level: "info", Format: "xml"})
+		assert.Error(t, err)
+	})
+}
+
+func TestWith(t *testing.T) {
+	logger, buf := newTestLogger(t, "info")
+	ctx := With(context.Background(), slog.String("request_id", "req-1"))
+	ctx = With(ctx, slog.String("user_id", "u-1"), slog.String("role", "employee"))
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	logger.InfoContext(ctx, "request")
+
+	record := decode(t, buf)
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "u-1", record["user_id"])
+	assert.Equal(t, "employee", record["role"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", record["span_id"])
+}
+
+func TestRedact(t *testing.T) {
+	t.Run("secret keys", func(t *testing.T) {
+		logger, buf := newTestLogger(t, "info")
+
+		logger.Info("login", slog.String("password", "hunter2"), slog.Group("jwt", slog.String("Secret", "s3cr3t")), slog.String("email", "a@b.c"))
+
+		record := decode(t, buf)
+		assert.Equal(t, Redacted, record["password"])
+		assert.Equal(t, Redacted, record["jwt"].(map[string]interface{})["Secret"])
+		assert.Equal(t, "a@b.c", record["email"])
+	})
+
+	t.Run("secrets inside values", func(t *testing.T) {
+		logger, buf := newTestLogger(t, "info")
+
+		logger.Error("host=db password=hunter2 dbname=pvz",
+			slog.Any("error", errors.New("dial postgres://pvz:hunter2@db:5432/pvz failed")),
+			slog.String("header", "Bearer eyJhbGciOiJIUzI1NiJ9.e30.c2lnbmF0dXJl"))
+
+		out := buf.String()
+		assert.NotContains(t, out, "hunter2")
+		assert.NotContains(t, out, "eyJhbGciOiJIUzI1NiJ9")
+		record := decode(t, buf)
+		assert.Equal(t, "host=db password=[REDACTED] dbname=pvz", record["msg"])
+		assert.Equal(t, "dial postgres://pvz:[REDACTED]@db:5432/pvz failed", record["error"])
+	})
+}
+
+func TestRedactString(t *testing.T) {
+	assert.Equal(t, "password=[REDACTED] user=pvz", RedactString("password='two words' user=pvz"))
+	assert.Equal(t, "PASSWORD: [REDACTED]", RedactString("PASSWORD: abc"))
+	assert.Equal(t, "nothing secret", RedactString("nothing secret"))
+}
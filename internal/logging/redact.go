@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces secret values in log output.
+const Redacted = "[REDACTED]"
+
+// secretKeys are substrings of attribute keys whose values are never written.
+var secretKeys = []string{"password", "secret", "token", "authorization", "api_key", "apikey", "cookie", "dsn"}
+
+// secretPatterns catch secrets embedded in messages and error texts: key=value connection
+// strings, URLs with credentials and bearer tokens.
+var secretPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)(password\s*[=:]\s*)('[^']*'|"[^"]*"|\S+)`), "${1}" + Redacted},
+	{regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+@`), "${1}" + Redacted + "@"},
+	{regexp.MustCompile(`(?i)(bearer\s+)[\w\-.~+/]+=*`), "${1}" + Redacted},
+}
+
+// redact is the ReplaceAttr hook of the handlers, so every record goes through it whatever
+// code logged it, including the standard log package once the logger is the slog default.
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindGroup {
+		return attr
+	}
+	if isSecretKey(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	switch value := attr.Value.Resolve().Any().(type) {
+	case string:
+		return slog.String(attr.Key, RedactString(value))
+	case error:
+		return slog.String(attr.Key, RedactString(value.Error()))
+	}
+	return attr
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString masks the secrets found in s.
+func RedactString(s string) string {
+	for _, p := range secretPatterns {
+		s = p.pattern.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
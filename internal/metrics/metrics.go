@@ -0,0 +1,202 @@
+// Package metrics exposes Prometheus metrics for /metrics: HTTP traffic per route, business
+// operations reported through services.Observer, open receptions and the database pool.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"pvz/internal/models"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unknownCity labels operations whose PVZ city could not be looked up.
+const unknownCity = "unknown"
+
+// scrapeTimeout bounds the queries run while /metrics is scraped.
+const scrapeTimeout = 5 * time.Second
+
+// CityLookup resolves the city of a PVZ; repository.PVZRepository implements it.
+type CityLookup interface {
+	GetPVZCity(ctx context.Context, id uuid.UUID) (string, error)
+}
+
+// OpenReceptionCounter counts in-progress receptions per city; repository.ReceptionRepository implements it.
+type OpenReceptionCounter interface {
+	CountOpenReceptions(ctx context.Context) (map[string]int, error)
+}
+
+type Metrics struct {
+	registry *prometheus.Registry
+	cities   CityLookup
+	// cityCache maps PVZ ids to cities, which never change once a PVZ is created
+	cityCache sync.Map
+
+	httpRequests     *prometheus.CounterVec
+	httpDuration     *prometheus.HistogramVec
+	pvzCreated       *prometheus.CounterVec
+	receptionsOpened *prometheus.CounterVec
+	receptionsClosed *prometheus.CounterVec
+	productsAdded    *prometheus.CounterVec
+	productsDeleted  *prometheus.CounterVec
+}
+
+func New(cities CityLookup) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		cities:   cities,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route template and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		pvzCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pvz_created_total",
+			Help: "PVZs created, by city.",
+		}, []string{"city"}),
+		receptionsOpened: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pvz_receptions_opened_total",
+			Help: "Receptions opened, by city.",
+		}, []string{"city"}),
+		receptionsClosed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pvz_receptions_closed_total",
+			Help: "Receptions closed, by city.",
+		}, []string{"city"}),
+		productsAdded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pvz_products_added_total",
+			Help: "Products added to receptions, by product type and city.",
+		}, []string{"type", "city"}),
+		productsDeleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pvz_products_deleted_total",
+			Help: "Products deleted from receptions, by product type and city.",
+		}, []string{"type", "city"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.pvzCreated,
+		m.receptionsOpened,
+		m.receptionsClosed,
+		m.productsAdded,
+		m.productsDeleted,
+	)
+	return m
+}
+
+// RegisterDB adds the connection pool statistics and the open receptions gauge. Both are read
+// when /metrics is scraped, so they are correct across replicas and restarts.
+func (m *Metrics) RegisterDB(db *sql.DB, receptions OpenReceptionCounter) {
+	m.registry.MustRegister(
+		collectors.NewDBStatsCollector(db, "pvz"),
+		&openReceptionsCollector{counter: receptions},
+	)
+}
+
+// Registry is where the metrics live; further collectors can be added to it.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware records every request under its route template, so path parameters do not create
+// new series. Requests that match no route share the "unmatched" label.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *Metrics) PVZCreated(_ context.Context, pvz models.PVZ) {
+	m.cityCache.Store(pvz.ID, pvz.City)
+	m.pvzCreated.WithLabelValues(pvz.City).Inc()
+}
+
+func (m *Metrics) ReceptionOpened(ctx context.Context, reception models.Reception) {
+	m.receptionsOpened.WithLabelValues(m.city(ctx, reception.PVZID)).Inc()
+}
+
+func (m *Metrics) ReceptionClosed(ctx context.Context, reception models.Reception) {
+	m.receptionsClosed.WithLabelValues(m.city(ctx, reception.PVZID)).Inc()
+}
+
+func (m *Metrics) ProductAdded(ctx context.Context, product models.Product, pvzID uuid.UUID) {
+	m.productsAdded.WithLabelValues(product.ProductType, m.city(ctx, pvzID)).Inc()
+}
+
+func (m *Metrics) ProductDeleted(ctx context.Context, product models.Product, pvzID uuid.UUID) {
+	m.productsDeleted.WithLabelValues(product.ProductType, m.city(ctx, pvzID)).Inc()
+}
+
+func (m *Metrics) city(ctx context.Context, pvzID uuid.UUID) string {
+	if city, ok := m.cityCache.Load(pvzID); ok {
+		return city.(string)
+	}
+	if m.cities == nil {
+		return unknownCity
+	}
+
+	city, err := m.cities.GetPVZCity(ctx, pvzID)
+	if err != nil {
+		slog.WarnContext(ctx, "metrics: failed to look up pvz city", slog.String("pvz_id", pvzID.String()), slog.Any("error", err))
+		return unknownCity
+	}
+	m.cityCache.Store(pvzID, city)
+	return city
+}
+
+var openReceptionsDesc = prometheus.NewDesc(
+	"pvz_receptions_open",
+	"Receptions currently in progress, by city.",
+	[]string{"city"}, nil,
+)
+
+type openReceptionsCollector struct {
+	counter OpenReceptionCounter
+}
+
+func (c *openReceptionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openReceptionsDesc
+}
+
+func (c *openReceptionsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	counts, err := c.counter.CountOpenReceptions(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(openReceptionsDesc, err)
+		return
+	}
+	for city, count := range counts {
+		ch <- prometheus.MustNewConstMetric(openReceptionsDesc, prometheus.GaugeValue, float64(count), city)
+	}
+}
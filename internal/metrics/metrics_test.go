@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pvz/internal/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCityLookup struct {
+	mock.Mock
+}
+
+func (m *MockCityLookup) GetPVZCity(ctx context.Context, id uuid.UUID) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+type MockOpenReceptionCounter struct {
+	mock.Mock
+}
+
+func (m *MockOpenReceptionCounter) CountOpenReceptions(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New(nil)
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/pvz/:pvzId", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/pvz/1", "/pvz/2", "/nowhere"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/pvz/:pvzId", "204")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestBusinessCounters(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cities are looked up once", func(t *testing.T) {
+		cities := new(MockCityLookup)
+		m := New(cities)
+		pvzID := uuid.New()
+		cities.On("GetPVZCity", mock.Anything, pvzID).Return("Казань", nil).Once()
+
+		m.ReceptionOpened(ctx, models.Reception{PVZID: pvzID})
+		m.ProductAdded(ctx, models.Product{ProductType: "обувь"}, pvzID)
+		m.ProductAdded(ctx, models.Product{ProductType: "обувь"}, pvzID)
+		m.ProductDeleted(ctx, models.Product{ProductType: "обувь"}, pvzID)
+		m.ReceptionClosed(ctx, models.Reception{PVZID: pvzID})
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.receptionsOpened.WithLabelValues("Казань")))
+		assert.Equal(t, 2.0, testutil.ToFloat64(m.productsAdded.WithLabelValues("обувь", "Казань")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.productsDeleted.WithLabelValues("обувь", "Казань")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.receptionsClosed.WithLabelValues("Казань")))
+		cities.AssertExpectations(t)
+	})
+
+	t.Run("created PVZs need no lookup", func(t *testing.T) {
+		cities := new(MockCityLookup)
+		m := New(cities)
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+
+		m.PVZCreated(ctx, pvz)
+		m.ReceptionOpened(ctx, models.Reception{PVZID: pvz.ID})
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.pvzCreated.WithLabelValues("Москва")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.receptionsOpened.WithLabelValues("Москва")))
+		cities.AssertNotCalled(t, "GetPVZCity", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed lookup is counted as unknown", func(t *testing.T) {
+		cities := new(MockCityLookup)
+		m := New(cities)
+		pvzID := uuid.New()
+		cities.On("GetPVZCity", mock.Anything, pvzID).Return("", errors.New("db down"))
+
+		m.ReceptionOpened(ctx, models.Reception{PVZID: pvzID})
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.receptionsOpened.WithLabelValues(unknownCity)))
+	})
+}
+
+func TestHandler(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	receptions := new(MockOpenReceptionCounter)
+	receptions.On("CountOpenReceptions", mock.Anything).Return(map[string]int{"Москва": 2}, nil)
+
+	m := New(nil)
+	m.RegisterDB(db, receptions)
+	m.PVZCreated(context.Background(), models.PVZ{ID: uuid.New(), City: "Москва"})
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `pvz_created_total{city="Москва"} 1`)
+	assert.Contains(t, body, `pvz_receptions_open{city="Москва"} 2`)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="pvz"}`)
+	assert.Contains(t, body, "go_goroutines")
+}
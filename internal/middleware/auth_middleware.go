@@ -1,80 +1,110 @@
 package middleware
 
 import (
-	"fmt"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
-	"os"
+	"pvz/internal/logging"
+	"pvz/internal/problem"
 	"pvz/internal/services"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
-	"github.com/joho/godotenv"
 )
 
-var secretKey []byte
-
-func JWTMiddleware() gin.HandlerFunc {
+// JWTMiddleware requires a valid token that the checker still honours, so tokens of users who were
+// deactivated or deleted since are refused.
+func JWTMiddleware(tokens *services.TokenManager, checker services.TokenCheckerInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
+		if c.GetHeader("Authorization") == "" {
+			problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Authorization header is required")
 			return
 		}
+		authenticate(c, tokens, checker)
+	}
+}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+// OptionalJWTMiddleware lets requests without a token through anonymously. A token that is sent
+// must be valid, so a mistyped one is reported instead of silently ignored.
+func OptionalJWTMiddleware(tokens *services.TokenManager, checker services.TokenCheckerInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
 			return
 		}
+		authenticate(c, tokens, checker)
+	}
+}
 
-		tokenString := parts[1]
-
-		if string(secretKey) == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error: JWT secret is missing"})
-			c.Abort()
+// JWTOrAPIKeyMiddleware accepts a token like JWTMiddleware or, without one, an API key in X-API-Key that
+// has the permission. A key acts as services.APIKeyRole with its id as the user id, so handlers, rate
+// limits and idempotency treat it like a user; the PVZs it is limited to are stored as "pvz_scope".
+func JWTOrAPIKeyMiddleware(tokens *services.TokenManager, checker services.TokenCheckerInterface,
+	keys services.APIKeyAuthenticatorInterface, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			authenticate(c, tokens, checker)
 			return
 		}
-
-		token, err := jwt.ParseWithClaims(
-			tokenString,
-			&services.CustomClaims{},
-			func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-				}
-				return []byte(os.Getenv("JWT_SECRET")), nil
-			},
-		)
-
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token: " + err.Error()})
-			c.Abort()
+		if c.GetHeader("X-API-Key") == "" {
+			problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Authorization or X-API-Key header is required")
 			return
 		}
+		authenticateAPIKey(c, keys, permission)
+	}
+}
 
-		if claims, ok := token.Claims.(*services.CustomClaims); ok && token.Valid {
-			c.Set("user_id", claims.UserID)
-			c.Set("role", claims.Role)
-			c.Next()
+func authenticateAPIKey(c *gin.Context, keys services.APIKeyAuthenticatorInterface, permission string) {
+	key, err := keys.AuthenticateAPIKey(c.Request.Context(), c.GetHeader("X-API-Key"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid API key")
 			return
 		}
+		slog.ErrorContext(c.Request.Context(), "failed to check API key", slog.Any("error", err))
+		problem.Write(c, http.StatusInternalServerError, problem.CodeInternal, "")
+		return
+	}
+	if !key.HasPermission(permission) {
+		problem.Write(c, http.StatusForbidden, problem.CodeAccessDenied, "API key lacks the "+permission+" permission")
+		return
+	}
 
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-		c.Abort()
+	c.Set("user_id", key.ID)
+	c.Set("role", services.APIKeyRole)
+	if len(key.PVZIDs) > 0 {
+		c.Set("pvz_scope", key.PVZIDs)
 	}
+	c.Request = c.Request.WithContext(logging.With(c.Request.Context(),
+		slog.String("user_id", key.ID.String()), slog.String("role", services.APIKeyRole), slog.String("api_key_id", key.ID.String())))
+	c.Next()
 }
 
-func InitSecretKey() {
-	if err := godotenv.Load(".env.secret"); err != nil {
-		log.Fatal("Ошибка загрузки .env.secret файла")
+func authenticate(c *gin.Context, tokens *services.TokenManager, checker services.TokenCheckerInterface) {
+	parts := strings.Split(c.GetHeader("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid authorization header format")
+		return
+	}
+
+	claims, err := tokens.Parse(parts[1])
+	if err != nil {
+		problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid token: "+err.Error())
+		return
+	}
+	if err := checker.CheckToken(c.Request.Context(), claims); err != nil {
+		if errors.Is(err, services.ErrTokenRevoked) {
+			problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid token: "+err.Error())
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "failed to check token", slog.Any("error", err))
+		problem.Write(c, http.StatusInternalServerError, problem.CodeInternal, "")
+		return
 	}
-	secretKey = []byte(os.Getenv("JWT_SECRET"))
-}
 
-func SetSecretKey(secret string) {
-	secretKey = []byte(secret)
+	c.Set("user_id", claims.UserID)
+	c.Set("role", claims.Role)
+	c.Request = c.Request.WithContext(logging.With(c.Request.Context(),
+		slog.String("user_id", claims.UserID.String()), slog.String("role", claims.Role)))
+	c.Next()
 }
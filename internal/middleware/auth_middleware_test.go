@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pvz/internal/models"
+	"pvz/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allowTokens honours every valid token.
+var allowTokens = services.TokenCheckerFunc(func(context.Context, *services.CustomClaims) error { return nil })
+
+func TestJWTMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := services.NewTokenManager("test-secret", time.Hour, "pvz-service", "pvz-api")
+	token, err := tokens.Generate(&models.User{ID: uuid.New(), Role: "moderator"})
+	require.NoError(t, err)
+	deactivatedID, brokenID := uuid.New(), uuid.New()
+	deactivatedToken, err := tokens.Generate(&models.User{ID: deactivatedID, Role: "employee"})
+	require.NoError(t, err)
+	brokenToken, err := tokens.Generate(&models.User{ID: brokenID, Role: "employee"})
+	require.NoError(t, err)
+	foreignToken, err := services.NewTokenManager("test-secret", time.Hour, "pvz-service", "billing").
+		Generate(&models.User{ID: uuid.New(), Role: "moderator"})
+	require.NoError(t, err)
+	checker := services.TokenCheckerFunc(func(_ context.Context, claims *services.CustomClaims) error {
+		switch claims.UserID {
+		case deactivatedID:
+			return services.ErrTokenRevoked
+		case brokenID:
+			return errors.New("db down")
+		}
+		return nil
+	})
+
+	router := gin.New()
+	echoRole := func(c *gin.Context) { c.String(http.StatusOK, c.GetString("role")) }
+	router.GET("/required", JWTMiddleware(tokens, checker), echoRole)
+	router.GET("/optional", OptionalJWTMiddleware(tokens, checker), echoRole)
+	send := func(path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	cases := []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+		role          string
+	}{
+		{"required with token", "/required", "Bearer " + token, http.StatusOK, "moderator"},
+		{"required without token", "/required", "", http.StatusUnauthorized, ""},
+		{"required with invalid token", "/required", "Bearer invalid", http.StatusUnauthorized, ""},
+		{"required with token for another audience", "/required", "Bearer " + foreignToken, http.StatusUnauthorized, ""},
+		{"required with revoked token", "/required", "Bearer " + deactivatedToken, http.StatusUnauthorized, ""},
+		{"required when the check fails", "/required", "Bearer " + brokenToken, http.StatusInternalServerError, ""},
+		{"optional with token", "/optional", "Bearer " + token, http.StatusOK, "moderator"},
+		{"optional without token", "/optional", "", http.StatusOK, ""},
+		{"optional with invalid token", "/optional", "Bearer invalid", http.StatusUnauthorized, ""},
+		{"optional with malformed header", "/optional", token, http.StatusUnauthorized, ""},
+		{"optional with revoked token", "/optional", "Bearer " + deactivatedToken, http.StatusUnauthorized, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := send(tc.path, tc.authorization)
+
+			assert.Equal(t, tc.status, w.Code)
+			if tc.status == http.StatusOK {
+				assert.Equal(t, tc.role, w.Body.String())
+			}
+		})
+	}
+}
+
+type apiKeysFunc func(ctx context.Context, key string) (*models.APIKey, error)
+
+func (f apiKeysFunc) AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKey, error) {
+	return f(ctx, key)
+}
+
+func TestJWTOrAPIKeyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := services.NewTokenManager("test-secret", time.Hour, "pvz-service", "pvz-api")
+	token, err := tokens.Generate(&models.User{ID: uuid.New(), Role: "moderator"})
+	require.NoError(t, err)
+	pvzID := uuid.New()
+	scoped := &models.APIKey{ID: uuid.New(), Permissions: []string{models.PermissionReceptionsWrite}, PVZIDs: []uuid.UUID{pvzID}}
+	keys := apiKeysFunc(func(_ context.Context, key string) (*models.APIKey, error) {
+		switch key {
+		case "pvz_scoped":
+			return scoped, nil
+		case "pvz_broken":
+			return nil, errors.New("db down")
+		}
+		return nil, services.ErrInvalidAPIKey
+	})
+
+	router := gin.New()
+	router.POST("/reception", JWTOrAPIKeyMiddleware(tokens, allowTokens, keys, models.PermissionReceptionsWrite), func(c *gin.Context) {
+		scope, _ := c.Get("pvz_scope")
+		c.JSON(http.StatusOK, gin.H{"user_id": c.MustGet("user_id"), "role": c.GetString("role"), "pvz_scope": scope})
+	})
+	router.POST("/products", JWTOrAPIKeyMiddleware(tokens, allowTokens, keys, models.PermissionProductsWrite), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	send := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("API key acts as an employee", func(t *testing.T) {
+		w := send("/reception", map[string]string{"X-API-Key": "pvz_scoped"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id":"`+scoped.ID.String()+`","role":"employee","pvz_scope":["`+pvzID.String()+`"]}`, w.Body.String())
+	})
+
+	cases := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		status  int
+	}{
+		{"token", "/reception", map[string]string{"Authorization": "Bearer " + token}, http.StatusOK},
+		{"token wins over API key", "/reception", map[string]string{"Authorization": "Bearer invalid", "X-API-Key": "pvz_scoped"}, http.StatusUnauthorized},
+		{"neither", "/reception", nil, http.StatusUnauthorized},
+		{"unknown API key", "/reception", map[string]string{"X-API-Key": "pvz_unknown"}, http.StatusUnauthorized},
+		{"API key without the permission", "/products", map[string]string{"X-API-Key": "pvz_scoped"}, http.StatusForbidden},
+		{"API key check fails", "/reception", map[string]string{"X-API-Key": "pvz_broken"}, http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.status, send(tc.path, tc.headers).Code)
+		})
+	}
+}
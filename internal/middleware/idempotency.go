@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"pvz/internal/problem"
+	"pvz/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency makes mutating requests that carry an Idempotency-Key safe to retry. The first request
+// with a key is processed and its response stored for ttl; a retry with the same method, path and body
+// gets the stored response replayed, while a different payload under the same key is rejected with 409.
+// While the request runs the key is held for lockTTL only, so a crash does not block retries for ttl.
+// Keys are scoped by user, so it must run after JWTMiddleware; anonymous requests are never
+// deduplicated, as they would all share one namespace of keys.
+func Idempotency(repo repository.IdempotencyRepositoryInterface, ttl, lockTTL time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userID, _ := c.Value("user_id").(uuid.UUID)
+		if key == "" || !isMutating(c.Request.Method) || userID == uuid.Nil {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			problem.Write(c, http.StatusBadRequest, problem.CodeInvalidRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, problem.CodeInvalidRequest, "failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash := fingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		ctx := c.Request.Context()
+		reserved, err := repo.Reserve(ctx, userID, key, requestHash, lockTTL)
+		if err != nil {
+			slog.ErrorContext(ctx, "idempotency: failed to reserve key", slog.Any("error", err))
+			problem.Write(c, http.StatusInternalServerError, problem.CodeInternal, "")
+			return
+		}
+		if !reserved {
+			replay(c, repo, userID, key, requestHash)
+			return
+		}
+
+		// the outcome is stored even if the client has already gone away
+		storeCtx := context.WithoutCancel(ctx)
+		defer func() {
+			// Recovery runs outside this middleware, so a panicking handler would leave the key reserved
+			if r := recover(); r != nil {
+				if err := repo.Release(storeCtx, userID, key); err != nil {
+					slog.ErrorContext(ctx, "idempotency: failed to release key", slog.Any("error", err))
+				}
+				panic(r)
+			}
+		}()
+
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			err = repo.Release(storeCtx, userID, key)
+		} else {
+			err = repo.Complete(storeCtx, userID, key, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes(), ttl)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "idempotency: failed to store response", slog.Any("error", err))
+		}
+	}
+}
+
+func replay(c *gin.Context, repo repository.IdempotencyRepositoryInterface, userID uuid.UUID, key, requestHash string) {
+	record, err := repo.Get(c.Request.Context(), userID, key)
+	if errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+		// expired right after Reserve saw it; the client can simply retry
+		problem.Write(c, http.StatusConflict, problem.CodeIdempotencyRequestInProgress, "")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "idempotency: failed to load stored response", slog.Any("error", err))
+		problem.Write(c, http.StatusInternalServerError, problem.CodeInternal, "")
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		problem.Write(c, http.StatusConflict, problem.CodeIdempotencyKeyReused, "")
+		return
+	}
+	if record.StatusCode == nil {
+		problem.Write(c, http.StatusConflict, problem.CodeIdempotencyRequestInProgress, "")
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(*record.StatusCode, record.ContentType, record.ResponseBody)
+	c.Abort()
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// capturingWriter keeps a copy of the response body so it can be stored for replays.
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
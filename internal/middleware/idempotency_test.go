@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pvz/internal/models"
+	"pvz/internal/problem"
+	"pvz/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]*models.IdempotencyRecord
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{records: make(map[string]*models.IdempotencyRecord)}
+}
+
+func (r *memoryIdempotencyRepository) Reserve(ctx context.Context, userID uuid.UUID, key, requestHash string, lockTTL time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if record, ok := r.records[userID.String()+key]; ok && record.ExpiresAt.After(time.Now()) {
+		return false, nil
+	}
+	r.records[userID.String()+key] = &models.IdempotencyRecord{UserID: userID, Key: key, RequestHash: requestHash, ExpiresAt: time.Now().Add(lockTTL)}
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepository) Get(ctx context.Context, userID uuid.UUID, key string) (*models.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, ok := r.records[userID.String()+key]
+	if !ok {
+		return nil, repository.ErrIdempotencyKeyNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(ctx context.Context, userID uuid.UUID, key string, statusCode int, contentType string, body []byte, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record := r.records[userID.String()+key]
+	record.ExpiresAt = time.Now().Add(ttl)
+	record.StatusCode = &statusCode
+	record.ContentType = contentType
+	record.ResponseBody = append([]byte(nil), body...)
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, userID.String()+key)
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	defaultUser := uuid.New()
+	setup := func(status int) (*gin.Engine, *int, *memoryIdempotencyRepository) {
+		repo := newMemoryIdempotencyRepository()
+		calls := 0
+		router := gin.New()
+		router.Use(gin.Recovery())
+		router.POST("/products",
+			func(c *gin.Context) {
+				switch userID := c.GetHeader("X-Test-User"); userID {
+				case "":
+					c.Set("user_id", defaultUser)
+				case "anonymous":
+				default:
+					c.Set("user_id", uuid.MustParse(userID))
+				}
+			},
+			Idempotency(repo, time.Hour, time.Minute),
+			func(c *gin.Context) {
+				calls++
+				if status == 0 {
+					panic("handler failed")
+				}
+				c.JSON(status, gin.H{"call": calls})
+			})
+		return router, &calls, repo
+	}
+
+	send := func(router *gin.Engine, key, body, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/products", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	problemCode := func(w *httptest.ResponseRecorder) string {
+		var details problem.Details
+		_ = json.Unmarshal(w.Body.Bytes(), &details)
+		return details.Code
+	}
+
+	t.Run("requests without a key are not deduplicated", func(t *testing.T) {
+		router, calls, _ := setup(http.StatusCreated)
+
+		send(router, "", `{"type":"обувь"}`, "")
+		send(router, "", `{"type":"обувь"}`, "")
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("retry replays the stored response", func(t *testing.T) {
+		router, calls, _ := setup(http.StatusCreated)
+
+		first := send(router, "scan-1", `{"type":"обувь"}`, "")
+		retry := send(router, "scan-1", `{"type":"обувь"}`, "")
+
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+		assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("same key with a different payload is rejected", func(t *testing.T) {
+		router, calls, _ := setup(http.StatusCreated)
+
+		send(router, "scan-1", `{"type":"обувь"}`, "")
+		w := send(router, "scan-1", `{"type":"одежда"}`, "")
+
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, problem.CodeIdempotencyKeyReused, problemCode(w))
+	})
+
+	t.Run("request still in progress", func(t *testing.T) {
+		router, calls, repo := setup(http.StatusCreated)
+		_, _ = repo.Reserve(context.Background(), defaultUser, "scan-1", fingerprint("POST", "/products", []byte(`{}`)), time.Hour)
+
+		w := send(router, "scan-1", `{}`, "")
+
+		assert.Equal(t, 0, *calls)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, problem.CodeIdempotencyRequestInProgress, problemCode(w))
+	})
+
+	t.Run("reservation is held for the lock TTL and the response for the TTL", func(t *testing.T) {
+		router, _, repo := setup(http.StatusCreated)
+		_, _ = repo.Reserve(context.Background(), defaultUser, "scan-2", "hash", time.Minute)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), repo.records[defaultUser.String()+"scan-2"].ExpiresAt, time.Second)
+
+		send(router, "scan-1", `{}`, "")
+		assert.WithinDuration(t, time.Now().Add(time.Hour), repo.records[defaultUser.String()+"scan-1"].ExpiresAt, time.Second)
+	})
+
+	t.Run("panicking handler releases the key", func(t *testing.T) {
+		router, calls, repo := setup(0)
+
+		first := send(router, "scan-1", `{}`, "")
+		send(router, "scan-1", `{}`, "")
+
+		assert.Equal(t, http.StatusInternalServerError, first.Code)
+		assert.Equal(t, 2, *calls, "the retry is processed again instead of waiting for the key")
+		assert.Empty(t, repo.records)
+	})
+
+	t.Run("server errors are not stored", func(t *testing.T) {
+		router, calls, _ := setup(http.StatusInternalServerError)
+
+		send(router, "scan-1", `{}`, "")
+		send(router, "scan-1", `{}`, "")
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("keys are scoped by user", func(t *testing.T) {
+		router, calls, _ := setup(http.StatusCreated)
+
+		send(router, "scan-1", `{}`, uuid.NewString())
+		w := send(router, "scan-1", `{}`, uuid.NewString())
+		assert.Equal(t, 2, *calls)
+		assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("anonymous requests are not deduplicated", func(t *testing.T) {
+		router, calls, repo := setup(http.StatusCreated)
+
+		send(router, "scan-1", `{}`, "anonymous")
+		w := send(router, "scan-1", `{}`, "anonymous")
+		assert.Equal(t, 2, *calls)
+		assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, repo.records)
+	})
+
+	t.Run("key too long", func(t *testing.T) {
+		router, calls, _ := setup(http.StatusCreated)
+
+		w := send(router, strings.Repeat("k", 256), `{}`, "")
+		assert.Equal(t, 0, *calls)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
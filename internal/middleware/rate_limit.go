@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"pvz/internal/problem"
+	"pvz/internal/ratelimit"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit lets each client make limit.Requests requests per limit.Period on the routes of group.
+// Clients are users once JWTMiddleware has run and client IPs before, so on protected routes it
+// must come after JWTMiddleware. Rejected requests get 429 with Retry-After in whole seconds.
+// When the store fails the request is let through: an outage of a shared store must not take the API down.
+func RateLimit(store ratelimit.Store, group string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := group + ":ip:" + c.ClientIP()
+		if userID, ok := c.Get("user_id"); ok {
+			key = fmt.Sprintf("%s:user:%v", group, userID)
+		}
+
+		decision, err := store.Take(c.Request.Context(), key, limit)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "rate limit store failed", slog.String("group", group), slog.Any("error", err))
+			c.Next()
+			return
+		}
+		if !decision.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds()))))
+			problem.Write(c, http.StatusTooManyRequests, problem.CodeRateLimited, "")
+			return
+		}
+		c.Next()
+	}
+}
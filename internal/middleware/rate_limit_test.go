@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pvz/internal/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Decision, error) {
+	return ratelimit.Decision{}, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limit := ratelimit.Limit{Requests: 2, Period: time.Minute}
+
+	newRouter := func(store ratelimit.Store) *gin.Engine {
+		router := gin.New()
+		router.POST("/login", RateLimit(store, "auth", limit), func(c *gin.Context) { c.Status(http.StatusOK) })
+		router.POST("/products", func(c *gin.Context) {
+			if user := c.GetHeader("X-Test-User"); user != "" {
+				c.Set("user_id", uuid.MustParse(user))
+			}
+		}, RateLimit(store, "write", limit), func(c *gin.Context) { c.Status(http.StatusCreated) })
+		return router
+	}
+	send := func(router *gin.Engine, path, remoteAddr, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, nil)
+		req.RemoteAddr = remoteAddr
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("rejects with 429 and Retry-After", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore())
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, http.StatusOK, send(router, "/login", "10.0.0.1:1234", "").Code)
+		}
+
+		w := send(router, "/login", "10.0.0.1:5678", "")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), `"code":"rate_limited"`)
+
+		assert.Equal(t, http.StatusOK, send(router, "/login", "10.0.0.2:1234", "").Code, "other IPs are not affected")
+	})
+
+	t.Run("authenticated requests are limited per user", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore())
+		alice, bob := uuid.NewString(), uuid.NewString()
+		send(router, "/products", "10.0.0.1:1", alice)
+		send(router, "/products", "10.0.0.2:1", alice)
+
+		assert.Equal(t, http.StatusTooManyRequests, send(router, "/products", "10.0.0.3:1", alice).Code)
+		assert.Equal(t, http.StatusCreated, send(router, "/products", "10.0.0.1:1", bob).Code, "same IP, other user")
+	})
+
+	t.Run("groups have separate buckets", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore())
+		send(router, "/login", "10.0.0.1:1", "")
+		send(router, "/login", "10.0.0.1:1", "")
+
+		assert.Equal(t, http.StatusCreated, send(router, "/products", "10.0.0.1:1", "").Code)
+	})
+
+	t.Run("store failure lets requests through", func(t *testing.T) {
+		captureLogs(t)
+		router := newRouter(failingStore{})
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, send(router, "/login", "10.0.0.1:1", "").Code)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"pvz/internal/config"
+	"pvz/internal/logging"
+	"pvz/internal/models"
+	"pvz/internal/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs makes the default logger write JSON to the returned buffer for the rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, config.LogConfig{Level: "info", Format: "json"})
+	require.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := services.NewTokenManager("test-secret", time.Hour, "pvz-service", "pvz-api")
+	newRouter := func() *gin.Engine {
+		router := gin.New()
+		router.Use(RequestLogger(), Recovery())
+		router.GET("/pvz", JWTMiddleware(tokens, allowTokens), func(c *gin.Context) { c.Status(http.StatusOK) })
+		router.GET("/panic", func(c *gin.Context) { panic("boom") })
+		return router
+	}
+
+	t.Run("logs the authenticated user", func(t *testing.T) {
+		buf := captureLogs(t)
+		user := &models.User{ID: uuid.New(), Role: "employee"}
+		token, err := tokens.Generate(user)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/pvz?city=Москва", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		newRouter().ServeHTTP(w, req)
+
+		requestID := w.Header().Get(RequestIDHeader)
+		_, err = uuid.Parse(requestID)
+		assert.NoError(t, err, "a request ID is generated")
+		records := logRecords(t, buf)
+		require.Len(t, records, 1)
+		record := records[0]
+		assert.Equal(t, "request", record["msg"])
+		assert.Equal(t, requestID, record["request_id"])
+		assert.Equal(t, user.ID.String(), record["user_id"])
+		assert.Equal(t, "employee", record["role"])
+		assert.Equal(t, "/pvz", record["path"])
+		assert.Equal(t, float64(http.StatusOK), record["status"])
+		assert.NotContains(t, buf.String(), token)
+	})
+
+	t.Run("keeps a valid incoming request ID", func(t *testing.T) {
+		buf := captureLogs(t)
+		req := httptest.NewRequest("GET", "/pvz", nil)
+		req.Header.Set(RequestIDHeader, "edge-42")
+		w := httptest.NewRecorder()
+		newRouter().ServeHTTP(w, req)
+
+		assert.Equal(t, "edge-42", w.Header().Get(RequestIDHeader))
+		assert.Equal(t, "edge-42", logRecords(t, buf)[0]["request_id"])
+	})
+
+	t.Run("replaces an unsafe request ID", func(t *testing.T) {
+		captureLogs(t)
+		req := httptest.NewRequest("GET", "/pvz", nil)
+		req.Header.Set(RequestIDHeader, "bad id\" injected")
+		w := httptest.NewRecorder()
+		newRouter().ServeHTTP(w, req)
+
+		assert.NotEqual(t, "bad id\" injected", w.Header().Get(RequestIDHeader))
+		assert.NotEmpty(t, w.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("panics are logged and answered with 500", func(t *testing.T) {
+		buf := captureLogs(t)
+		w := httptest.NewRecorder()
+		newRouter().ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		records := logRecords(t, buf)
+		require.Len(t, records, 2)
+		assert.Equal(t, "panic recovered", records[0]["msg"])
+		assert.Equal(t, records[1]["request_id"], records[0]["request_id"])
+		assert.Equal(t, "ERROR", records[1]["level"])
+	})
+}
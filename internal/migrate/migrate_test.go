@@ -0,0 +1,205 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"pvz/migrations"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	lockSQL   = regexp.QuoteMeta(lockQuery)
+	unlockSQL = regexp.QuoteMeta(unlockQuery)
+	createSQL = regexp.QuoteMeta(createTableQuery)
+	selectSQL = regexp.QuoteMeta(selectAppliedQuery)
+	insertSQL = regexp.QuoteMeta(insertAppliedQuery)
+	deleteSQL = regexp.QuoteMeta(deleteAppliedQuery)
+	testFS    = fstest.MapFS{
+		"0001_users.up.sql":   {Data: []byte("CREATE TABLE users (id UUID)")},
+		"0001_users.down.sql": {Data: []byte("DROP TABLE users")},
+		"0002_pvz.up.sql":     {Data: []byte("CREATE TABLE pvz (id UUID)")},
+	}
+	appliedColumns = []string{"version", "name", "applied_at"}
+)
+
+func newTestMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := New(db, testFS)
+	require.NoError(t, err)
+	return m, mock
+}
+
+func expectLocked(mock sqlmock.Sqlmock, applied *sqlmock.Rows) {
+	mock.ExpectExec(lockSQL).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(createSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(selectSQL).WillReturnRows(applied)
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(unlockSQL).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("orders by version", func(t *testing.T) {
+		migrations, err := Load(testFS)
+
+		require.NoError(t, err)
+		require.Len(t, migrations, 2)
+		assert.Equal(t, Migration{Version: 1, Name: "users", Up: "CREATE TABLE users (id UUID)", Down: "DROP TABLE users"}, migrations[0])
+		assert.Equal(t, int64(2), migrations[1].Version)
+		assert.Empty(t, migrations[1].Down)
+	})
+
+	t.Run("invalid file name", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{"users.sql": {Data: []byte("SELECT 1")}})
+		assert.Error(t, err)
+	})
+
+	t.Run("down without up", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{"0001_users.down.sql": {Data: []byte("DROP TABLE users")}})
+		assert.Error(t, err)
+	})
+
+	t.Run("conflicting names", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{
+			"0001_users.up.sql": {Data: []byte("CREATE TABLE users (id UUID)")},
+			"0001_pvz.up.sql":   {Data: []byte("CREATE TABLE pvz (id UUID)")},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("embedded migrations", func(t *testing.T) {
+		embedded, err := Load(migrations.FS)
+
+		require.NoError(t, err)
+		require.NotEmpty(t, embedded)
+		for i, m := range embedded {
+			assert.Equal(t, int64(i+1), m.Version, "versions are consecutive")
+			assert.NotEmpty(t, m.Down, "%d_%s needs a down migration", m.Version, m.Name)
+		}
+	})
+}
+
+func TestMigrator_Up(t *testing.T) {
+	t.Run("applies pending migrations", func(t *testing.T) {
+		m, mock := newTestMigrator(t)
+		expectLocked(mock, sqlmock.NewRows(appliedColumns).AddRow(1, "users", time.Now()))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE pvz (id UUID)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(insertSQL).WithArgs(int64(2), "pvz").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectUnlock(mock)
+
+		applied, err := m.Up(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, applied, 1)
+		assert.Equal(t, "pvz", applied[0].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing pending", func(t *testing.T) {
+		m, mock := newTestMigrator(t)
+		expectLocked(mock, sqlmock.NewRows(appliedColumns).AddRow(1, "users", time.Now()).AddRow(2, "pvz", time.Now()))
+		expectUnlock(mock)
+
+		applied, err := m.Up(context.Background())
+
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed migration is rolled back and stops the run", func(t *testing.T) {
+		m, mock := newTestMigrator(t)
+		expectLocked(mock, sqlmock.NewRows(appliedColumns))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE users (id UUID)")).WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+		expectUnlock(mock)
+
+		applied, err := m.Up(context.Background())
+
+		assert.ErrorContains(t, err, "1_users")
+		assert.Empty(t, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("lock not acquired", func(t *testing.T) {
+		m, mock := newTestMigrator(t)
+		mock.ExpectExec(lockSQL).WithArgs(lockKey).WillReturnError(context.DeadlineExceeded)
+
+		_, err := m.Up(context.Background())
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMigrator_Down(t *testing.T) {
+	t.Run("reverts the newest migration", func(t *testing.T) {
+		m, mock := newTestMigrator(t)
+		expectLocked(mock, sqlmock.NewRows(appliedColumns).AddRow(1, "users", time.Now()))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DROP TABLE users")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(deleteSQL).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectUnlock(mock)
+
+		reverted, err := m.Down(context.Background(), 1)
+
+		require.NoError(t, err)
+		require.Len(t, reverted, 1)
+		assert.Equal(t, int64(1), reverted[0].Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no down file", func(t *testing.T) {
+		m, mock := newTestMigrator(t)
+		expectLocked(mock, sqlmock.NewRows(appliedColumns).AddRow(1, "users", time.Now()).AddRow(2, "pvz", time.Now()))
+		expectUnlock(mock)
+
+		_, err := m.Down(context.Background(), 1)
+
+		assert.ErrorIs(t, err, ErrNoDownMigration)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown applied version", func(t *testing.T) {
+		m, mock := newTestMigrator(t)
+		expectLocked(mock, sqlmock.NewRows(appliedColumns).AddRow(7, "future", time.Now()))
+		expectUnlock(mock)
+
+		_, err := m.Down(context.Background(), 1)
+
+		assert.ErrorIs(t, err, ErrUnknownVersion)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMigrator_Status(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	appliedAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	expectLocked(mock, sqlmock.NewRows(appliedColumns).AddRow(1, "users", appliedAt).AddRow(7, "future", appliedAt))
+	expectUnlock(mock)
+
+	statuses, err := m.Status(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.Equal(t, Status{Version: 1, Name: "users", AppliedAt: &appliedAt}, statuses[0])
+	assert.Equal(t, Status{Version: 2, Name: "pvz"}, statuses[1])
+	assert.Equal(t, Status{Version: 7, Name: "future", AppliedAt: &appliedAt}, statuses[2])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
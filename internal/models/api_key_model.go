@@ -0,0 +1,33 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PermissionReceptionsWrite = "receptions:write"
+	PermissionProductsWrite   = "products:write"
+)
+
+// APIKey lets an integration call the API without a user. It acts with the employee role, but only
+// on the routes its permissions cover and, with PVZIDs set, only at those PVZs.
+type APIKey struct {
+	ID          uuid.UUID   `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	Key         string      `json:"key,omitempty"` // returned only on creation and rotation
+	Prefix      string      `json:"prefix" db:"prefix"`
+	Permissions []string    `json:"permissions" db:"permissions"`
+	PVZIDs      []uuid.UUID `json:"pvzIds" db:"pvz_ids"`
+	CreatedBy   uuid.UUID   `json:"createdBy" db:"created_by"`
+	CreatedAt   time.Time   `json:"createdAt" db:"created_at"`
+	ExpiresAt   *time.Time  `json:"expiresAt,omitempty" db:"expires_at"`
+	RotatedAt   *time.Time  `json:"rotatedAt,omitempty" db:"rotated_at"`
+	RevokedAt   *time.Time  `json:"revokedAt,omitempty" db:"revoked_at"`
+}
+
+func (k *APIKey) HasPermission(permission string) bool {
+	return slices.Contains(k.Permissions, permission)
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventPVZCreated       = "pvz.created"
+	EventReceptionCreated = "reception.created"
+	EventReceptionClosed  = "reception.closed"
+	EventProductAdded     = "product.added"
+	EventProductDeleted   = "product.deleted"
+)
+
+type Event struct {
+	Seq       int64           `json:"-" db:"seq"` // position in the outbox, used as the SSE event id
+	ID        uuid.UUID       `json:"id" db:"id"`
+	Type      string          `json:"type" db:"event_type"`
+	PVZID     uuid.UUID       `json:"pvzId" db:"pvz_id"`
+	Payload   json.RawMessage `json:"payload" db:"payload"`
+	CreatedAt time.Time       `json:"createdAt" db:"created_at"`
+	Attempts  int             `json:"-" db:"attempts"` // publish attempts so far, set on claimed events
+}
+
+// ReceptionEventPayload is the payload of reception.* events. Unlike the API model it exposes the reception id.
+type ReceptionEventPayload struct {
+	ReceptionID uuid.UUID  `json:"receptionId"`
+	PVZID       uuid.UUID  `json:"pvzId"`
+	DateTime    time.Time  `json:"dateTime"`
+	Status      string     `json:"status"`
+	CreatedBy   uuid.UUID  `json:"createdBy"`
+	ClosedBy    *uuid.UUID `json:"closedBy,omitempty"`
+}
+
+// ProductEventPayload is the payload of product.* events.
+type ProductEventPayload struct {
+	ProductID   uuid.UUID `json:"productId"`
+	ReceptionID uuid.UUID `json:"receptionId"`
+	PVZID       uuid.UUID `json:"pvzId"`
+	DateTime    time.Time `json:"dateTime"`
+	ProductType string    `json:"type"`
+	CreatedBy   uuid.UUID `json:"createdBy"`
+}
+
+func NewReceptionEventPayload(reception *Reception) ReceptionEventPayload {
+	return ReceptionEventPayload{
+		ReceptionID: reception.ID,
+		PVZID:       reception.PVZID,
+		DateTime:    reception.DateTime,
+		Status:      reception.Status,
+		CreatedBy:   reception.CreatedBy,
+		ClosedBy:    reception.ClosedBy,
+	}
+}
+
+func NewProductEventPayload(product *Product, pvzID uuid.UUID) ProductEventPayload {
+	return ProductEventPayload{
+		ProductID:   product.ID,
+		ReceptionID: product.ReceptionID,
+		PVZID:       pvzID,
+		DateTime:    product.DateTime,
+		ProductType: product.ProductType,
+		CreatedBy:   product.CreatedBy,
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation lets its holder register one moderator account before it expires. With an Email
+// set, only that email can use it.
+type Invitation struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Code      string     `json:"code,omitempty"` // returned only once, on creation
+	Email     string     `json:"email,omitempty" db:"email"`
+	CreatedBy uuid.UUID  `json:"createdBy" db:"created_by"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	ExpiresAt time.Time  `json:"expiresAt" db:"expires_at"`
+	UsedBy    *uuid.UUID `json:"usedBy,omitempty" db:"used_by"`
+	UsedAt    *time.Time `json:"usedAt,omitempty" db:"used_at"`
+}
@@ -11,4 +11,5 @@ type Product struct {
 	DateTime    time.Time `json:"-" db:"date_time"`
 	ProductType string    `json:"type" db:"type"`
 	ReceptionID uuid.UUID `json:"receptionId" db:"reception_id"`
+	CreatedBy   uuid.UUID `json:"createdBy" db:"created_by"`
 }
@@ -12,10 +12,12 @@ const (
 )
 
 type Reception struct {
-	ID       uuid.UUID `json:"-" db:"id"`
-	DateTime time.Time `json:"dateTime" db:"date_time"`
-	PVZID    uuid.UUID `json:"pvzId" db:"pvz_id"`
-	Status   string    `json:"status" db:"status"` // "in_progress" or "closed"
+	ID        uuid.UUID  `json:"-" db:"id"`
+	DateTime  time.Time  `json:"dateTime" db:"date_time"`
+	PVZID     uuid.UUID  `json:"pvzId" db:"pvz_id"`
+	Status    string     `json:"status" db:"status"` // "in_progress" or "closed"
+	CreatedBy uuid.UUID  `json:"createdBy" db:"created_by"`
+	ClosedBy  *uuid.UUID `json:"closedBy,omitempty" db:"closed_by"`
 }
 
 type ReceptionWithProducts struct {
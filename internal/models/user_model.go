@@ -1,10 +1,57 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
 	ID       uuid.UUID `json:"id"`
 	Email    string    `json:"email"`
 	Password string    `json:"-"`
 	Role     string    `json:"role"`
+	// FailedLoginAttempts counts wrong passwords since the last successful login or lockout.
+	FailedLoginAttempts int `json:"-"`
+	// LockedUntil is set while logins are refused after too many wrong passwords.
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
+	// DeactivatedAt is set while a moderator has deactivated the account: it cannot log in and its tokens are refused.
+	DeactivatedAt *time.Time `json:"deactivatedAt,omitempty"`
+	// TokensRevokedAt refuses the tokens issued before it.
+	TokensRevokedAt *time.Time `json:"-"`
+}
+
+// Profile is what GET /me tells a user about themselves.
+type Profile struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email,omitempty"`
+	Role  string    `json:"role"`
+	// AssignedPVZs are the ids of the PVZs the user works at.
+	AssignedPVZs []uuid.UUID `json:"assignedPvzs"`
+	// Dummy marks the profile of a /dummyLogin token, which has no account behind it.
+	Dummy bool `json:"dummy,omitempty"`
+}
+
+// Locked reports whether logins are refused at now.
+func (u *User) Locked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+const (
+	LoginFailureUnknownEmail  = "unknown_email"
+	LoginFailureWrongPassword = "wrong_password"
+	LoginFailureLocked        = "locked"
+	LoginFailureDeactivated   = "deactivated"
+)
+
+// LoginAttempt is one entry of the login history. UserID is empty for emails without an account.
+type LoginAttempt struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        *uuid.UUID `json:"userId,omitempty" db:"user_id"`
+	Email         string     `json:"email" db:"email"`
+	IP            string     `json:"ip" db:"ip"`
+	UserAgent     string     `json:"userAgent" db:"user_agent"`
+	Success       bool       `json:"success" db:"success"`
+	FailureReason string     `json:"failureReason,omitempty" db:"failure_reason"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
 }
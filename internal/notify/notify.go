@@ -0,0 +1,84 @@
+// Package notify delivers messages to users, such as password reset tokens. The service only needs
+// NotifierInterface; the log and file notifiers here are for development and tests, a mail or SMS
+// gateway is plugged in by implementing the interface.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+type NotifierInterface interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// LogNotifier writes messages to the log. The body is logged as is, so it must not be used where
+// logs are shared: anyone who can read them can use the tokens in the messages.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (LogNotifier) Notify(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "notification",
+		slog.String("to", msg.To), slog.String("subject", msg.Subject), slog.String("body", msg.Body))
+	return nil
+}
+
+// WriterNotifier writes every message as a JSON line with the time it was sent.
+type WriterNotifier struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	now    func() time.Time
+}
+
+func NewWriterNotifier(w io.Writer) *WriterNotifier {
+	return &WriterNotifier{w: w, now: time.Now}
+}
+
+func NewFileNotifier(path string) (*WriterNotifier, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open notifications file: %w", err)
+	}
+	return &WriterNotifier{w: f, closer: f, now: time.Now}, nil
+}
+
+func (n *WriterNotifier) Notify(_ context.Context, msg Message) error {
+	data, err := json.Marshal(struct {
+		Message
+		SentAt time.Time `json:"sentAt"`
+	}{msg, n.now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, err := n.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+	return nil
+}
+
+func (n *WriterNotifier) Close() error {
+	if n.closer != nil {
+		return n.closer.Close()
+	}
+	return nil
+}
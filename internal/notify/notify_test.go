@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterNotifier_Notify(t *testing.T) {
+	var buf bytes.Buffer
+	notifier := NewWriterNotifier(&buf)
+	notifier.now = func() time.Time { return time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC) }
+
+	err := notifier.Notify(context.Background(), Message{To: "user@example.com", Subject: "Password reset", Body: "code"})
+
+	require.NoError(t, err)
+	var got map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, map[string]string{
+		"to":      "user@example.com",
+		"subject": "Password reset",
+		"body":    "code",
+		"sentAt":  "2026-10-19T12:00:00Z",
+	}, got)
+}
+
+func TestFileNotifier_Notify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	notifier, err := NewFileNotifier(path)
+	require.NoError(t, err)
+
+	assert.NoError(t, notifier.Notify(context.Background(), Message{To: "a@example.com"}))
+	assert.NoError(t, notifier.Notify(context.Background(), Message{To: "b@example.com"}))
+	assert.NoError(t, notifier.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(data, []byte("\n")))
+}
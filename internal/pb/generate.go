@@ -0,0 +1,4 @@
+// Package pb holds code generated from the protobuf definitions in api/proto.
+package pb
+
+//go:generate protoc -I ../../api/proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative pvz/v1/pvz.proto
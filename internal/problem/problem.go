@@ -0,0 +1,116 @@
+// Package problem writes error responses as RFC 7807 problem details. Handlers and middleware
+// share it so every error the HTTP API returns has the same shape and a stable machine-readable code.
+package problem
+
+import (
+	"fmt"
+	"net/http"
+	"pvz/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ContentType = "application/problem+json"
+
+// typePrefix makes the problem type a URI, as RFC 7807 requires; it is not meant to be dereferenced.
+const typePrefix = "urn:pvz:problem:"
+
+// Stable error codes. Clients match on these, so existing values must never change.
+const (
+	CodeInvalidRequest               = "invalid_request"
+	CodeUnauthorized                 = "unauthorized"
+	CodeInvalidCredentials           = "invalid_credentials"
+	CodeAccessDenied                 = "access_denied"
+	CodeCityNotAllowed               = "city_not_allowed"
+	CodeProductTypeNotAllowed        = "product_type_not_allowed"
+	CodeInvalidDateRange             = "invalid_date_range"
+	CodeInvalidPage                  = "invalid_page"
+	CodeInvalidLimit                 = "invalid_limit"
+	CodeInvalidRole                  = "invalid_role"
+	CodeEventTypeNotAllowed          = "event_type_not_allowed"
+	CodeInvalidWebhookURL            = "invalid_webhook_url"
+	CodeUserExists                   = "user_exists"
+	CodePVZNotFound                  = "pvz_not_found"
+	CodeActiveReceptionExists        = "active_reception_exists"
+	CodeNoActiveReception            = "no_active_reception"
+	CodeEmptyReception               = "empty_reception"
+	CodeReceptionConflict            = "reception_conflict"
+	CodeSubscriptionNotFound         = "subscription_not_found"
+	CodeDeliveryNotFound             = "delivery_not_found"
+	CodeUserNotFound                 = "user_not_found"
+	CodeWeakPassword                 = "weak_password"
+	CodeWrongCurrentPassword         = "wrong_current_password"
+	CodeInvalidResetToken            = "invalid_reset_token"
+	CodeDummyLoginDisabled           = "dummy_login_disabled"
+	CodeInvalidInvitation            = "invalid_invitation"
+	CodeInvitationNotFound           = "invitation_not_found"
+	CodeAccountDeactivated           = "account_deactivated"
+	CodeOwnAccount                   = "own_account"
+	CodeIdempotencyKeyReused         = "idempotency_key_reused"
+	CodeIdempotencyRequestInProgress = "idempotency_request_in_progress"
+	CodeRateLimited                  = "rate_limited"
+	CodePermissionNotAllowed         = "permission_not_allowed"
+	CodeInvalidExpiry                = "invalid_expiry"
+	CodeAPIKeyNotFound               = "api_key_not_found"
+	CodeInternal                     = "internal_error"
+)
+
+type Details struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	// Violations lists the broken rules for codes such as weak_password.
+	Violations []string `json:"violations,omitempty"`
+}
+
+// New builds problem details with the title, and the detail when none is given, taken from the
+// message catalog in the requested locale.
+func New(locale i18n.Locale, status int, code, detail string) Details {
+	title, text, ok := i18n.Message(locale, code)
+	if !ok {
+		title = http.StatusText(status)
+	}
+	if detail == "" {
+		detail = text
+	}
+	return Details{
+		Type:   typePrefix + code,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Write aborts the request with a problem+json response in the locale chosen by Accept-Language.
+// An empty detail is replaced by the catalog message for the code.
+func Write(c *gin.Context, status int, code, detail string) {
+	locale := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+	writeDetails(c, locale, New(locale, status, code, detail))
+}
+
+// Writef writes problem details whose detail is the catalog message of the code filled in with args,
+// for messages that depend on configuration such as the maximum page size.
+func Writef(c *gin.Context, status int, code string, args ...any) {
+	locale := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+	_, text, _ := i18n.Message(locale, code)
+	writeDetails(c, locale, New(locale, status, code, fmt.Sprintf(text, args...)))
+}
+
+// WriteViolations is Write with the catalog detail and the list of broken rules.
+func WriteViolations(c *gin.Context, status int, code string, violations []string) {
+	locale := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+	details := New(locale, status, code, "")
+	details.Violations = violations
+	writeDetails(c, locale, details)
+}
+
+func writeDetails(c *gin.Context, locale i18n.Locale, details Details) {
+	details.Instance = c.Request.URL.Path
+	c.Header("Content-Type", ContentType)
+	c.Header("Content-Language", string(locale))
+	c.AbortWithStatusJSON(details.Status, details)
+}
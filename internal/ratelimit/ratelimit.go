@@ -0,0 +1,90 @@
+// Package ratelimit implements token buckets. A bucket holds up to Limit.Requests tokens and
+// refills at Requests per Period; every request takes one token. Buckets live in a Store: the
+// in-memory one limits each replica on its own, a shared implementation (e.g. Redis) can limit
+// the whole deployment.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Decision is the outcome of taking a token.
+type Decision struct {
+	Allowed bool
+	// RetryAfter is how long until the next token, set when the request is not allowed.
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	// Take takes a token from the bucket under key, which starts full.
+	Take(ctx context.Context, key string, limit Limit) (Decision, error)
+}
+
+// sweepInterval is how often MemoryStore drops buckets that have refilled completely.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket will have refilled completely; it can then be forgotten.
+	full time.Time
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(limit.Requests)
+	perToken := limit.Period / time.Duration(limit.Requests)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.updated))/float64(perToken))
+	b.updated = now
+	if b.tokens < 1 {
+		return Decision{RetryAfter: time.Duration((1 - b.tokens) * float64(perToken))}, nil
+	}
+
+	b.tokens--
+	b.full = now.Add(time.Duration((capacity - b.tokens) * float64(perToken)))
+	return Decision{Allowed: true}, nil
+}
+
+// sweep keeps the map from growing with every client ever seen; a forgotten bucket was full
+// anyway, which is how a new one starts.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
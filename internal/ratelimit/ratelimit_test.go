@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func take(t *testing.T, store *MemoryStore, key string, limit Limit) Decision {
+	t.Helper()
+	decision, err := store.Take(context.Background(), key, limit)
+	require.NoError(t, err)
+	return decision
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+
+	t.Run("burst then refill", func(t *testing.T) {
+		store, clock := newTestStore()
+		for i := 0; i < 3; i++ {
+			assert.True(t, take(t, store, "ip:1", limit).Allowed, "request %d", i)
+		}
+
+		denied := take(t, store, "ip:1", limit)
+		assert.False(t, denied.Allowed)
+		assert.Equal(t, time.Second, denied.RetryAfter)
+
+		clock.Advance(400 * time.Millisecond)
+		assert.Equal(t, 600*time.Millisecond, take(t, store, "ip:1", limit).RetryAfter)
+
+		clock.Advance(600 * time.Millisecond)
+		assert.True(t, take(t, store, "ip:1", limit).Allowed)
+		assert.False(t, take(t, store, "ip:1", limit).Allowed)
+	})
+
+	t.Run("keys are independent", func(t *testing.T) {
+		store, _ := newTestStore()
+		for i := 0; i < 3; i++ {
+			take(t, store, "user:a", limit)
+		}
+
+		assert.False(t, take(t, store, "user:a", limit).Allowed)
+		assert.True(t, take(t, store, "user:b", limit).Allowed)
+	})
+
+	t.Run("refill is capped", func(t *testing.T) {
+		store, clock := newTestStore()
+		take(t, store, "ip:1", limit)
+		clock.Advance(time.Hour)
+
+		for i := 0; i < 3; i++ {
+			assert.True(t, take(t, store, "ip:1", limit).Allowed)
+		}
+		assert.False(t, take(t, store, "ip:1", limit).Allowed)
+	})
+
+	t.Run("full buckets are swept", func(t *testing.T) {
+		store, clock := newTestStore()
+		take(t, store, "ip:1", limit)
+		take(t, store, "ip:2", Limit{Requests: 1, Period: time.Hour})
+
+		clock.Advance(2 * sweepInterval)
+		take(t, store, "ip:3", limit)
+
+		assert.NotContains(t, store.buckets, "ip:1")
+		assert.Contains(t, store.buckets, "ip:2", "still refilling")
+		assert.Contains(t, store.buckets, "ip:3")
+	})
+}
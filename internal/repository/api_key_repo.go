@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pvz/internal/models"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = "id, name, prefix, permissions, pvz_ids, created_by, created_at, expires_at, rotated_at, revoked_at"
+
+// APIKeyRepositoryInterface stores API keys by the hash of their secret. A rotated key is also found
+// by its previous secret until the rotation grace period ends.
+type APIKeyRepositoryInterface interface {
+	InsertAPIKey(ctx context.Context, key models.APIKey, keyHash string) (*models.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	RotateAPIKey(ctx context.Context, id uuid.UUID, keyHash, prefix string, previousExpiresAt time.Time) (*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// InsertAPIKey stores key with the given hash of its secret; ID and CreatedAt are set here.
+func (r *APIKeyRepository) InsertAPIKey(ctx context.Context, key models.APIKey, keyHash string) (*models.APIKey, error) {
+	// a nil slice would be NULL, not an empty array
+	pvzIDs := key.PVZIDs
+	if pvzIDs == nil {
+		pvzIDs = []uuid.UUID{}
+	}
+
+	query, args, err := sq.Insert("api_keys").
+		Columns("id", "name", "key_hash", "prefix", "permissions", "pvz_ids", "created_by", "expires_at").
+		Values(uuid.New(), key.Name, keyHash, key.Prefix, pq.Array(key.Permissions), pq.Array(pvzIDs), key.CreatedBy, key.ExpiresAt).
+		Suffix("RETURNING " + apiKeyColumns).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	inserted, err := scanAPIKey(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return inserted, nil
+}
+
+// GetAPIKeys returns all keys, expired and revoked ones included, newest first.
+func (r *APIKeyRepository) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	query, args, err := sq.Select(apiKeyColumns).
+		From("api_keys").
+		OrderBy("created_at DESC").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+
+	return keys, nil
+}
+
+// GetActiveAPIKeyByHash returns the unrevoked, unexpired key with this secret, or with this previous
+// secret while its grace period lasts. It returns nil without an error when there is none.
+func (r *APIKeyRepository) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query, args, err := sq.Select(apiKeyColumns).
+		From("api_keys").
+		Where(sq.Or{
+			sq.Eq{"key_hash": keyHash},
+			sq.And{sq.Eq{"previous_key_hash": keyHash}, sq.Expr("previous_key_expires_at > now()")},
+		}).
+		Where(sq.Eq{"revoked_at": nil}).
+		Where(sq.Or{sq.Eq{"expires_at": nil}, sq.Expr("expires_at > now()")}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return key, nil
+}
+
+// RotateAPIKey gives an unrevoked key a new secret. The current secret becomes the previous one and
+// keeps working until previousExpiresAt; a secret rotated out before is dropped.
+func (r *APIKeyRepository) RotateAPIKey(ctx context.Context, id uuid.UUID, keyHash, prefix string, previousExpiresAt time.Time) (*models.APIKey, error) {
+	query, args, err := sq.Update("api_keys").
+		Set("previous_key_hash", sq.Expr("key_hash")).
+		Set("previous_key_expires_at", previousExpiresAt).
+		Set("key_hash", keyHash).
+		Set("prefix", prefix).
+		Set("rotated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id, "revoked_at": nil}).
+		Suffix("RETURNING " + apiKeyColumns).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return key, nil
+}
+
+// RevokeAPIKey refuses the key's secrets from now on. The key stays listed, as receptions and products
+// record it as the acting user.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	query, args, err := sq.Update("api_keys").
+		Set("revoked_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id, "revoked_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error in rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	var createdBy uuid.NullUUID
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		pq.Array(&key.Permissions),
+		pq.Array(&key.PVZIDs),
+		&createdBy,
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.RotatedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	key.CreatedBy = createdBy.UUID
+	if key.PVZIDs == nil {
+		key.PVZIDs = []uuid.UUID{}
+	}
+	return &key, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"pvz/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	apiKeysInsertQuery = regexp.QuoteMeta(`INSERT INTO api_keys (id,name,key_hash,prefix,permissions,pvz_ids,created_by,expires_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id, name, prefix, permissions, pvz_ids, created_by, created_at, expires_at, rotated_at, revoked_at`)
+	apiKeysSelectQuery = regexp.QuoteMeta(`SELECT id, name, prefix, permissions, pvz_ids, created_by, created_at, expires_at, rotated_at, revoked_at FROM api_keys ORDER BY created_at DESC`)
+	apiKeysByHashQuery = regexp.QuoteMeta(`SELECT id, name, prefix, permissions, pvz_ids, created_by, created_at, expires_at, rotated_at, revoked_at FROM api_keys WHERE (key_hash = $1 OR (previous_key_hash = $2 AND previous_key_expires_at > now())) AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > now())`)
+	apiKeysRotateQuery = regexp.QuoteMeta(`UPDATE api_keys SET previous_key_hash = key_hash, previous_key_expires_at = $1, key_hash = $2, prefix = $3, rotated_at = now() WHERE id = $4 AND revoked_at IS NULL RETURNING id, name, prefix, permissions, pvz_ids, created_by, created_at, expires_at, rotated_at, revoked_at`)
+	apiKeysRevokeQuery = regexp.QuoteMeta(`UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`)
+)
+
+var apiKeyRowColumns = []string{"id", "name", "prefix", "permissions", "pvz_ids", "created_by", "created_at", "expires_at", "rotated_at", "revoked_at"}
+
+func TestAPIKeyRepository_InsertAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+	id, pvzID, createdBy := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	// a key without PVZs is stored with an empty array, which allows every PVZ
+	mock.ExpectQuery(apiKeysInsertQuery).
+		WithArgs(sqlmock.AnyArg(), "wms", "hash", "pvz_abcdefgh", `{"receptions:write"}`, "{}", createdBy, nil).
+		WillReturnRows(sqlmock.NewRows(apiKeyRowColumns).
+			AddRow(id, "wms", "pvz_abcdefgh", "{receptions:write}", "{}", createdBy, now, nil, nil, nil))
+
+	key, err := repo.InsertAPIKey(context.Background(), models.APIKey{
+		Name: "wms", Prefix: "pvz_abcdefgh", Permissions: []string{"receptions:write"}, CreatedBy: createdBy,
+	}, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, id, key.ID)
+	assert.Equal(t, []string{"receptions:write"}, key.Permissions)
+	assert.Equal(t, []uuid.UUID{}, key.PVZIDs)
+
+	mock.ExpectQuery(apiKeysInsertQuery).
+		WithArgs(sqlmock.AnyArg(), "wms", "hash", "pvz_abcdefgh", `{"receptions:write","products:write"}`, `{"`+pvzID.String()+`"}`, createdBy, now).
+		WillReturnRows(sqlmock.NewRows(apiKeyRowColumns).
+			AddRow(id, "wms", "pvz_abcdefgh", "{receptions:write,products:write}", "{"+pvzID.String()+"}", createdBy, now, now, nil, nil))
+
+	key, err = repo.InsertAPIKey(context.Background(), models.APIKey{
+		Name: "wms", Prefix: "pvz_abcdefgh", Permissions: []string{"receptions:write", "products:write"},
+		PVZIDs: []uuid.UUID{pvzID}, CreatedBy: createdBy, ExpiresAt: &now,
+	}, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{pvzID}, key.PVZIDs)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_GetAPIKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(apiKeysSelectQuery).
+		WillReturnRows(sqlmock.NewRows(apiKeyRowColumns).
+			AddRow(uuid.New(), "wms", "pvz_abcdefgh", "{products:write}", "{}", uuid.New(), now, nil, nil, nil).
+			AddRow(uuid.New(), "old", "pvz_hgfedcba", "{receptions:write}", "{}", nil, now.Add(-time.Hour), nil, nil, now))
+
+	keys, err := repo.GetAPIKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Nil(t, keys[0].RevokedAt)
+	assert.NotNil(t, keys[1].RevokedAt)
+	assert.Equal(t, uuid.Nil, keys[1].CreatedBy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_GetActiveAPIKeyByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+	id := uuid.New()
+
+	mock.ExpectQuery(apiKeysByHashQuery).WithArgs("hash", "hash").
+		WillReturnRows(sqlmock.NewRows(apiKeyRowColumns).
+			AddRow(id, "wms", "pvz_abcdefgh", "{products:write}", "{}", uuid.New(), time.Now(), nil, nil, nil))
+	key, err := repo.GetActiveAPIKeyByHash(context.Background(), "hash")
+	require.NoError(t, err)
+	assert.Equal(t, id, key.ID)
+
+	mock.ExpectQuery(apiKeysByHashQuery).WithArgs("unknown", "unknown").WillReturnError(sql.ErrNoRows)
+	key, err = repo.GetActiveAPIKeyByHash(context.Background(), "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, key)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_RotateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+	id := uuid.New()
+	now := time.Now()
+	graceUntil := now.Add(24 * time.Hour)
+
+	mock.ExpectQuery(apiKeysRotateQuery).WithArgs(graceUntil, "new-hash", "pvz_newnewne", id).
+		WillReturnRows(sqlmock.NewRows(apiKeyRowColumns).
+			AddRow(id, "wms", "pvz_newnewne", "{products:write}", "{}", uuid.New(), now, nil, now, nil))
+	key, err := repo.RotateAPIKey(context.Background(), id, "new-hash", "pvz_newnewne", graceUntil)
+	require.NoError(t, err)
+	assert.Equal(t, "pvz_newnewne", key.Prefix)
+	assert.NotNil(t, key.RotatedAt)
+
+	mock.ExpectQuery(apiKeysRotateQuery).WithArgs(graceUntil, "new-hash", "pvz_newnewne", id).WillReturnError(sql.ErrNoRows)
+	_, err = repo.RotateAPIKey(context.Background(), id, "new-hash", "pvz_newnewne", graceUntil)
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_RevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+	id := uuid.New()
+
+	mock.ExpectExec(apiKeysRevokeQuery).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.RevokeAPIKey(context.Background(), id))
+
+	// unknown and already revoked keys
+	mock.ExpectExec(apiKeysRevokeQuery).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.RevokeAPIKey(context.Background(), id), ErrAPIKeyNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
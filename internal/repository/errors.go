@@ -4,9 +4,26 @@ import "errors"
 
 var (
 	ErrUserExists            = errors.New("user already exists")
+	ErrUserNotFound          = errors.New("user not found")
 	ErrActiveReceptionExists = errors.New("active reception already exists")
 	ErrPVZNotFound           = errors.New("pvz not found")
 	ErrReceptionConflict     = errors.New("reception conflict")
 	ErrNoActiveReception     = errors.New("no active reception")
 	ErrEmptyReception        = errors.New("no products in reception")
 )
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+)
+
+var ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+
+var ErrResetTokenNotFound = errors.New("password reset token not found or expired")
+
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvalidInvitation  = errors.New("invitation is unknown, used, expired or issued for another email")
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
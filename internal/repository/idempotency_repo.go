@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"pvz/internal/models"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type IdempotencyRepositoryInterface interface {
+	Reserve(ctx context.Context, userID uuid.UUID, key, requestHash string, lockTTL time.Duration) (bool, error)
+	Get(ctx context.Context, userID uuid.UUID, key string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, userID uuid.UUID, key string, statusCode int, contentType string, body []byte, ttl time.Duration) error
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve claims the key for a new request for lockTTL. It returns false if the key is already taken by
+// an unexpired record; an expired record is overwritten, so keys can be reused after the TTL, and keys of
+// requests that never completed are freed once their lock runs out.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, userID uuid.UUID, key, requestHash string, lockTTL time.Duration) (bool, error) {
+	query, args, err := sq.Insert("idempotency_keys").
+		Columns("user_id", "key", "request_hash", "expires_at").
+		Values(userID, key, requestHash, sq.Expr("now() + make_interval(secs => ?)", lockTTL.Seconds())).
+		Suffix("ON CONFLICT (user_id, key) DO UPDATE SET " +
+			"request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = '', response_body = NULL, " +
+			"created_at = now(), expires_at = EXCLUDED.expires_at " +
+			"WHERE idempotency_keys.expires_at <= now() RETURNING key").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var reserved string
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(&reserved)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *IdempotencyRepository) Get(ctx context.Context, userID uuid.UUID, key string) (*models.IdempotencyRecord, error) {
+	query, args, err := sq.Select("user_id", "key", "request_hash", "status_code", "content_type", "response_body", "created_at", "expires_at").
+		From("idempotency_keys").
+		Where(sq.Eq{"user_id": userID, "key": key}).
+		Where(sq.Expr("expires_at > now()")).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var record models.IdempotencyRecord
+	var statusCode sql.NullInt64
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(
+		&record.UserID,
+		&record.Key,
+		&record.RequestHash,
+		&statusCode,
+		&record.ContentType,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if statusCode.Valid {
+		code := int(statusCode.Int64)
+		record.StatusCode = &code
+	}
+
+	return &record, nil
+}
+
+// Complete stores the response of a reserved key and keeps it for ttl.
+func (r *IdempotencyRepository) Complete(ctx context.Context, userID uuid.UUID, key string, statusCode int, contentType string, body []byte, ttl time.Duration) error {
+	query, args, err := sq.Update("idempotency_keys").
+		Set("status_code", statusCode).
+		Set("content_type", contentType).
+		Set("response_body", body).
+		Set("expires_at", sq.Expr("now() + make_interval(secs => ?)", ttl.Seconds())).
+		Where(sq.Eq{"user_id": userID, "key": key}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+// Release drops a reservation whose request did not complete, so that a retry is processed again.
+func (r *IdempotencyRepository) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	query, args, err := sq.Delete("idempotency_keys").
+		Where(sq.Eq{"user_id": userID, "key": key, "status_code": nil}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query, args, err := sq.Delete("idempotency_keys").
+		Where(sq.Expr("expires_at <= now()")).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return result.RowsAffected()
+}
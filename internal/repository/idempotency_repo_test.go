@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	idempotencyReserveQuery  = regexp.QuoteMeta(`INSERT INTO idempotency_keys (user_id,key,request_hash,expires_at) VALUES ($1,$2,$3,now() + make_interval(secs => $4)) ON CONFLICT (user_id, key) DO UPDATE SET`)
+	idempotencyGetQuery      = regexp.QuoteMeta(`SELECT user_id, key, request_hash, status_code, content_type, response_body, created_at, expires_at FROM idempotency_keys WHERE key = $1 AND user_id = $2 AND expires_at > now()`)
+	idempotencyCompleteQuery = regexp.QuoteMeta(`UPDATE idempotency_keys SET status_code = $1, content_type = $2, response_body = $3, expires_at = now() + make_interval(secs => $4) WHERE key = $5 AND user_id = $6`)
+	idempotencyReleaseQuery  = regexp.QuoteMeta(`DELETE FROM idempotency_keys WHERE key = $1 AND status_code IS NULL AND user_id = $2`)
+)
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(db)
+	userID := uuid.New()
+
+	t.Run("new key", func(t *testing.T) {
+		mock.ExpectQuery(idempotencyReserveQuery).
+			WithArgs(userID, "key-1", "hash", float64(3600)).
+			WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key-1"))
+
+		reserved, err := repo.Reserve(context.Background(), userID, "key-1", "hash", time.Hour)
+		assert.NoError(t, err)
+		assert.True(t, reserved)
+	})
+
+	t.Run("key already taken", func(t *testing.T) {
+		mock.ExpectQuery(idempotencyReserveQuery).
+			WithArgs(userID, "key-1", "hash", float64(3600)).
+			WillReturnError(sql.ErrNoRows)
+
+		reserved, err := repo.Reserve(context.Background(), userID, "key-1", "hash", time.Hour)
+		assert.NoError(t, err)
+		assert.False(t, reserved)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(db)
+	userID := uuid.New()
+	now := time.Now()
+	columns := []string{"user_id", "key", "request_hash", "status_code", "content_type", "response_body", "created_at", "expires_at"}
+
+	t.Run("completed request", func(t *testing.T) {
+		mock.ExpectQuery(idempotencyGetQuery).
+			WithArgs("key-1", userID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(userID, "key-1", "hash", 201, "application/json", []byte(`{"type":"обувь"}`), now, now.Add(time.Hour)))
+
+		record, err := repo.Get(context.Background(), userID, "key-1")
+		assert.NoError(t, err)
+		assert.Equal(t, 201, *record.StatusCode)
+		assert.Equal(t, `{"type":"обувь"}`, string(record.ResponseBody))
+	})
+
+	t.Run("request in progress", func(t *testing.T) {
+		mock.ExpectQuery(idempotencyGetQuery).
+			WithArgs("key-2", userID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(userID, "key-2", "hash", nil, "", nil, now, now.Add(time.Hour)))
+
+		record, err := repo.Get(context.Background(), userID, "key-2")
+		assert.NoError(t, err)
+		assert.Nil(t, record.StatusCode)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		mock.ExpectQuery(idempotencyGetQuery).
+			WithArgs("key-3", userID).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.Get(context.Background(), userID, "key-3")
+		assert.ErrorIs(t, err, ErrIdempotencyKeyNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_CompleteAndRelease(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectExec(idempotencyCompleteQuery).
+		WithArgs(201, "application/json", []byte(`{}`), float64(86400), "key-1", userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(idempotencyReleaseQuery).
+		WithArgs("key-2", userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.Complete(context.Background(), userID, "key-1", 201, "application/json", []byte(`{}`), 24*time.Hour))
+	assert.NoError(t, repo.Release(context.Background(), userID, "key-2"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pvz/internal/models"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+const invitationColumns = "id, email, created_by, created_at, expires_at, used_by, used_at"
+
+// InvitationRepositoryInterface stores moderator invitations by the hash of their code. They are
+// redeemed by UserRepository.InsertInvitedUser, together with the new account.
+type InvitationRepositoryInterface interface {
+	InsertInvitation(ctx context.Context, codeHash, email string, createdBy uuid.UUID, expiresAt time.Time) (*models.Invitation, error)
+	GetInvitations(ctx context.Context) ([]models.Invitation, error)
+	DeleteInvitation(ctx context.Context, id uuid.UUID) error
+}
+
+type InvitationRepository struct {
+	db *sql.DB
+}
+
+func NewInvitationRepository(db *sql.DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+func (r *InvitationRepository) InsertInvitation(ctx context.Context, codeHash, email string, createdBy uuid.UUID, expiresAt time.Time) (*models.Invitation, error) {
+	var restrictTo sql.NullString
+	if email != "" {
+		restrictTo = sql.NullString{String: email, Valid: true}
+	}
+
+	query, args, err := sq.Insert("invitations").
+		Columns("id", "code_hash", "email", "created_by", "expires_at").
+		Values(uuid.New(), codeHash, restrictTo, createdBy, expiresAt).
+		Suffix("RETURNING " + invitationColumns).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	invitation, err := scanInvitation(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return invitation, nil
+}
+
+// GetInvitations returns all invitations, used and expired ones included, newest first.
+func (r *InvitationRepository) GetInvitations(ctx context.Context) ([]models.Invitation, error) {
+	query, args, err := sq.Select(invitationColumns).
+		From("invitations").
+		OrderBy("created_at DESC").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		invitations = append(invitations, *invitation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// DeleteInvitation revokes an invitation; deleting a used one only drops it from the list.
+func (r *InvitationRepository) DeleteInvitation(ctx context.Context, id uuid.UUID) error {
+	query, args, err := sq.Delete("invitations").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error in rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+func scanInvitation(row interface{ Scan(dest ...any) error }) (*models.Invitation, error) {
+	var invitation models.Invitation
+	var email sql.NullString
+	err := row.Scan(
+		&invitation.ID,
+		&email,
+		&invitation.CreatedBy,
+		&invitation.CreatedAt,
+		&invitation.ExpiresAt,
+		&invitation.UsedBy,
+		&invitation.UsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	invitation.Email = email.String
+	return &invitation, nil
+}
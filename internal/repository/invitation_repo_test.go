@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	invitationsInsertQuery = regexp.QuoteMeta(`INSERT INTO invitations (id,code_hash,email,created_by,expires_at) VALUES ($1,$2,$3,$4,$5) RETURNING id, email, created_by, created_at, expires_at, used_by, used_at`)
+	invitationsSelectQuery = regexp.QuoteMeta(`SELECT id, email, created_by, created_at, expires_at, used_by, used_at FROM invitations ORDER BY created_at DESC`)
+	invitationsDeleteQuery = regexp.QuoteMeta(`DELETE FROM invitations WHERE id = $1`)
+)
+
+var invitationRowColumns = []string{"id", "email", "created_by", "created_at", "expires_at", "used_by", "used_at"}
+
+func TestInvitationRepository_InsertInvitation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewInvitationRepository(db)
+	id, createdBy := uuid.New(), uuid.New()
+	now := time.Now()
+	expiresAt := now.Add(72 * time.Hour)
+
+	// an invitation without an email is stored with NULL, so any email may use it
+	mock.ExpectQuery(invitationsInsertQuery).
+		WithArgs(sqlmock.AnyArg(), "hash", nil, createdBy, expiresAt).
+		WillReturnRows(sqlmock.NewRows(invitationRowColumns).AddRow(id, nil, createdBy, now, expiresAt, nil, nil))
+
+	invitation, err := repo.InsertInvitation(context.Background(), "hash", "", createdBy, expiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, id, invitation.ID)
+	assert.Empty(t, invitation.Email)
+	assert.Nil(t, invitation.UsedBy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvitationRepository_GetInvitations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewInvitationRepository(db)
+	usedBy := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(invitationsSelectQuery).
+		WillReturnRows(sqlmock.NewRows(invitationRowColumns).
+			AddRow(uuid.New(), "new@example.com", uuid.New(), now, now.Add(time.Hour), nil, nil).
+			AddRow(uuid.New(), nil, uuid.New(), now.Add(-time.Hour), now, usedBy, now))
+
+	invitations, err := repo.GetInvitations(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, invitations, 2)
+	assert.Equal(t, "new@example.com", invitations[0].Email)
+	assert.Equal(t, &usedBy, invitations[1].UsedBy)
+	assert.NotNil(t, invitations[1].UsedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvitationRepository_DeleteInvitation(t *testing.T) {
+	t.Run("deleted", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		id := uuid.New()
+		mock.ExpectExec(invitationsDeleteQuery).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, NewInvitationRepository(db).DeleteInvitation(context.Background(), id))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		id := uuid.New()
+		mock.ExpectExec(invitationsDeleteQuery).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, NewInvitationRepository(db).DeleteInvitation(context.Background(), id), ErrInvitationNotFound)
+	})
+}
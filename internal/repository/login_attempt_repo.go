@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pvz/internal/models"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type LoginAttemptRepositoryInterface interface {
+	InsertLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error
+	GetLoginAttempts(ctx context.Context, userID uuid.UUID, limit int) ([]models.LoginAttempt, error)
+}
+
+type LoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) InsertLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error {
+	var failureReason sql.NullString
+	if attempt.FailureReason != "" {
+		failureReason = sql.NullString{String: attempt.FailureReason, Valid: true}
+	}
+
+	query, args, err := sq.Insert("login_attempts").
+		Columns("id", "user_id", "email", "ip", "user_agent", "success", "failure_reason").
+		Values(uuid.New(), attempt.UserID, attempt.Email, attempt.IP, attempt.UserAgent, attempt.Success, failureReason).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// GetLoginAttempts returns the latest attempts of the user, newest first.
+func (r *LoginAttemptRepository) GetLoginAttempts(ctx context.Context, userID uuid.UUID, limit int) ([]models.LoginAttempt, error) {
+	query, args, err := sq.Select("id", "user_id", "email", "ip", "user_agent", "success", "failure_reason", "created_at").
+		From("login_attempts").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []models.LoginAttempt{}
+	for rows.Next() {
+		var attempt models.LoginAttempt
+		var failureReason sql.NullString
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.UserID,
+			&attempt.Email,
+			&attempt.IP,
+			&attempt.UserAgent,
+			&attempt.Success,
+			&failureReason,
+			&attempt.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		attempt.FailureReason = failureReason.String
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pvz/internal/models"
+	"slices"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type OutboxRepositoryInterface interface {
+	ClaimUnpublished(ctx context.Context, limit int, lease time.Duration) ([]models.Event, error)
+	MarkPublished(ctx context.Context, id uuid.UUID) error
+	MarkPublishFailed(ctx context.Context, id uuid.UUID, lastError string, retryAt *time.Time) error
+	ReleaseEvents(ctx context.Context, ids []uuid.UUID) error
+	GetEventsAfter(ctx context.Context, pvzID uuid.UUID, afterSeq int64, eventTypes []string, limit int) ([]models.Event, error)
+	GetLastEventSeq(ctx context.Context, pvzID uuid.UUID) (int64, error)
+}
+
+var eventColumns = []string{"seq", "id", "event_type", "pvz_id", "payload", "created_at"}
+
+// outboxConsumer names the columns that track one consumer's way through the outbox. Every consumer
+// claims, retries and sets aside events on its own, so one failing sink neither blocks nor repeats another.
+type outboxConsumer struct {
+	publishedAt string
+	lockedUntil string
+	attempts    string
+	lastError   string
+	failedAt    string
+}
+
+var (
+	publisherConsumer = outboxConsumer{
+		publishedAt: "published_at",
+		lockedUntil: "locked_until",
+		attempts:    "attempts",
+		lastError:   "last_error",
+		failedAt:    "failed_at",
+	}
+	webhooksConsumer = outboxConsumer{
+		publishedAt: "webhooks_enqueued_at",
+		lockedUntil: "webhooks_locked_until",
+		attempts:    "webhooks_attempts",
+		lastError:   "webhooks_last_error",
+		failedAt:    "webhooks_failed_at",
+	}
+)
+
+type OutboxRepository struct {
+	db       *sql.DB
+	consumer outboxConsumer
+}
+
+// NewOutboxRepository claims events for the configured publisher (OUTBOX_PUBLISHER).
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db, consumer: publisherConsumer}
+}
+
+// NewWebhookOutboxRepository claims events for enqueueing webhook deliveries, independently of the
+// configured publisher.
+func NewWebhookOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db, consumer: webhooksConsumer}
+}
+
+// insertEvent writes a domain event into the outbox inside the caller's transaction,
+// so the event is stored if and only if the business change is committed.
+func insertEvent(ctx context.Context, tx *sql.Tx, eventType string, pvzID uuid.UUID, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	query, args, err := sq.Insert("outbox").
+		Columns("id", "event_type", "pvz_id", "payload").
+		Values(uuid.New(), eventType, pvzID, data).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build outbox query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert outbox event: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimUnpublished leases up to limit events the consumer has not published, oldest first, so that they can be published
+// outside any transaction. Nothing is claimed while an earlier event is leased or waits for a retry,
+// which keeps events in order. The claimed events carry their Attempts so far.
+func (r *OutboxRepository) ClaimUnpublished(ctx context.Context, limit int, lease time.Duration) ([]models.Event, error) {
+	c := r.consumer
+	pending := sq.And{sq.Eq{c.publishedAt: nil}, sq.Eq{c.failedAt: nil}}
+	next := sq.Select("id").
+		From("outbox").
+		Where(pending).
+		OrderBy("seq").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+	busy := sq.Select("1").
+		From("outbox").
+		Where(pending).
+		Where(sq.Expr(c.lockedUntil + " > now()"))
+
+	query, args, err := sq.Update("outbox").
+		Set(c.lockedUntil, sq.Expr("now() + make_interval(secs => ?)", lease.Seconds())).
+		Where(sq.Expr("id IN (?)", next)).
+		Where(sq.Expr("NOT EXISTS (?)", busy)).
+		Suffix("RETURNING " + strings.Join(eventColumns, ", ") + ", " + c.attempts).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var event models.Event
+		if err := rows.Scan(append(eventScanDest(&event), &event.Attempts)...); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+
+	// RETURNING keeps no order
+	slices.SortFunc(events, func(a, b models.Event) int { return cmp.Compare(a.Seq, b.Seq) })
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	c := r.consumer
+	return r.update(ctx, sq.Update("outbox").
+		Set(c.publishedAt, sq.Expr("now()")).
+		Set(c.attempts, sq.Expr(c.attempts+" + 1")).
+		Set(c.lockedUntil, nil).
+		Where(sq.Eq{"id": id}))
+}
+
+// MarkPublishFailed records a failed attempt and holds the event until retryAt. A nil retryAt means no
+// retries are left: the event is set aside as failed and later events go on.
+func (r *OutboxRepository) MarkPublishFailed(ctx context.Context, id uuid.UUID, lastError string, retryAt *time.Time) error {
+	c := r.consumer
+	update := sq.Update("outbox").
+		Set(c.attempts, sq.Expr(c.attempts+" + 1")).
+		Set(c.lastError, lastError)
+	if retryAt == nil {
+		update = update.Set(c.failedAt, sq.Expr("now()")).Set(c.lockedUntil, nil)
+	} else {
+		update = update.Set(c.lockedUntil, *retryAt)
+	}
+	return r.update(ctx, update.Where(sq.Eq{"id": id}))
+}
+
+// ReleaseEvents ends the lease of claimed events that were not attempted.
+func (r *OutboxRepository) ReleaseEvents(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.update(ctx, sq.Update("outbox").
+		Set(r.consumer.lockedUntil, nil).
+		Where(sq.Eq{"id": ids}))
+}
+
+func (r *OutboxRepository) update(ctx context.Context, update sq.UpdateBuilder) error {
+	query, args, err := update.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update outbox event: %w", err)
+	}
+	return nil
+}
+
+// settledEvents keeps only events of transactions older than any still in progress. seq is taken on
+// insert, so a transaction that commits late could otherwise show an event behind one already streamed;
+// ordered by transaction, the settled events can no longer be joined by new ones before them.
+var settledEvents = sq.Expr("xact_id < pg_snapshot_xmin(pg_current_snapshot())")
+
+// GetEventsAfter returns settled events of the pvz that come after the event at afterSeq in stream
+// order, oldest first. An afterSeq of 0 starts from the beginning.
+func (r *OutboxRepository) GetEventsAfter(ctx context.Context, pvzID uuid.UUID, afterSeq int64, eventTypes []string, limit int) ([]models.Event, error) {
+	query, args, err := sq.Select(eventColumns...).
+		From("outbox").
+		Where(sq.Eq{"pvz_id": pvzID}).
+		Where(settledEvents).
+		Where(sq.Expr("(xact_id, seq) > (COALESCE((SELECT xact_id FROM outbox WHERE seq = ?), '0'::xid8), ?)", afterSeq, afterSeq)).
+		Where(sq.Eq{"event_type": eventTypes}).
+		OrderBy("xact_id", "seq").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var event models.Event
+		if err := rows.Scan(eventScanDest(&event)...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetLastEventSeq returns the position of the latest settled event of the pvz, or 0 if there are none.
+func (r *OutboxRepository) GetLastEventSeq(ctx context.Context, pvzID uuid.UUID) (int64, error) {
+	query, args, err := sq.Select("seq").
+		From("outbox").
+		Where(sq.Eq{"pvz_id": pvzID}).
+		Where(settledEvents).
+		OrderBy("xact_id DESC", "seq DESC").
+		Limit(1).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var seq int64
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(&seq)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return seq, nil
+}
+
+func eventScanDest(event *models.Event) []interface{} {
+	return []interface{}{&event.Seq, &event.ID, &event.Type, &event.PVZID, &event.Payload, &event.CreatedAt}
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"pvz/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	outboxInsertQuery    = regexp.QuoteMeta(`INSERT INTO outbox (id,event_type,pvz_id,payload) VALUES ($1,$2,$3,$4)`)
+	outboxClaimQuery     = regexp.QuoteMeta(`UPDATE outbox SET locked_until = now() + make_interval(secs => $1) WHERE id IN (SELECT id FROM outbox WHERE (published_at IS NULL AND failed_at IS NULL) ORDER BY seq LIMIT 10 FOR UPDATE SKIP LOCKED) AND NOT EXISTS (SELECT 1 FROM outbox WHERE (published_at IS NULL AND failed_at IS NULL) AND locked_until > now()) RETURNING seq, id, event_type, pvz_id, payload, created_at, attempts`)
+	outboxPublishedQuery = regexp.QuoteMeta(`UPDATE outbox SET published_at = now(), attempts = attempts + 1, locked_until = $1 WHERE id = $2`)
+	outboxRetryQuery     = regexp.QuoteMeta(`UPDATE outbox SET attempts = attempts + 1, last_error = $1, locked_until = $2 WHERE id = $3`)
+	outboxFailedQuery    = regexp.QuoteMeta(`UPDATE outbox SET attempts = attempts + 1, last_error = $1, failed_at = now(), locked_until = $2 WHERE id = $3`)
+	outboxReleaseQuery   = regexp.QuoteMeta(`UPDATE outbox SET locked_until = $1 WHERE id IN ($2,$3)`)
+	outboxAfterQuery     = regexp.QuoteMeta(`SELECT seq, id, event_type, pvz_id, payload, created_at FROM outbox WHERE pvz_id = $1 AND xact_id < pg_snapshot_xmin(pg_current_snapshot()) AND (xact_id, seq) > (COALESCE((SELECT xact_id FROM outbox WHERE seq = $2), '0'::xid8), $3) AND event_type IN ($4,$5) ORDER BY xact_id, seq LIMIT 100`)
+	outboxLastSeqQuery   = regexp.QuoteMeta(`SELECT seq FROM outbox WHERE pvz_id = $1 AND xact_id < pg_snapshot_xmin(pg_current_snapshot()) ORDER BY xact_id DESC, seq DESC LIMIT 1`)
+)
+
+func TestOutboxRepository_ClaimUnpublished_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	first, second := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(outboxClaimQuery).
+		WithArgs(float64(30)).
+		WillReturnRows(sqlmock.NewRows([]string{"seq", "id", "event_type", "pvz_id", "payload", "created_at", "attempts"}).
+			AddRow(int64(2), second, models.EventProductAdded, uuid.New(), []byte(`{}`), time.Now(), 0).
+			AddRow(int64(1), first, models.EventProductAdded, uuid.New(), []byte(`{}`), time.Now(), 3))
+
+	events, err := repo.ClaimUnpublished(context.Background(), 10, 30*time.Second)
+
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, first, events[0].ID)
+		assert.Equal(t, 3, events[0].Attempts)
+		assert.Equal(t, second, events[1].ID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_ClaimUnpublished_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+
+	mock.ExpectQuery(outboxClaimQuery).WillReturnError(errors.New("some db error"))
+
+	_, err = repo.ClaimUnpublished(context.Background(), 10, 30*time.Second)
+	assert.ErrorContains(t, err, "failed to claim outbox events")
+}
+
+func TestOutboxRepository_WebhookConsumer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// the webhook consumer claims and marks events in its own columns, apart from the publisher
+	repo := NewWebhookOutboxRepository(db)
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE outbox SET webhooks_locked_until = now() + make_interval(secs => $1) WHERE id IN (SELECT id FROM outbox WHERE (webhooks_enqueued_at IS NULL AND webhooks_failed_at IS NULL) ORDER BY seq LIMIT 10 FOR UPDATE SKIP LOCKED) AND NOT EXISTS (SELECT 1 FROM outbox WHERE (webhooks_enqueued_at IS NULL AND webhooks_failed_at IS NULL) AND webhooks_locked_until > now()) RETURNING seq, id, event_type, pvz_id, payload, created_at, webhooks_attempts`)).
+		WithArgs(float64(30)).
+		WillReturnRows(sqlmock.NewRows([]string{"seq", "id", "event_type", "pvz_id", "payload", "created_at", "webhooks_attempts"}).
+			AddRow(int64(1), id, models.EventProductAdded, uuid.New(), []byte(`{}`), time.Now(), 2))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox SET webhooks_enqueued_at = now(), webhooks_attempts = webhooks_attempts + 1, webhooks_locked_until = $1 WHERE id = $2`)).
+		WithArgs(nil, id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	events, err := repo.ClaimUnpublished(context.Background(), 10, 30*time.Second)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, 2, events[0].Attempts)
+	}
+	assert.NoError(t, repo.MarkPublished(context.Background(), id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_MarkPublished(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	id := uuid.New()
+
+	mock.ExpectExec(outboxPublishedQuery).WithArgs(nil, id).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.MarkPublished(context.Background(), id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_MarkPublishFailed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	id := uuid.New()
+	retryAt := time.Now().Add(time.Minute)
+
+	t.Run("retry", func(t *testing.T) {
+		mock.ExpectExec(outboxRetryQuery).WithArgs("broker is down", retryAt, id).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.MarkPublishFailed(context.Background(), id, "broker is down", &retryAt))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no retries left", func(t *testing.T) {
+		mock.ExpectExec(outboxFailedQuery).WithArgs("broker is down", nil, id).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.MarkPublishFailed(context.Background(), id, "broker is down", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestOutboxRepository_ReleaseEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	first, second := uuid.New(), uuid.New()
+
+	mock.ExpectExec(outboxReleaseQuery).WithArgs(nil, first, second).WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.ReleaseEvents(context.Background(), []uuid.UUID{first, second}))
+	assert.NoError(t, repo.ReleaseEvents(context.Background(), nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_GetEventsAfter_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	pvzID := uuid.New()
+
+	mock.ExpectQuery(outboxAfterQuery).
+		WithArgs(pvzID, int64(5), int64(5), models.EventProductAdded, models.EventReceptionClosed).
+		WillReturnRows(sqlmock.NewRows([]string{"seq", "id", "event_type", "pvz_id", "payload", "created_at"}).
+			AddRow(int64(6), uuid.New(), models.EventProductAdded, pvzID, []byte(`{}`), time.Now()))
+
+	events, err := repo.GetEventsAfter(context.Background(), pvzID, 5, []string{models.EventProductAdded, models.EventReceptionClosed}, 100)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, int64(6), events[0].Seq)
+		assert.Equal(t, models.EventProductAdded, events[0].Type)
+	}
+}
+
+func TestOutboxRepository_GetLastEventSeq_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	pvzID := uuid.New()
+
+	mock.ExpectQuery(outboxLastSeqQuery).
+		WithArgs(pvzID).
+		WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(int64(42)))
+
+	seq, err := repo.GetLastEventSeq(context.Background(), pvzID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), seq)
+}
+
+func TestOutboxRepository_GetLastEventSeq_NoEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	pvzID := uuid.New()
+
+	mock.ExpectQuery(outboxLastSeqQuery).
+		WithArgs(pvzID).
+		WillReturnRows(sqlmock.NewRows([]string{"seq"}))
+
+	seq, err := repo.GetLastEventSeq(context.Background(), pvzID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), seq)
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// PasswordResetRepositoryInterface stores password reset tokens by their hash, so a database
+// leak does not hand out working tokens. They are redeemed by UserRepository.ResetPasswordWithToken.
+type PasswordResetRepositoryInterface interface {
+	ReplaceResetToken(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error
+}
+
+type PasswordResetRepository struct {
+	db *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// ReplaceResetToken stores a new token of the user and drops the earlier ones, so only the latest
+// requested token works.
+func (r *PasswordResetRepository) ReplaceResetToken(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query, args, err := sq.Delete("password_reset_tokens").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	query, args, err = sq.Insert("password_reset_tokens").
+		Columns("token_hash", "user_id", "expires_at").
+		Values(tokenHash, userID, expiresAt).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
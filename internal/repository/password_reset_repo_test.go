@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	resetTokensDeleteQuery = regexp.QuoteMeta(`DELETE FROM password_reset_tokens WHERE user_id = $1`)
+	resetTokensInsertQuery = regexp.QuoteMeta(`INSERT INTO password_reset_tokens (token_hash,user_id,expires_at) VALUES ($1,$2,$3)`)
+)
+
+func TestPasswordResetRepository_ReplaceResetToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPasswordResetRepository(db)
+	userID := uuid.New()
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(resetTokensDeleteQuery).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(resetTokensInsertQuery).WithArgs("hash", userID, expiresAt).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.ReplaceResetToken(context.Background(), userID, "hash", expiresAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
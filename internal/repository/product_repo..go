@@ -12,8 +12,8 @@ import (
 )
 
 type ProductRepositoryInterface interface {
-	InsertProduct(ctx context.Context, productType string, pvzID uuid.UUID) (*models.Product, error)
-	DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error
+	InsertProduct(ctx context.Context, productType string, pvzID, userID uuid.UUID) (*models.Product, error)
+	DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) (*models.Product, error)
 }
 
 type ProductRepository struct {
@@ -24,7 +24,7 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-func (r *ProductRepository) InsertProduct(ctx context.Context, productType string, pvzID uuid.UUID) (*models.Product, error) {
+func (r *ProductRepository) InsertProduct(ctx context.Context, productType string, pvzID, userID uuid.UUID) (*models.Product, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -56,9 +56,9 @@ func (r *ProductRepository) InsertProduct(ctx context.Context, productType strin
 
 	id := uuid.New()
 	insertQuery, insertArgs, err := sq.Insert("products").
-		Columns("id, type, reception_id").
-		Values(id, productType, receptionID).
-		Suffix("RETURNING id, date_time, type, reception_id").
+		Columns("id, type, reception_id, created_by").
+		Values(id, productType, receptionID, userID).
+		Suffix("RETURNING id, date_time, type, reception_id, created_by").
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
@@ -72,12 +72,17 @@ func (r *ProductRepository) InsertProduct(ctx context.Context, productType strin
 		&product.DateTime,
 		&product.ProductType,
 		&product.ReceptionID,
+		&product.CreatedBy,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
+	if err := insertEvent(ctx, tx, models.EventProductAdded, pvzID, models.NewProductEventPayload(&product, pvzID)); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -85,10 +90,10 @@ func (r *ProductRepository) InsertProduct(ctx context.Context, productType strin
 	return &product, nil
 }
 
-func (r *ProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error {
+func (r *ProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) (*models.Product, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("begin transaction: %w", err)
+		return nil, fmt.Errorf("begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
@@ -103,16 +108,16 @@ func (r *ProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UU
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("build check reception query: %w", err)
+		return nil, fmt.Errorf("build check reception query: %w", err)
 	}
 
 	var receptionID uuid.UUID
 	err = tx.QueryRowContext(ctx, checkReceptionQuery, checkReceptionArgs...).Scan(&receptionID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrNoActiveReception
+			return nil, ErrNoActiveReception
 		}
-		return fmt.Errorf("get active reception: %w", err)
+		return nil, fmt.Errorf("get active reception: %w", err)
 	}
 
 	subQuery, subArgs, err := sq.
@@ -124,28 +129,34 @@ func (r *ProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UU
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("build subquery: %w", err)
+		return nil, fmt.Errorf("build subquery: %w", err)
 	}
 
-	deleteSQL := fmt.Sprintf("DELETE FROM products WHERE id = (%s)", subQuery)
+	deleteSQL := fmt.Sprintf("DELETE FROM products WHERE id = (%s) RETURNING id, date_time, type, reception_id, created_by", subQuery)
 
-	result, err := tx.ExecContext(ctx, deleteSQL, subArgs...)
+	var product models.Product
+	err = tx.QueryRowContext(ctx, deleteSQL, subArgs...).Scan(
+		&product.ID,
+		&product.DateTime,
+		&product.ProductType,
+		&product.ReceptionID,
+		&product.CreatedBy,
+	)
 	if err != nil {
-		return fmt.Errorf("delete product: %w", err)
+		// no deleted row means the reception has no products
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEmptyReception
+		}
+		return nil, fmt.Errorf("delete product: %w", err)
 	}
 
-	// check affected rows count
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error in rows affected: %w", err)
-	}
-	if rowsAffected == 0 {
-		return ErrEmptyReception
+	if err := insertEvent(ctx, tx, models.EventProductDeleted, pvzID, models.NewProductEventPayload(&product, pvzID)); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit transaction: %w", err)
+		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
 
-	return nil
+	return &product, nil
 }
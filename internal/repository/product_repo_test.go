@@ -15,9 +15,9 @@ import (
 
 var (
 	productSelectInInsertQuery = regexp.QuoteMeta(`SELECT id FROM receptions WHERE (pvz_id = $1 AND status = $2) LIMIT 1`)
-	productInsertQuery         = regexp.QuoteMeta(`INSERT INTO products (id, type, reception_id) VALUES ($1,$2,$3) RETURNING id, date_time, type, reception_id`)
+	productInsertQuery         = regexp.QuoteMeta(`INSERT INTO products (id, type, reception_id, created_by) VALUES ($1,$2,$3,$4) RETURNING id, date_time, type, reception_id, created_by`)
 	productSelectInDeleteQuery = regexp.QuoteMeta(`SELECT id FROM receptions WHERE (pvz_id = $1 AND status = $2) LIMIT 1`)
-	productDeleteQuery         = regexp.QuoteMeta(`DELETE FROM products WHERE id = (SELECT id FROM products WHERE reception_id = $1 ORDER BY date_time DESC LIMIT 1)`)
+	productDeleteQuery         = regexp.QuoteMeta(`DELETE FROM products WHERE id = (SELECT id FROM products WHERE reception_id = $1 ORDER BY date_time DESC LIMIT 1) RETURNING id, date_time, type, reception_id, created_by`)
 )
 
 func TestProductRepository_InsertProduct_Success(t *testing.T) {
@@ -29,6 +29,7 @@ func TestProductRepository_InsertProduct_Success(t *testing.T) {
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 	productID := uuid.New()
+	userID := uuid.New()
 	productType := "обувь"
 	now := time.Now()
 
@@ -39,17 +40,23 @@ func TestProductRepository_InsertProduct_Success(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(receptionID))
 
 	mock.ExpectQuery(productInsertQuery).
-		WithArgs(sqlmock.AnyArg(), productType, receptionID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "type", "reception_id"}).
-			AddRow(productID, now, productType, receptionID))
+		WithArgs(sqlmock.AnyArg(), productType, receptionID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "type", "reception_id", "created_by"}).
+			AddRow(productID, now, productType, receptionID, userID))
+
+	mock.ExpectExec(outboxInsertQuery).
+		WithArgs(sqlmock.AnyArg(), models.EventProductAdded, pvzID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	mock.ExpectCommit()
 
-	result, err := repo.InsertProduct(context.Background(), productType, pvzID)
+	result, err := repo.InsertProduct(context.Background(), productType, pvzID, userID)
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 	assert.NotNil(t, result)
 	assert.Equal(t, productID, result.ID)
 	assert.Equal(t, receptionID, result.ReceptionID)
+	assert.Equal(t, userID, result.CreatedBy)
 }
 
 func TestProductRepository_InsertProduct_NoActiveReception(t *testing.T) {
@@ -68,7 +75,7 @@ func TestProductRepository_InsertProduct_NoActiveReception(t *testing.T) {
 
 	mock.ExpectRollback()
 
-	_, err = repo.InsertProduct(context.Background(), "", pvzID)
+	_, err = repo.InsertProduct(context.Background(), "", pvzID, uuid.New())
 	assert.ErrorIs(t, err, ErrNoActiveReception)
 }
 
@@ -87,14 +94,23 @@ func TestProductRepository_DeleteLastProduct_Success(t *testing.T) {
 		WithArgs(pvzID, models.ReceptionStatusInProgress).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(receptionID))
 
-	mock.ExpectExec(productDeleteQuery).
+	mock.ExpectQuery(productDeleteQuery).
 		WithArgs(receptionID).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "type", "reception_id", "created_by"}).
+			AddRow(uuid.New(), time.Now(), "обувь", receptionID, uuid.New()))
+
+	mock.ExpectExec(outboxInsertQuery).
+		WithArgs(sqlmock.AnyArg(), models.EventProductDeleted, pvzID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	mock.ExpectCommit()
 
-	err = repo.DeleteLastProduct(context.Background(), pvzID)
+	product, err := repo.DeleteLastProduct(context.Background(), pvzID)
 	assert.NoError(t, err)
+	if assert.NotNil(t, product) {
+		assert.Equal(t, "обувь", product.ProductType)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestProductRepository_DeleteLastProduct_NoActiveReception(t *testing.T) {
@@ -113,7 +129,7 @@ func TestProductRepository_DeleteLastProduct_NoActiveReception(t *testing.T) {
 
 	mock.ExpectRollback()
 
-	err = repo.DeleteLastProduct(context.Background(), pvzID)
+	_, err = repo.DeleteLastProduct(context.Background(), pvzID)
 	assert.ErrorIs(t, err, ErrNoActiveReception)
 }
 
@@ -132,12 +148,12 @@ func TestProductRepository_DeleteLastProduct_EmptyReception(t *testing.T) {
 		WithArgs(pvzID, models.ReceptionStatusInProgress).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(receptionID))
 
-	mock.ExpectExec(productDeleteQuery).
+	mock.ExpectQuery(productDeleteQuery).
 		WithArgs(receptionID).
-		WillReturnResult(sqlmock.NewResult(0, 0))
+		WillReturnError(sql.ErrNoRows)
 
 	mock.ExpectRollback()
 
-	err = repo.DeleteLastProduct(context.Background(), pvzID)
+	_, err = repo.DeleteLastProduct(context.Background(), pvzID)
 	assert.ErrorIs(t, err, ErrEmptyReception)
 }
@@ -13,7 +13,9 @@ import (
 
 type PVZRepositoryInterface interface {
 	InsertPVZ(ctx context.Context, city string) (*models.PVZ, error)
-	GetPVZList(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]models.PVZWithReceptions, error)
+	GetPVZList(ctx context.Context, startDate, endDate *time.Time, createdBy, closedBy *uuid.UUID, page, limit int) ([]models.PVZWithReceptions, error)
+	GetPVZ(ctx context.Context, id uuid.UUID) (*models.PVZWithReceptions, error)
+	GetPVZCity(ctx context.Context, id uuid.UUID) (string, error)
 }
 
 type PVZRepository struct {
@@ -25,6 +27,12 @@ func NewPWZRepository(db *sql.DB) *PVZRepository {
 }
 
 func (p *PVZRepository) InsertPVZ(ctx context.Context, city string) (*models.PVZ, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	id := uuid.New()
 	query, args, err := sq.Insert("pvz").Columns("id, city").Values(id, city).Suffix("RETURNING id, registration_date, city").PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
@@ -32,33 +40,26 @@ func (p *PVZRepository) InsertPVZ(ctx context.Context, city string) (*models.PVZ
 	}
 
 	var pvz models.PVZ
-	err = p.db.QueryRowContext(ctx, query, args...).Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City)
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
+	if err := insertEvent(ctx, tx, models.EventPVZCreated, pvz.ID, pvz); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &pvz, nil
 }
 
-func (p *PVZRepository) GetPVZList(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]models.PVZWithReceptions, error) {
-	query := sq.Select(
-		"p.id AS pvz_id",
-		"p.registration_date",
-		"p.city",
-		"r.id AS reception_id",
-		"r.date_time AS reception_dateTime",
-		"r.status AS reception_status",
-		"pr.id AS product_id",
-		"pr.date_time AS product_dateTime",
-		"pr.type AS product_type",
-	).
-		From("pvz p").
-		LeftJoin("receptions r ON p.id = r.pvz_id").
-		LeftJoin("products pr ON r.id = pr.reception_id").
-		PlaceholderFormat(sq.Dollar).
+func (p *PVZRepository) GetPVZList(ctx context.Context, startDate, endDate *time.Time, createdBy, closedBy *uuid.UUID, page, limit int) ([]models.PVZWithReceptions, error) {
+	query := pvzWithReceptionsQuery().
 		Limit(uint64(limit)).
-		Offset(uint64((page-1)*limit)).
-		OrderBy("p.id", "r.date_time", "pr.date_time")
+		Offset(uint64((page - 1) * limit))
 
 	if startDate != nil {
 		query = query.Where(sq.GtOrEq{"r.date_time": *startDate})
@@ -66,6 +67,12 @@ func (p *PVZRepository) GetPVZList(ctx context.Context, startDate, endDate *time
 	if endDate != nil {
 		query = query.Where(sq.LtOrEq{"r.date_time": *endDate})
 	}
+	if createdBy != nil {
+		query = query.Where(sq.Eq{"r.created_by": *createdBy})
+	}
+	if closedBy != nil {
+		query = query.Where(sq.Eq{"r.closed_by": *closedBy})
+	}
 
 	sqlQuery, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
@@ -78,6 +85,74 @@ func (p *PVZRepository) GetPVZList(ctx context.Context, startDate, endDate *time
 	}
 	defer rows.Close()
 
+	return scanPVZWithReceptions(rows)
+}
+
+// GetPVZ returns a PVZ with all of its receptions and products.
+func (p *PVZRepository) GetPVZ(ctx context.Context, id uuid.UUID) (*models.PVZWithReceptions, error) {
+	sqlQuery, args, err := pvzWithReceptionsQuery().Where(sq.Eq{"p.id": id}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := p.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	result, err := scanPVZWithReceptions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, ErrPVZNotFound
+	}
+	return &result[0], nil
+}
+
+func (p *PVZRepository) GetPVZCity(ctx context.Context, id uuid.UUID) (string, error) {
+	query, args, err := sq.Select("city").From("pvz").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var city string
+	err = p.db.QueryRowContext(ctx, query, args...).Scan(&city)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrPVZNotFound
+		}
+		return "", fmt.Errorf("database error: %w", err)
+	}
+	return city, nil
+}
+
+// pvzWithReceptionsQuery selects one row per product, joined with its reception and PVZ;
+// PVZs without receptions and receptions without products come with NULL columns.
+func pvzWithReceptionsQuery() sq.SelectBuilder {
+	return sq.Select(
+		"p.id AS pvz_id",
+		"p.registration_date",
+		"p.city",
+		"r.id AS reception_id",
+		"r.date_time AS reception_dateTime",
+		"r.status AS reception_status",
+		"r.created_by AS reception_created_by",
+		"r.closed_by AS reception_closed_by",
+		"pr.id AS product_id",
+		"pr.date_time AS product_dateTime",
+		"pr.type AS product_type",
+		"pr.created_by AS product_created_by",
+	).
+		From("pvz p").
+		LeftJoin("receptions r ON p.id = r.pvz_id").
+		LeftJoin("products pr ON r.id = pr.reception_id").
+		PlaceholderFormat(sq.Dollar).
+		OrderBy("p.id", "r.date_time", "pr.date_time")
+}
+
+func scanPVZWithReceptions(rows *sql.Rows) ([]models.PVZWithReceptions, error) {
 	var rawResult []*models.PVZWithReceptions
 	pvzMap := make(map[uuid.UUID]*models.PVZWithReceptions)
 
@@ -89,9 +164,12 @@ func (p *PVZRepository) GetPVZList(ctx context.Context, startDate, endDate *time
 			receptionID       sql.NullString
 			receptionDateTime sql.NullTime
 			receptionStatus   sql.NullString
+			receptionCreator  uuid.NullUUID
+			receptionCloser   uuid.NullUUID
 			productID         sql.NullString
 			productDateTime   sql.NullTime
 			productType       sql.NullString
+			productCreator    uuid.NullUUID
 		)
 
 		err := rows.Scan(
@@ -101,9 +179,12 @@ func (p *PVZRepository) GetPVZList(ctx context.Context, startDate, endDate *time
 			&receptionID,
 			&receptionDateTime,
 			&receptionStatus,
+			&receptionCreator,
+			&receptionCloser,
 			&productID,
 			&productDateTime,
 			&productType,
+			&productCreator,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
@@ -138,15 +219,20 @@ func (p *PVZRepository) GetPVZList(ctx context.Context, startDate, endDate *time
 			if existingReception == nil {
 				newReception := models.ReceptionWithProducts{
 					Reception: models.Reception{
-						ID:       receptionUUID,
-						DateTime: receptionDateTime.Time,
-						PVZID:    pvzID,
-						Status:   receptionStatus.String,
+						ID:        receptionUUID,
+						DateTime:  receptionDateTime.Time,
+						PVZID:     pvzID,
+						Status:    receptionStatus.String,
+						CreatedBy: receptionCreator.UUID,
 					},
 					Products: []models.Product{},
 				}
+				if receptionCloser.Valid {
+					closedBy := receptionCloser.UUID
+					newReception.ClosedBy = &closedBy
+				}
 				pvzResp.Receptions = append(pvzResp.Receptions, newReception)
-				existingReception = &newReception
+				existingReception = &pvzResp.Receptions[len(pvzResp.Receptions)-1]
 			}
 
 			if productID.Valid {
@@ -159,6 +245,7 @@ func (p *PVZRepository) GetPVZList(ctx context.Context, startDate, endDate *time
 					DateTime:    productDateTime.Time,
 					ProductType: productType.String,
 					ReceptionID: receptionUUID,
+					CreatedBy:   productCreator.UUID,
 				}
 				existingReception.Products = append(existingReception.Products, product)
 			}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"pvz/internal/models"
 	"regexp"
 	"testing"
 	"time"
@@ -26,13 +27,22 @@ func TestPVZRepository_InsertPVZ_Success(t *testing.T) {
 	id := uuid.New()
 	registration := time.Now()
 
+	mock.ExpectBegin()
+
 	mock.ExpectQuery(pvzInsertQuery).
 		WithArgs(sqlmock.AnyArg(), city).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city"}).
 			AddRow(id, registration, city))
 
+	mock.ExpectExec(outboxInsertQuery).
+		WithArgs(sqlmock.AnyArg(), models.EventPVZCreated, id, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectCommit()
+
 	result, err := repo.InsertPVZ(context.Background(), city)
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 	assert.NotNil(t, result)
 	assert.Equal(t, city, result.City)
 	assert.Equal(t, id, result.ID)
@@ -47,10 +57,14 @@ func TestPVZRepository_InsertPVZ_DBError(t *testing.T) {
 	repo := NewPWZRepository(db)
 	city := "Kazan"
 
+	mock.ExpectBegin()
+
 	mock.ExpectQuery(pvzInsertQuery).
 		WithArgs(sqlmock.AnyArg(), city).
 		WillReturnError(sql.ErrConnDone)
 
+	mock.ExpectRollback()
+
 	_, err = repo.InsertPVZ(context.Background(), city)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "database error")
@@ -64,17 +78,146 @@ func TestPVZRepository_GetPVZList_EmptyResult(t *testing.T) {
 	repo := NewPWZRepository(db)
 
 	queryRegex := regexp.QuoteMeta(
-		`SELECT p.id AS pvz_id, p.registration_date, p.city, r.id AS reception_id, r.date_time AS reception_dateTime, r.status AS reception_status, pr.id AS product_id, pr.date_time AS product_dateTime, pr.type AS product_type FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id LEFT JOIN products pr ON r.id = pr.reception_id ORDER BY p.id, r.date_time, pr.date_time LIMIT 10 OFFSET 0`,
+		`SELECT p.id AS pvz_id, p.registration_date, p.city, r.id AS reception_id, r.date_time AS reception_dateTime, r.status AS reception_status, r.created_by AS reception_created_by, r.closed_by AS reception_closed_by, pr.id AS product_id, pr.date_time AS product_dateTime, pr.type AS product_type, pr.created_by AS product_created_by FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id LEFT JOIN products pr ON r.id = pr.reception_id ORDER BY p.id, r.date_time, pr.date_time LIMIT 10 OFFSET 0`,
 	)
 
 	mock.ExpectQuery(queryRegex).
 		WillReturnRows(sqlmock.NewRows([]string{
 			"pvz_id", "registration_date", "city",
-			"reception_id", "reception_dateTime", "reception_status",
-			"product_id", "product_dateTime", "product_type",
+			"reception_id", "reception_dateTime", "reception_status", "reception_created_by", "reception_closed_by",
+			"product_id", "product_dateTime", "product_type", "product_created_by",
 		}))
 
-	result, err := repo.GetPVZList(context.Background(), nil, nil, 1, 10)
+	result, err := repo.GetPVZList(context.Background(), nil, nil, nil, nil, 1, 10)
 	assert.NoError(t, err)
 	assert.Empty(t, result)
 }
+
+func TestPVZRepository_GetPVZList_FilterByCreator(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPWZRepository(db)
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	creatorID := uuid.New()
+	now := time.Now()
+
+	queryRegex := regexp.QuoteMeta(
+		`FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id LEFT JOIN products pr ON r.id = pr.reception_id WHERE r.created_by = $1 ORDER BY`,
+	)
+
+	mock.ExpectQuery(queryRegex).
+		WithArgs(creatorID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"pvz_id", "registration_date", "city",
+			"reception_id", "reception_dateTime", "reception_status", "reception_created_by", "reception_closed_by",
+			"product_id", "product_dateTime", "product_type", "product_created_by",
+		}).AddRow(
+			pvzID, now, "Москва",
+			receptionID.String(), now, "close", creatorID, creatorID,
+			nil, nil, nil, nil,
+		))
+
+	result, err := repo.GetPVZList(context.Background(), nil, nil, &creatorID, nil, 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, result, 1) && assert.Len(t, result[0].Receptions, 1) {
+		reception := result[0].Receptions[0]
+		assert.Equal(t, creatorID, reception.CreatedBy)
+		if assert.NotNil(t, reception.ClosedBy) {
+			assert.Equal(t, creatorID, *reception.ClosedBy)
+		}
+	}
+}
+
+func TestPVZRepository_GetPVZ(t *testing.T) {
+	columns := []string{
+		"pvz_id", "registration_date", "city",
+		"reception_id", "reception_dateTime", "reception_status", "reception_created_by", "reception_closed_by",
+		"product_id", "product_dateTime", "product_type", "product_created_by",
+	}
+	queryRegex := regexp.QuoteMeta(
+		`FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id LEFT JOIN products pr ON r.id = pr.reception_id WHERE p.id = $1 ORDER BY p.id, r.date_time, pr.date_time`,
+	)
+
+	t.Run("with receptions and products", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewPWZRepository(db)
+		pvzID := uuid.New()
+		receptionID := uuid.New()
+		userID := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery(queryRegex).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(pvzID, now, "Казань", receptionID.String(), now, "in_progress", userID, nil, uuid.New().String(), now, "обувь", userID).
+				AddRow(pvzID, now, "Казань", receptionID.String(), now, "in_progress", userID, nil, uuid.New().String(), now, "одежда", userID))
+
+		pvz, err := repo.GetPVZ(context.Background(), pvzID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, pvz) && assert.Len(t, pvz.Receptions, 1) {
+			assert.Equal(t, pvzID, pvz.ID)
+			assert.Len(t, pvz.Receptions[0].Products, 2)
+			assert.Nil(t, pvz.Receptions[0].ClosedBy)
+		}
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewPWZRepository(db)
+		pvzID := uuid.New()
+
+		mock.ExpectQuery(queryRegex).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		pvz, err := repo.GetPVZ(context.Background(), pvzID)
+		assert.Nil(t, pvz)
+		assert.ErrorIs(t, err, ErrPVZNotFound)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPVZRepository_GetPVZCity(t *testing.T) {
+	query := regexp.QuoteMeta(`SELECT city FROM pvz WHERE id = $1`)
+
+	t.Run("found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewPWZRepository(db)
+		id := uuid.New()
+
+		mock.ExpectQuery(query).WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"city"}).AddRow("Казань"))
+
+		city, err := repo.GetPVZCity(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, "Казань", city)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewPWZRepository(db)
+		id := uuid.New()
+
+		mock.ExpectQuery(query).WithArgs(id).WillReturnError(sql.ErrNoRows)
+
+		_, err = repo.GetPVZCity(context.Background(), id)
+		assert.ErrorIs(t, err, ErrPVZNotFound)
+	})
+}
@@ -12,8 +12,9 @@ import (
 )
 
 type ReceptionRepositoryInterface interface {
-	InsertReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error)
-	UpdateLastReceptionStatus(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error)
+	InsertReception(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error)
+	UpdateLastReceptionStatus(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error)
+	CountOpenReceptions(ctx context.Context) (map[string]int, error)
 }
 
 type ReceptionRepository struct {
@@ -24,7 +25,7 @@ func NewReceptionRepository(db *sql.DB) *ReceptionRepository {
 	return &ReceptionRepository{db: db}
 }
 
-func (r *ReceptionRepository) InsertReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
+func (r *ReceptionRepository) InsertReception(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -60,9 +61,9 @@ func (r *ReceptionRepository) InsertReception(ctx context.Context, pvzID uuid.UU
 
 	id := uuid.New()
 	insertQuery, insertArgs, err := sq.Insert("receptions").
-		Columns("id, pvz_id").
-		Values(id, pvzID).
-		Suffix("RETURNING id, date_time, pvz_id, status").
+		Columns("id, pvz_id, created_by").
+		Values(id, pvzID, userID).
+		Suffix("RETURNING id, date_time, pvz_id, status, created_by, closed_by").
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
@@ -76,6 +77,8 @@ func (r *ReceptionRepository) InsertReception(ctx context.Context, pvzID uuid.UU
 		&reception.DateTime,
 		&reception.PVZID,
 		&reception.Status,
+		&reception.CreatedBy,
+		&reception.ClosedBy,
 	)
 
 	if err != nil {
@@ -86,6 +89,10 @@ func (r *ReceptionRepository) InsertReception(ctx context.Context, pvzID uuid.UU
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
+	if err := insertEvent(ctx, tx, models.EventReceptionCreated, reception.PVZID, models.NewReceptionEventPayload(&reception)); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -93,21 +100,28 @@ func (r *ReceptionRepository) InsertReception(ctx context.Context, pvzID uuid.UU
 	return &reception, nil
 }
 
-func (r *ReceptionRepository) UpdateLastReceptionStatus(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
+func (r *ReceptionRepository) UpdateLastReceptionStatus(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query, args, err := sq.
 		Update("receptions").
 		Set("status", models.ReceptionStatusClosed).
+		Set("closed_by", userID).
 		Where(`
             id = (
                 SELECT id FROM receptions 
-                WHERE pvz_id = $2 AND status = $3
+                WHERE pvz_id = $3 AND status = $4
                 ORDER BY date_time DESC 
                 LIMIT 1
             )`,
 			pvzID,
 			models.ReceptionStatusInProgress,
 		).
-		Suffix("RETURNING id, date_time, pvz_id, status").
+		Suffix("RETURNING id, date_time, pvz_id, status, created_by, closed_by").
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
@@ -116,11 +130,13 @@ func (r *ReceptionRepository) UpdateLastReceptionStatus(ctx context.Context, pvz
 	}
 
 	var reception models.Reception
-	err = r.db.QueryRowContext(ctx, query, args...).Scan(
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
 		&reception.ID,
 		&reception.DateTime,
 		&reception.PVZID,
 		&reception.Status,
+		&reception.CreatedBy,
+		&reception.ClosedBy,
 	)
 
 	if err != nil {
@@ -130,5 +146,47 @@ func (r *ReceptionRepository) UpdateLastReceptionStatus(ctx context.Context, pvz
 		return nil, fmt.Errorf("execute update: %w", err)
 	}
 
+	if err := insertEvent(ctx, tx, models.EventReceptionClosed, reception.PVZID, models.NewReceptionEventPayload(&reception)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &reception, nil
 }
+
+// CountOpenReceptions returns the number of in-progress receptions per city.
+func (r *ReceptionRepository) CountOpenReceptions(ctx context.Context) (map[string]int, error) {
+	query, args, err := sq.Select("p.city", "count(*)").
+		From("receptions r").
+		Join("pvz p ON p.id = r.pvz_id").
+		Where(sq.Eq{"r.status": models.ReceptionStatusInProgress}).
+		GroupBy("p.city").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var city string
+		var count int
+		if err := rows.Scan(&city, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		counts[city] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+	return counts, nil
+}
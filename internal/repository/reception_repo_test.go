@@ -18,8 +18,8 @@ import (
 
 var (
 	receptionSelectInInsertQuery = regexp.QuoteMeta(`SELECT 1 FROM receptions WHERE (pvz_id = $1 AND status = $2) LIMIT 1`)
-	receptionInsertQuery         = regexp.QuoteMeta(`INSERT INTO receptions (id, pvz_id) VALUES ($1,$2) RETURNING id, date_time, pvz_id, status`)
-	receptionUpdateQuery         = regexp.QuoteMeta(`UPDATE receptions SET status = $1 WHERE id = ( SELECT id FROM receptions WHERE pvz_id = $2 AND status = $3 ORDER BY date_time DESC LIMIT 1 ) RETURNING id, date_time, pvz_id, status`)
+	receptionInsertQuery         = regexp.QuoteMeta(`INSERT INTO receptions (id, pvz_id, created_by) VALUES ($1,$2,$3) RETURNING id, date_time, pvz_id, status, created_by, closed_by`)
+	receptionUpdateQuery         = regexp.QuoteMeta(`UPDATE receptions SET status = $1, closed_by = $2 WHERE id = ( SELECT id FROM receptions WHERE pvz_id = $3 AND status = $4 ORDER BY date_time DESC LIMIT 1 ) RETURNING id, date_time, pvz_id, status, created_by, closed_by`)
 )
 
 func TestReceptionRepository_InsertReception_Success(t *testing.T) {
@@ -29,6 +29,7 @@ func TestReceptionRepository_InsertReception_Success(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 	pvzID := uuid.New()
+	userID := uuid.New()
 	id := uuid.New()
 	now := time.Now()
 
@@ -39,18 +40,25 @@ func TestReceptionRepository_InsertReception_Success(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 	mock.ExpectQuery(receptionInsertQuery).
-		WithArgs(sqlmock.AnyArg(), pvzID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
-			AddRow(id, now, pvzID, models.ReceptionStatusInProgress))
+		WithArgs(sqlmock.AnyArg(), pvzID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by", "closed_by"}).
+			AddRow(id, now, pvzID, models.ReceptionStatusInProgress, userID, nil))
+
+	mock.ExpectExec(outboxInsertQuery).
+		WithArgs(sqlmock.AnyArg(), models.EventReceptionCreated, pvzID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	mock.ExpectCommit()
 
-	result, err := repo.InsertReception(context.Background(), pvzID)
+	result, err := repo.InsertReception(context.Background(), pvzID, userID)
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 	assert.NotNil(t, result)
 	assert.Equal(t, id, result.ID)
 	assert.Equal(t, pvzID, result.PVZID)
 	assert.Equal(t, models.ReceptionStatusInProgress, result.Status)
+	assert.Equal(t, userID, result.CreatedBy)
+	assert.Nil(t, result.ClosedBy)
 }
 
 func TestReceptionRepository_InsertReception_ActiveReceptionExists(t *testing.T) {
@@ -60,6 +68,7 @@ func TestReceptionRepository_InsertReception_ActiveReceptionExists(t *testing.T)
 
 	repo := NewReceptionRepository(db)
 	pvzID := uuid.New()
+	userID := uuid.New()
 
 	mock.ExpectBegin()
 
@@ -69,7 +78,7 @@ func TestReceptionRepository_InsertReception_ActiveReceptionExists(t *testing.T)
 
 	mock.ExpectRollback()
 
-	_, err = repo.InsertReception(context.Background(), pvzID)
+	_, err = repo.InsertReception(context.Background(), pvzID, userID)
 	assert.ErrorIs(t, err, ErrActiveReceptionExists)
 }
 
@@ -80,6 +89,7 @@ func TestReceptionRepository_InsertReception_PVZNotFound(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 	pvzID := uuid.New()
+	userID := uuid.New()
 
 	mock.ExpectBegin()
 
@@ -88,12 +98,12 @@ func TestReceptionRepository_InsertReception_PVZNotFound(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 	mock.ExpectQuery(receptionInsertQuery).
-		WithArgs(sqlmock.AnyArg(), pvzID).
+		WithArgs(sqlmock.AnyArg(), pvzID, userID).
 		WillReturnError(&pq.Error{Code: "23503"})
 
 	mock.ExpectRollback()
 
-	_, err = repo.InsertReception(context.Background(), pvzID)
+	_, err = repo.InsertReception(context.Background(), pvzID, userID)
 	assert.ErrorIs(t, err, ErrPVZNotFound)
 }
 
@@ -104,6 +114,7 @@ func TestReceptionRepository_InsertReception_DBerrorInSelect(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 	pvzID := uuid.New()
+	userID := uuid.New()
 
 	mock.ExpectBegin()
 
@@ -113,7 +124,7 @@ func TestReceptionRepository_InsertReception_DBerrorInSelect(t *testing.T) {
 
 	mock.ExpectRollback()
 
-	_, err = repo.InsertReception(context.Background(), pvzID)
+	_, err = repo.InsertReception(context.Background(), pvzID, userID)
 	assert.ErrorContains(t, err, "failed to check active reception:")
 }
 
@@ -124,6 +135,7 @@ func TestReceptionRepository_InsertReception_DBerrorInInsert(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 	pvzID := uuid.New()
+	userID := uuid.New()
 
 	mock.ExpectBegin()
 
@@ -132,12 +144,12 @@ func TestReceptionRepository_InsertReception_DBerrorInInsert(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 	mock.ExpectQuery(receptionInsertQuery).
-		WithArgs(sqlmock.AnyArg(), pvzID).
+		WithArgs(sqlmock.AnyArg(), pvzID, userID).
 		WillReturnError(errors.New("some db error"))
 
 	mock.ExpectRollback()
 
-	_, err = repo.InsertReception(context.Background(), pvzID)
+	_, err = repo.InsertReception(context.Background(), pvzID, userID)
 	assert.ErrorContains(t, err, "database error:")
 }
 
@@ -148,12 +160,17 @@ func TestReceptionRepository_UpdateLastReceptionStatus_NoActiveReception(t *test
 
 	repo := NewReceptionRepository(db)
 	pvzID := uuid.New()
+	userID := uuid.New()
+
+	mock.ExpectBegin()
 
 	mock.ExpectQuery(receptionUpdateQuery).
-		WithArgs(models.ReceptionStatusClosed, pvzID, models.ReceptionStatusInProgress).
+		WithArgs(models.ReceptionStatusClosed, userID, pvzID, models.ReceptionStatusInProgress).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err = repo.UpdateLastReceptionStatus(context.Background(), pvzID)
+	mock.ExpectRollback()
+
+	_, err = repo.UpdateLastReceptionStatus(context.Background(), pvzID, userID)
 	assert.ErrorIs(t, err, ErrNoActiveReception)
 }
 
@@ -164,12 +181,17 @@ func TestReceptionRepository_UpdateLastReceptionStatus_DBError(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 	pvzID := uuid.New()
+	userID := uuid.New()
 
-	mock.ExpectQuery(receptionInsertQuery).
-		WithArgs(models.ReceptionStatusClosed, pvzID, models.ReceptionStatusInProgress).
+	mock.ExpectBegin()
+
+	mock.ExpectQuery(receptionUpdateQuery).
+		WithArgs(models.ReceptionStatusClosed, userID, pvzID, models.ReceptionStatusInProgress).
 		WillReturnError(errors.New("some db error"))
 
-	_, err = repo.UpdateLastReceptionStatus(context.Background(), pvzID)
+	mock.ExpectRollback()
+
+	_, err = repo.UpdateLastReceptionStatus(context.Background(), pvzID, userID)
 	assert.ErrorContains(t, err, "execute update")
 }
 
@@ -180,17 +202,47 @@ func TestReceptionRepository_UpdateLastReceptionStatus_Success(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 	pvzID := uuid.New()
+	userID := uuid.New()
 	id := uuid.New()
 	now := time.Now()
 
+	mock.ExpectBegin()
+
 	mock.ExpectQuery(receptionUpdateQuery).
-		WithArgs(models.ReceptionStatusClosed, pvzID, models.ReceptionStatusInProgress).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
-			AddRow(id, now, pvzID, models.ReceptionStatusClosed))
+		WithArgs(models.ReceptionStatusClosed, userID, pvzID, models.ReceptionStatusInProgress).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by", "closed_by"}).
+			AddRow(id, now, pvzID, models.ReceptionStatusClosed, userID, userID))
 
-	result, err := repo.UpdateLastReceptionStatus(context.Background(), pvzID)
+	mock.ExpectExec(outboxInsertQuery).
+		WithArgs(sqlmock.AnyArg(), models.EventReceptionClosed, pvzID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectCommit()
+
+	result, err := repo.UpdateLastReceptionStatus(context.Background(), pvzID, userID)
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 	assert.NotNil(t, result)
 	assert.Equal(t, models.ReceptionStatusClosed, result.Status)
 	assert.Equal(t, pvzID, result.PVZID)
+	if assert.NotNil(t, result.ClosedBy) {
+		assert.Equal(t, userID, *result.ClosedBy)
+	}
+}
+
+func TestReceptionRepository_CountOpenReceptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT p.city, count(*) FROM receptions r JOIN pvz p ON p.id = r.pvz_id WHERE r.status = $1 GROUP BY p.city`)).
+		WithArgs(models.ReceptionStatusInProgress).
+		WillReturnRows(sqlmock.NewRows([]string{"city", "count"}).AddRow("Москва", 3).AddRow("Казань", 1))
+
+	counts, err := repo.CountOpenReceptions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"Москва": 3, "Казань": 1}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
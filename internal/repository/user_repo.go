@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 	"pvz/internal/models"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
@@ -14,9 +16,36 @@ import (
 
 type UserRepositoryInterface interface {
 	InsertUser(ctx context.Context, email, password, role string) (*models.User, error)
+	InsertInvitedUser(ctx context.Context, email, password, role, invitationHash string) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, password string) error
+	ResetPasswordWithToken(ctx context.Context, tokenHash, password string) error
+	RecordLoginFailure(ctx context.Context, id uuid.UUID, maxAttempts int, lockUntil time.Time) (*time.Time, error)
+	ResetLoginFailures(ctx context.Context, id uuid.UUID) error
+	GetUsers(ctx context.Context, filter UserFilter, page, limit int) ([]models.User, error)
+	UpdateRole(ctx context.Context, id uuid.UUID, role string) error
+	SetDeactivated(ctx context.Context, id uuid.UUID, deactivated bool) error
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+	GetAssignedPVZs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	AssignPVZ(ctx context.Context, id, pvzID uuid.UUID) error
+	UnassignPVZ(ctx context.Context, id, pvzID uuid.UUID) error
 }
 
+// UserFilter narrows GetUsers down; zero fields match every user.
+type UserFilter struct {
+	Role string
+	// Email matches any part of the email, case-insensitively.
+	Email string
+	// Deactivated selects deactivated (true) or active (false) users.
+	Deactivated *bool
+}
+
+var userColumns = []string{"id", "email", "password", "role", "failed_login_attempts", "locked_until", "deactivated_at", "tokens_revoked_at"}
+
+// likeEscaper escapes the LIKE wildcards, so a filter value matches only itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -26,42 +55,108 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 }
 
 func (ur *UserRepository) InsertUser(ctx context.Context, email, password, role string) (*models.User, error) {
-	id := uuid.New()
+	user, query, args, err := buildUserInsert(email, password, role)
+	if err != nil {
+		return nil, err
+	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if _, err := ur.db.ExecContext(ctx, query, args...); err != nil {
+		return nil, userInsertError(err)
+	}
+	return user, nil
+}
+
+// InsertInvitedUser creates the user and redeems the invitation in one transaction, so an invitation
+// is used up only by an account that exists, and never twice.
+func (ur *UserRepository) InsertInvitedUser(ctx context.Context, email, password, role, invitationHash string) (*models.User, error) {
+	user, query, args, err := buildUserInsert(email, password, role)
 	if err != nil {
 		return nil, err
 	}
 
-	query, args, err := sq.Insert("users").Columns("id", "email", "password", "role").Values(id, email, hashedPassword, role).PlaceholderFormat(sq.Dollar).ToSql()
+	tx, err := ur.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, userInsertError(err)
+	}
+
+	query, args, err = sq.Update("invitations").
+		Set("used_by", user.ID).
+		Set("used_at", sq.Expr("now()")).
+		Where(sq.Eq{"code_hash": invitationHash, "used_at": nil}).
+		Where(sq.Expr("expires_at > now()")).
+		Where(sq.Or{sq.Eq{"email": nil}, sq.Eq{"email": email}}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	_, err = ur.db.ExecContext(ctx, query, args...)
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
-		// check for 23505 error (unique_violation) in PostgreSQL
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			return nil, ErrUserExists
-		}
 		return nil, fmt.Errorf("database error: %w", err)
 	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error in rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrInvalidInvitation
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return user, nil
+}
+
+// buildUserInsert hashes the password and builds the INSERT of a new user.
+func buildUserInsert(email, password, role string) (*models.User, string, []interface{}, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
 	user := &models.User{
-		ID:    id,
+		ID:    uuid.New(),
 		Email: email,
 		Role:  role,
 	}
-	return user, nil
+	query, args, err := sq.Insert("users").Columns("id", "email", "password", "role").Values(user.ID, email, hashedPassword, role).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	return user, query, args, nil
+}
+
+func userInsertError(err error) error {
+	// check for 23505 error (unique_violation) in PostgreSQL
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return ErrUserExists
+	}
+	return fmt.Errorf("database error: %w", err)
 }
 
 func (ur *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	query, args, err := sq.Select("*").From("users").Where(sq.Eq{"email": email}).PlaceholderFormat(sq.Dollar).ToSql()
+	return ur.getUser(ctx, sq.Eq{"email": email})
+}
+
+func (ur *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return ur.getUser(ctx, sq.Eq{"id": id})
+}
+
+// getUser returns nil without an error when no user matches.
+func (ur *UserRepository) getUser(ctx context.Context, where sq.Eq) (*models.User, error) {
+	query, args, err := sq.Select(userColumns...).From("users").Where(where).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	var user models.User
-	err = ur.db.QueryRowContext(ctx, query, args...).Scan(&user.ID, &user.Email, &user.Password, &user.Role)
+	user, err := scanUser(ur.db.QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -69,5 +164,302 @@ func (ur *UserRepository) GetUserByEmail(ctx context.Context, email string) (*mo
 		return nil, err
 	}
 
+	return user, nil
+}
+
+// GetUsers returns a page of the users that match the filter, ordered by email.
+func (ur *UserRepository) GetUsers(ctx context.Context, filter UserFilter, page, limit int) ([]models.User, error) {
+	builder := sq.Select(userColumns...).From("users")
+	if filter.Role != "" {
+		builder = builder.Where(sq.Eq{"role": filter.Role})
+	}
+	if filter.Email != "" {
+		builder = builder.Where(sq.ILike{"email": "%" + likeEscaper.Replace(filter.Email) + "%"})
+	}
+	if filter.Deactivated != nil {
+		if *filter.Deactivated {
+			builder = builder.Where(sq.NotEq{"deactivated_at": nil})
+		} else {
+			builder = builder.Where(sq.Eq{"deactivated_at": nil})
+		}
+	}
+
+	query, args, err := builder.
+		OrderBy("email").
+		Limit(uint64(limit)).
+		Offset(uint64((page - 1) * limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := ur.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		users = append(users, *user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+
+	return users, nil
+}
+
+func scanUser(row interface{ Scan(dest ...any) error }) (*models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&user.FailedLoginAttempts,
+		&user.LockedUntil,
+		&user.DeactivatedAt,
+		&user.TokensRevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
 	return &user, nil
 }
+
+// UpdateRole changes the role and revokes the user's tokens, which still carry the old role.
+func (ur *UserRepository) UpdateRole(ctx context.Context, id uuid.UUID, role string) error {
+	return ur.updateUser(ctx, id, sq.Update("users").
+		Set("role", role).
+		Set("tokens_revoked_at", sq.Expr("now()")))
+}
+
+// SetDeactivated deactivates the account and revokes its tokens, or reactivates it. Tokens issued
+// before a deactivation stay revoked after the reactivation.
+func (ur *UserRepository) SetDeactivated(ctx context.Context, id uuid.UUID, deactivated bool) error {
+	builder := sq.Update("users").Set("deactivated_at", nil)
+	if deactivated {
+		builder = sq.Update("users").
+			Set("deactivated_at", sq.Expr("COALESCE(deactivated_at, now())")).
+			Set("tokens_revoked_at", sq.Expr("now()"))
+	}
+	return ur.updateUser(ctx, id, builder)
+}
+
+// DeleteUser removes the account together with its login history and password reset tokens.
+func (ur *UserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	query, args, err := sq.Delete("users").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	return ur.execOnUser(ctx, query, args)
+}
+
+func (ur *UserRepository) updateUser(ctx context.Context, id uuid.UUID, builder sq.UpdateBuilder) error {
+	query, args, err := builder.Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	return ur.execOnUser(ctx, query, args)
+}
+
+// execOnUser runs a statement that must affect the user, and reports ErrUserNotFound when it does not.
+func (ur *UserRepository) execOnUser(ctx context.Context, query string, args []interface{}) error {
+	result, err := ur.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdatePassword sets a new password and revokes the user's tokens, so whoever knew the old password
+// is signed out too.
+func (ur *UserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return ur.updateUser(ctx, id, passwordUpdate(hashedPassword))
+}
+
+// ResetPasswordWithToken redeems an unexpired password reset token and sets the new password of its
+// user in one transaction, so the token is used up only by a password that was set. Like UpdatePassword
+// it revokes the user's tokens, and it lifts a lockout: the token proves the user owns the email.
+func (ur *UserRepository) ResetPasswordWithToken(ctx context.Context, tokenHash, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	tx, err := ur.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query, args, err := sq.Delete("password_reset_tokens").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		Where(sq.Expr("expires_at > now()")).
+		Suffix("RETURNING user_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var userID uuid.UUID
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return ErrResetTokenNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	query, args, err = passwordUpdate(hashedPassword).
+		Set("failed_login_attempts", 0).
+		Set("locked_until", nil).
+		Where(sq.Eq{"id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func passwordUpdate(hashedPassword []byte) sq.UpdateBuilder {
+	return sq.Update("users").
+		Set("password", hashedPassword).
+		Set("tokens_revoked_at", sq.Expr("now()"))
+}
+
+// RecordLoginFailure counts a wrong password. The failure that reaches maxAttempts locks the account
+// until lockUntil and starts the count over, so after the lockout the user gets maxAttempts tries again.
+// It returns the account's locked_until, which is nil or in the past while the account is not locked.
+func (ur *UserRepository) RecordLoginFailure(ctx context.Context, id uuid.UUID, maxAttempts int, lockUntil time.Time) (*time.Time, error) {
+	query, args, err := sq.Update("users").
+		Set("failed_login_attempts", sq.Expr("CASE WHEN failed_login_attempts + 1 >= ? THEN 0 ELSE failed_login_attempts + 1 END", maxAttempts)).
+		Set("locked_until", sq.Expr("CASE WHEN failed_login_attempts + 1 >= ? THEN ? ELSE locked_until END", maxAttempts, lockUntil)).
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING locked_until").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var lockedUntil *time.Time
+	err = ur.db.QueryRowContext(ctx, query, args...).Scan(&lockedUntil)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return lockedUntil, nil
+}
+
+// ResetLoginFailures clears the failure count and lifts a lockout.
+func (ur *UserRepository) ResetLoginFailures(ctx context.Context, id uuid.UUID) error {
+	return ur.updateUser(ctx, id, sq.Update("users").
+		Set("failed_login_attempts", 0).
+		Set("locked_until", nil))
+}
+
+// GetAssignedPVZs returns the ids of the PVZs the user works at, in the order they were assigned.
+func (ur *UserRepository) GetAssignedPVZs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	query, args, err := sq.Select("pvz_id").
+		From("user_pvzs").
+		Where(sq.Eq{"user_id": id}).
+		OrderBy("assigned_at", "pvz_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := ur.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	pvzIDs := []uuid.UUID{}
+	for rows.Next() {
+		var pvzID uuid.UUID
+		if err := rows.Scan(&pvzID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		pvzIDs = append(pvzIDs, pvzID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+
+	return pvzIDs, nil
+}
+
+// AssignPVZ lets the user work at the PVZ. Assigning a PVZ twice is not an error.
+func (ur *UserRepository) AssignPVZ(ctx context.Context, id, pvzID uuid.UUID) error {
+	query, args, err := sq.Insert("user_pvzs").
+		Columns("user_id", "pvz_id").
+		Values(id, pvzID).
+		Suffix("ON CONFLICT DO NOTHING").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := ur.db.ExecContext(ctx, query, args...); err != nil {
+		// foreign_key_violation: the constraint tells whether the user or the PVZ is missing
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			if pqErr.Constraint == "user_pvzs_pvz_id_fkey" {
+				return ErrPVZNotFound
+			}
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// UnassignPVZ removes the PVZ from the user's ones. Removing a PVZ that is not assigned is not an error.
+func (ur *UserRepository) UnassignPVZ(ctx context.Context, id, pvzID uuid.UUID) error {
+	query, args, err := sq.Delete("user_pvzs").
+		Where(sq.Eq{"user_id": id, "pvz_id": pvzID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := ur.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"pvz/internal/models"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -15,9 +18,28 @@ import (
 
 var (
 	usersInsertQuery = regexp.QuoteMeta(`INSERT INTO users (id,email,password,role) VALUES ($1,$2,$3,$4)`)
-	usersSelectQuery = regexp.QuoteMeta(`SELECT * FROM users WHERE email = $1`)
+	usersSelectQuery = regexp.QuoteMeta(`SELECT id, email, password, role, failed_login_attempts, locked_until, deactivated_at, tokens_revoked_at FROM users WHERE email = $1`)
+	usersUpdateQuery = regexp.QuoteMeta(`UPDATE users SET password = $1, tokens_revoked_at = now() WHERE id = $2`)
+
+	usersSelectByIDQuery       = regexp.QuoteMeta(`SELECT id, email, password, role, failed_login_attempts, locked_until, deactivated_at, tokens_revoked_at FROM users WHERE id = $1`)
+	usersLoginFailureQuery     = regexp.QuoteMeta(`UPDATE users SET failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $1 THEN 0 ELSE failed_login_attempts + 1 END, locked_until = CASE WHEN failed_login_attempts + 1 >= $2 THEN $3 ELSE locked_until END WHERE id = $4 RETURNING locked_until`)
+	usersResetFailuresQuery    = regexp.QuoteMeta(`UPDATE users SET failed_login_attempts = $1, locked_until = $2 WHERE id = $3`)
+	usersRoleQuery             = regexp.QuoteMeta(`UPDATE users SET role = $1, tokens_revoked_at = now() WHERE id = $2`)
+	usersDeactivateQuery       = regexp.QuoteMeta(`UPDATE users SET deactivated_at = COALESCE(deactivated_at, now()), tokens_revoked_at = now() WHERE id = $1`)
+	usersReactivateQuery       = regexp.QuoteMeta(`UPDATE users SET deactivated_at = $1 WHERE id = $2`)
+	usersDeleteQuery           = regexp.QuoteMeta(`DELETE FROM users WHERE id = $1`)
+	usersRedeemResetTokenQuery = regexp.QuoteMeta(`DELETE FROM password_reset_tokens WHERE token_hash = $1 AND expires_at > now() RETURNING user_id`)
+	usersResetPasswordQuery    = regexp.QuoteMeta(`UPDATE users SET password = $1, tokens_revoked_at = now(), failed_login_attempts = $2, locked_until = $3 WHERE id = $4`)
+	usersRedeemInvitationQuery = regexp.QuoteMeta(`UPDATE invitations SET used_by = $1, used_at = now() WHERE code_hash = $2 AND used_at IS NULL AND expires_at > now() AND (email IS NULL OR email = $3)`)
+	userPVZsSelectQuery        = regexp.QuoteMeta(`SELECT pvz_id FROM user_pvzs WHERE user_id = $1 ORDER BY assigned_at, pvz_id`)
+	userPVZsInsertQuery        = regexp.QuoteMeta(`INSERT INTO user_pvzs (user_id,pvz_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`)
+	userPVZsDeleteQuery        = regexp.QuoteMeta(`DELETE FROM user_pvzs WHERE pvz_id = $1 AND user_id = $2`)
+	loginAttemptsInsertQuery   = regexp.QuoteMeta(`INSERT INTO login_attempts (id,user_id,email,ip,user_agent,success,failure_reason) VALUES ($1,$2,$3,$4,$5,$6,$7)`)
+	loginAttemptsSelectQuery   = regexp.QuoteMeta(`SELECT id, user_id, email, ip, user_agent, success, failure_reason, created_at FROM login_attempts WHERE user_id = $1 ORDER BY created_at DESC LIMIT 50`)
 )
 
+var userRowColumns = []string{"id", "email", "password", "role", "failed_login_attempts", "locked_until", "deactivated_at", "tokens_revoked_at"}
+
 func TestUserRepository_InsertUser_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -74,6 +96,66 @@ func TestUserRepository_InsertUser_HashError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestUserRepository_InsertInvitedUser(t *testing.T) {
+	email := "moderator@example.com"
+
+	t.Run("redeems the invitation", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(usersInsertQuery).
+			WithArgs(sqlmock.AnyArg(), email, sqlmock.AnyArg(), "moderator").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(usersRedeemInvitationQuery).
+			WithArgs(sqlmock.AnyArg(), "hash", email).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		user, err := NewUserRepository(db).InsertInvitedUser(context.Background(), email, "password", "moderator", "hash")
+		assert.NoError(t, err)
+		assert.Equal(t, "moderator", user.Role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("invalid invitation rolls the user back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(usersInsertQuery).
+			WithArgs(sqlmock.AnyArg(), email, sqlmock.AnyArg(), "moderator").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(usersRedeemInvitationQuery).
+			WithArgs(sqlmock.AnyArg(), "hash", email).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		user, err := NewUserRepository(db).InsertInvitedUser(context.Background(), email, "password", "moderator", "hash")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("existing user keeps the invitation", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(usersInsertQuery).
+			WithArgs(sqlmock.AnyArg(), email, sqlmock.AnyArg(), "moderator").
+			WillReturnError(&pq.Error{Code: "23505"})
+		mock.ExpectRollback()
+
+		_, err = NewUserRepository(db).InsertInvitedUser(context.Background(), email, "password", "moderator", "hash")
+		assert.ErrorIs(t, err, ErrUserExists)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestUserRepository_GetUserByEmail_Found(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -86,8 +168,8 @@ func TestUserRepository_GetUserByEmail_Found(t *testing.T) {
 	role := "admin"
 	password := "hashed-password"
 
-	rows := sqlmock.NewRows([]string{"id", "email", "password", "role"}).
-		AddRow(expectedID, email, password, role)
+	rows := sqlmock.NewRows(userRowColumns).
+		AddRow(expectedID, email, password, role, 0, nil, nil, nil)
 
 	mock.ExpectQuery(usersSelectQuery).
 		WithArgs(email).
@@ -143,3 +225,423 @@ func TestUserRepository_GetUserByEmail_DBerror(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUserRepository_ResetPasswordWithToken(t *testing.T) {
+	t.Run("redeems the token and sets the password", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		userID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(usersRedeemResetTokenQuery).
+			WithArgs("hash").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+		mock.ExpectExec(usersResetPasswordQuery).
+			WithArgs(sqlmock.AnyArg(), 0, nil, userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, NewUserRepository(db).ResetPasswordWithToken(context.Background(), "hash", "New-passw0rd"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown, used or expired token", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(usersRedeemResetTokenQuery).WithArgs("hash").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err = NewUserRepository(db).ResetPasswordWithToken(context.Background(), "hash", "New-passw0rd")
+		assert.ErrorIs(t, err, ErrResetTokenNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed update keeps the token", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(usersRedeemResetTokenQuery).
+			WithArgs("hash").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(uuid.New()))
+		mock.ExpectExec(usersResetPasswordQuery).WillReturnError(errors.New("db down"))
+		mock.ExpectRollback()
+
+		err = NewUserRepository(db).ResetPasswordWithToken(context.Background(), "hash", "New-passw0rd")
+		assert.ErrorContains(t, err, "db down")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepository_UpdatePassword(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+		id := uuid.New()
+
+		mock.ExpectExec(usersUpdateQuery).
+			WithArgs(sqlmock.AnyArg(), id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err = repo.UpdatePassword(context.Background(), id, "new-password")
+		assert.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+		id := uuid.New()
+
+		mock.ExpectExec(usersUpdateQuery).
+			WithArgs(sqlmock.AnyArg(), id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err = repo.UpdatePassword(context.Background(), id, "new-password")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepository_GetUserByID(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+		id := uuid.New()
+		lockedUntil := time.Now().Add(time.Minute).UTC()
+
+		mock.ExpectQuery(usersSelectByIDQuery).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow(id, "user@example.com", "hash", "employee", 2, lockedUntil, nil, nil))
+
+		user, err := repo.GetUserByID(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, user.ID)
+		assert.Equal(t, 2, user.FailedLoginAttempts)
+		assert.Equal(t, lockedUntil, *user.LockedUntil)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+		id := uuid.New()
+
+		mock.ExpectQuery(usersSelectByIDQuery).WithArgs(id).WillReturnError(sql.ErrNoRows)
+
+		user, err := repo.GetUserByID(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Nil(t, user)
+	})
+}
+
+func TestUserRepository_RecordLoginFailure(t *testing.T) {
+	t.Run("returns the lock", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+		id := uuid.New()
+		lockUntil := time.Now().Add(15 * time.Minute)
+
+		mock.ExpectQuery(usersLoginFailureQuery).
+			WithArgs(5, 5, lockUntil, id).
+			WillReturnRows(sqlmock.NewRows([]string{"locked_until"}).AddRow(lockUntil))
+
+		lockedUntil, err := repo.RecordLoginFailure(context.Background(), id, 5, lockUntil)
+		assert.NoError(t, err)
+		assert.Equal(t, lockUntil, *lockedUntil)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not locked", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+		id := uuid.New()
+
+		mock.ExpectQuery(usersLoginFailureQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"locked_until"}).AddRow(nil))
+
+		lockedUntil, err := repo.RecordLoginFailure(context.Background(), id, 5, time.Now())
+		assert.NoError(t, err)
+		assert.Nil(t, lockedUntil)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+
+		mock.ExpectQuery(usersLoginFailureQuery).WillReturnError(sql.ErrNoRows)
+
+		_, err = repo.RecordLoginFailure(context.Background(), uuid.New(), 5, time.Now())
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestUserRepository_ResetLoginFailures(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+		id := uuid.New()
+
+		mock.ExpectExec(usersResetFailuresQuery).
+			WithArgs(0, nil, id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.ResetLoginFailures(context.Background(), id))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+
+		mock.ExpectExec(usersResetFailuresQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.ResetLoginFailures(context.Background(), uuid.New()), ErrUserNotFound)
+	})
+}
+
+func TestUserRepository_GetUsers(t *testing.T) {
+	t.Run("all users", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+		id := uuid.New()
+		deactivatedAt := time.Now().UTC()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT ` + strings.Join(userColumns, ", ") + ` FROM users ORDER BY email LIMIT 10 OFFSET 0`)).
+			WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow(id, "user@example.com", "hash", "employee", 0, nil, deactivatedAt, deactivatedAt))
+
+		users, err := repo.GetUsers(context.Background(), UserFilter{}, 1, 10)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, id, users[0].ID)
+		assert.Equal(t, deactivatedAt, *users[0].DeactivatedAt)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewUserRepository(db)
+		active := false
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT `+strings.Join(userColumns, ", ")+` FROM users WHERE role = $1 AND email ILIKE $2 AND deactivated_at IS NULL ORDER BY email LIMIT 5 OFFSET 10`)).
+			WithArgs("moderator", `%a\_b%`).
+			WillReturnRows(sqlmock.NewRows(userRowColumns))
+
+		users, err := repo.GetUsers(context.Background(), UserFilter{Role: "moderator", Email: "a_b", Deactivated: &active}, 3, 5)
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepository_UpdateRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	id := uuid.New()
+
+	mock.ExpectExec(usersRoleQuery).WithArgs("moderator", id).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.UpdateRole(context.Background(), id, "moderator"))
+
+	mock.ExpectExec(usersRoleQuery).WithArgs("employee", id).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.UpdateRole(context.Background(), id, "employee"), ErrUserNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_SetDeactivated(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	id := uuid.New()
+
+	mock.ExpectExec(usersDeactivateQuery).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.SetDeactivated(context.Background(), id, true))
+
+	mock.ExpectExec(usersReactivateQuery).WithArgs(nil, id).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.SetDeactivated(context.Background(), id, false))
+
+	mock.ExpectExec(usersDeactivateQuery).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.SetDeactivated(context.Background(), id, true), ErrUserNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DeleteUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	id := uuid.New()
+
+	mock.ExpectExec(usersDeleteQuery).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.DeleteUser(context.Background(), id))
+
+	mock.ExpectExec(usersDeleteQuery).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.DeleteUser(context.Background(), id), ErrUserNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_GetAssignedPVZs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	id, first, second := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery(userPVZsSelectQuery).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"pvz_id"}).AddRow(first).AddRow(second))
+	pvzIDs, err := repo.GetAssignedPVZs(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{first, second}, pvzIDs)
+
+	mock.ExpectQuery(userPVZsSelectQuery).WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"pvz_id"}))
+	pvzIDs, err = repo.GetAssignedPVZs(context.Background(), id)
+	assert.NoError(t, err)
+	assert.NotNil(t, pvzIDs, "no PVZs is an empty list, not null")
+	assert.Empty(t, pvzIDs)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_AssignPVZ(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	id, pvzID := uuid.New(), uuid.New()
+
+	mock.ExpectExec(userPVZsInsertQuery).WithArgs(id, pvzID).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.AssignPVZ(context.Background(), id, pvzID))
+
+	mock.ExpectExec(userPVZsInsertQuery).WithArgs(id, pvzID).
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "user_pvzs_pvz_id_fkey"})
+	assert.ErrorIs(t, repo.AssignPVZ(context.Background(), id, pvzID), ErrPVZNotFound)
+
+	mock.ExpectExec(userPVZsInsertQuery).WithArgs(id, pvzID).
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "user_pvzs_user_id_fkey"})
+	assert.ErrorIs(t, repo.AssignPVZ(context.Background(), id, pvzID), ErrUserNotFound)
+
+	mock.ExpectExec(userPVZsDeleteQuery).WithArgs(pvzID, id).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.NoError(t, repo.UnassignPVZ(context.Background(), id, pvzID))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttemptRepository_InsertLoginAttempt(t *testing.T) {
+	t.Run("unknown email", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoginAttemptRepository(db)
+		attempt := models.LoginAttempt{
+			Email:         "nobody@example.com",
+			IP:            "10.0.0.1",
+			UserAgent:     "curl/8.0",
+			FailureReason: models.LoginFailureUnknownEmail,
+		}
+
+		mock.ExpectExec(loginAttemptsInsertQuery).
+			WithArgs(sqlmock.AnyArg(), nil, attempt.Email, attempt.IP, attempt.UserAgent, false, attempt.FailureReason).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.InsertLoginAttempt(context.Background(), attempt))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success has no failure reason", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoginAttemptRepository(db)
+		userID := uuid.New()
+
+		mock.ExpectExec(loginAttemptsInsertQuery).
+			WithArgs(sqlmock.AnyArg(), userID, "user@example.com", "", "", true, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err = repo.InsertLoginAttempt(context.Background(), models.LoginAttempt{UserID: &userID, Email: "user@example.com", Success: true})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLoginAttemptRepository_GetLoginAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoginAttemptRepository(db)
+	userID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(loginAttemptsSelectQuery).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "email", "ip", "user_agent", "success", "failure_reason", "created_at"}).
+			AddRow(uuid.New(), userID, "user@example.com", "10.0.0.1", "curl/8.0", false, models.LoginFailureWrongPassword, now).
+			AddRow(uuid.New(), userID, "user@example.com", "10.0.0.1", "curl/8.0", true, nil, now.Add(-time.Minute)))
+
+	attempts, err := repo.GetLoginAttempts(context.Background(), userID, 50)
+	assert.NoError(t, err)
+	assert.Len(t, attempts, 2)
+	assert.Equal(t, models.LoginFailureWrongPassword, attempts[0].FailureReason)
+	assert.True(t, attempts[1].Success)
+	assert.Empty(t, attempts[1].FailureReason)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pvz/internal/models"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+const deliveryColumns = "d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.response_code, d.last_error, d.next_attempt_at, d.created_at, d.delivered_at"
+
+type WebhookRepositoryInterface interface {
+	InsertSubscription(ctx context.Context, url, eventType, secret string, createdBy uuid.UUID) (*models.WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	EnqueueDeliveries(ctx context.Context, event models.Event) (int, error)
+	GetDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	MarkDeliveryDelivered(ctx context.Context, id uuid.UUID, responseCode int) error
+	MarkDeliveryFailed(ctx context.Context, id uuid.UUID, responseCode int, lastError string, nextAttemptAt *time.Time) error
+	ResetDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error)
+}
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) InsertSubscription(ctx context.Context, url, eventType, secret string, createdBy uuid.UUID) (*models.WebhookSubscription, error) {
+	query, args, err := sq.Insert("webhook_subscriptions").
+		Columns("id", "url", "event_type", "secret", "created_by").
+		Values(uuid.New(), url, eventType, secret, createdBy).
+		Suffix("RETURNING id, url, event_type, secret, created_by, created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var subscription models.WebhookSubscription
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&subscription.EventType,
+		&subscription.Secret,
+		&subscription.CreatedBy,
+		&subscription.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+// GetSubscriptions returns all subscriptions without their secrets.
+func (r *WebhookRepository) GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	query, args, err := sq.Select("id", "url", "event_type", "created_by", "created_at").
+		From("webhook_subscriptions").
+		OrderBy("created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []models.WebhookSubscription{}
+	for rows.Next() {
+		var subscription models.WebhookSubscription
+		err := rows.Scan(
+			&subscription.ID,
+			&subscription.URL,
+			&subscription.EventType,
+			&subscription.CreatedBy,
+			&subscription.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	query, args, err := sq.Delete("webhook_subscriptions").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error in rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// EnqueueDeliveries creates a pending delivery of the event for every matching subscription.
+// Enqueueing the same event twice is a no-op, so it is safe under at-least-once relaying.
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, event models.Event) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	selectSubscriptions := sq.Select().
+		Column("gen_random_uuid()").
+		Column("s.id").
+		Column("?", event.ID).
+		Column("?", event.Type).
+		Column("?", body).
+		From("webhook_subscriptions s").
+		Where(sq.Eq{"s.event_type": event.Type})
+
+	query, args, err := sq.Insert("webhook_deliveries").
+		Columns("id", "subscription_id", "event_id", "event_type", "payload").
+		Select(selectSubscriptions).
+		Suffix("ON CONFLICT (subscription_id, event_id) DO NOTHING").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue deliveries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error in rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	query, args, err := sq.Select(deliveryColumns).
+		From("webhook_deliveries d").
+		Where(sq.Eq{"d.subscription_id": subscriptionID}).
+		OrderBy("d.created_at DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := rows.Scan(deliveryScanDest(&delivery)...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDueDeliveries leases up to limit pending deliveries whose next attempt is due.
+// Claimed deliveries are pushed forward by lease, so other replicas skip them while they are being sent.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	due := sq.Select("id").
+		From("webhook_deliveries").
+		Where(sq.Eq{"status": models.DeliveryStatusPending}).
+		Where(sq.Expr("next_attempt_at <= now()")).
+		OrderBy("next_attempt_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	query, args, err := sq.Update("webhook_deliveries d").
+		Set("next_attempt_at", sq.Expr("now() + make_interval(secs => ?)", lease.Seconds())).
+		From("webhook_subscriptions s").
+		Where("s.id = d.subscription_id").
+		Where(sq.Expr("d.id IN (?)", due)).
+		Suffix("RETURNING " + deliveryColumns + ", s.url, s.secret").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		dest := append(deliveryScanDest(&delivery), &delivery.URL, &delivery.Secret)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after row iteration: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) MarkDeliveryDelivered(ctx context.Context, id uuid.UUID, responseCode int) error {
+	query, args, err := sq.Update("webhook_deliveries").
+		Set("status", models.DeliveryStatusDelivered).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("response_code", responseCode).
+		Set("last_error", nil).
+		Set("delivered_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// MarkDeliveryFailed records a failed attempt. A nil nextAttemptAt means no retries are left.
+func (r *WebhookRepository) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, responseCode int, lastError string, nextAttemptAt *time.Time) error {
+	update := sq.Update("webhook_deliveries").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", lastError)
+
+	if responseCode != 0 {
+		update = update.Set("response_code", responseCode)
+	}
+
+	if nextAttemptAt != nil {
+		update = update.Set("next_attempt_at", *nextAttemptAt)
+	} else {
+		update = update.Set("status", models.DeliveryStatusFailed)
+	}
+
+	query, args, err := update.Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// ResetDelivery puts a delivery back to the queue with a fresh retry budget.
+func (r *WebhookRepository) ResetDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	query, args, err := sq.Update("webhook_deliveries d").
+		Set("status", models.DeliveryStatusPending).
+		Set("attempts", 0).
+		Set("next_attempt_at", sq.Expr("now()")).
+		Where(sq.Eq{"d.id": id}).
+		Suffix("RETURNING " + deliveryColumns).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var delivery models.WebhookDelivery
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(deliveryScanDest(&delivery)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+func deliveryScanDest(delivery *models.WebhookDelivery) []interface{} {
+	return []interface{}{
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.ResponseCode,
+		&delivery.LastError,
+		&delivery.NextAttemptAt,
+		&delivery.CreatedAt,
+		&delivery.DeliveredAt,
+	}
+}
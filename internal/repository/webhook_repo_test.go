@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"pvz/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	subscriptionInsertQuery = regexp.QuoteMeta(`INSERT INTO webhook_subscriptions (id,url,event_type,secret,created_by) VALUES ($1,$2,$3,$4,$5) RETURNING id, url, event_type, secret, created_by, created_at`)
+	subscriptionDeleteQuery = regexp.QuoteMeta(`DELETE FROM webhook_subscriptions WHERE id = $1`)
+	deliveryEnqueueQuery    = regexp.QuoteMeta(`INSERT INTO webhook_deliveries (id,subscription_id,event_id,event_type,payload) SELECT gen_random_uuid(), s.id, $1, $2, $3 FROM webhook_subscriptions s WHERE s.event_type = $4 ON CONFLICT (subscription_id, event_id) DO NOTHING`)
+	deliveryClaimQuery      = regexp.QuoteMeta(`UPDATE webhook_deliveries d SET next_attempt_at = now() + make_interval(secs => $1) FROM webhook_subscriptions s WHERE s.id = d.subscription_id AND d.id IN (SELECT id FROM webhook_deliveries WHERE status = $2 AND next_attempt_at <= now() ORDER BY next_attempt_at LIMIT 5 FOR UPDATE SKIP LOCKED) RETURNING`)
+	deliveryRetryQuery      = regexp.QuoteMeta(`UPDATE webhook_deliveries SET attempts = attempts + 1, last_error = $1, response_code = $2, next_attempt_at = $3 WHERE id = $4`)
+	deliveryFailQuery       = regexp.QuoteMeta(`UPDATE webhook_deliveries SET attempts = attempts + 1, last_error = $1, status = $2 WHERE id = $3`)
+	deliveryResetQuery      = regexp.QuoteMeta(`UPDATE webhook_deliveries d SET status = $1, attempts = $2, next_attempt_at = now() WHERE d.id = $3 RETURNING`)
+)
+
+var deliveryRowColumns = []string{
+	"id", "subscription_id", "event_id", "event_type", "payload", "status", "attempts",
+	"response_code", "last_error", "next_attempt_at", "created_at", "delivered_at",
+}
+
+func TestWebhookRepository_InsertSubscription_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	id := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(subscriptionInsertQuery).
+		WithArgs(sqlmock.AnyArg(), "https://partner.example/hook", models.EventReceptionClosed, "secret", userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "event_type", "secret", "created_by", "created_at"}).
+			AddRow(id, "https://partner.example/hook", models.EventReceptionClosed, "secret", userID, now))
+
+	result, err := repo.InsertSubscription(context.Background(), "https://partner.example/hook", models.EventReceptionClosed, "secret", userID)
+	assert.NoError(t, err)
+	assert.Equal(t, id, result.ID)
+	assert.Equal(t, "secret", result.Secret)
+	assert.Equal(t, userID, result.CreatedBy)
+}
+
+func TestWebhookRepository_DeleteSubscription_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	id := uuid.New()
+
+	mock.ExpectExec(subscriptionDeleteQuery).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.DeleteSubscription(context.Background(), id)
+	assert.ErrorIs(t, err, ErrSubscriptionNotFound)
+}
+
+func TestWebhookRepository_EnqueueDeliveries_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	event := models.Event{ID: uuid.New(), Type: models.EventPVZCreated, PVZID: uuid.New(), Payload: []byte(`{}`)}
+
+	mock.ExpectExec(deliveryEnqueueQuery).
+		WithArgs(event.ID, event.Type, sqlmock.AnyArg(), event.Type).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	n, err := repo.EnqueueDeliveries(context.Background(), event)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestWebhookRepository_ClaimDueDeliveries_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	id := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(deliveryClaimQuery).
+		WithArgs(float64(30), models.DeliveryStatusPending).
+		WillReturnRows(sqlmock.NewRows(append(deliveryRowColumns, "url", "secret")).
+			AddRow(id, uuid.New(), uuid.New(), models.EventPVZCreated, []byte(`{}`), models.DeliveryStatusPending, 1,
+				nil, nil, now, now, nil, "https://partner.example/hook", "secret"))
+
+	deliveries, err := repo.ClaimDueDeliveries(context.Background(), 5, 30*time.Second)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, id, deliveries[0].ID)
+		assert.Equal(t, "https://partner.example/hook", deliveries[0].URL)
+		assert.Equal(t, "secret", deliveries[0].Secret)
+		assert.Nil(t, deliveries[0].ResponseCode)
+	}
+}
+
+func TestWebhookRepository_MarkDeliveryFailed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	id := uuid.New()
+	next := time.Now().Add(time.Minute)
+
+	mock.ExpectExec(deliveryRetryQuery).
+		WithArgs("timeout", 504, next, id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(deliveryFailQuery).
+		WithArgs("timeout", models.DeliveryStatusFailed, id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.MarkDeliveryFailed(context.Background(), id, 504, "timeout", &next))
+	assert.NoError(t, repo.MarkDeliveryFailed(context.Background(), id, 0, "timeout", nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_ResetDelivery_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	id := uuid.New()
+
+	mock.ExpectQuery(deliveryResetQuery).
+		WithArgs(models.DeliveryStatusPending, 0, id).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.ResetDelivery(context.Background(), id)
+	assert.ErrorIs(t, err, ErrDeliveryNotFound)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// APIKeyRole is the role API keys act with; their permissions narrow it down to single routes.
+const APIKeyRole = "employee"
+
+// apiKeyPrefix starts every API key, so that leaked keys are easy to recognise, e.g. by secret scanners.
+const apiKeyPrefix = "pvz_"
+
+// apiKeyShownLength is how much of a key its listing shows.
+const apiKeyShownLength = len(apiKeyPrefix) + 8
+
+// apiKeyPermissions are the permissions a key may be given: the employee operations an integration needs.
+var apiKeyPermissions = map[string]bool{
+	models.PermissionReceptionsWrite: true,
+	models.PermissionProductsWrite:   true,
+}
+
+type APIKeyServiceInterface interface {
+	CreateAPIKey(ctx context.Context, req APIKeyRequest, userID uuid.UUID, role string) (models.APIKey, error)
+	GetAPIKeys(ctx context.Context, role string) ([]models.APIKey, error)
+	RotateAPIKey(ctx context.Context, id uuid.UUID, role string) (models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID, role string) error
+}
+
+// APIKeyAuthenticatorInterface finds the key a request authenticates with.
+type APIKeyAuthenticatorInterface interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKey, error)
+}
+
+// APIKeyRequest describes a new key. Without PVZIDs it may act at every PVZ, without ExpiresAt it never expires.
+type APIKeyRequest struct {
+	Name        string
+	Permissions []string
+	PVZIDs      []uuid.UUID
+	ExpiresAt   *time.Time
+}
+
+// APIKeyService lets moderators issue keys for integrations. Like invitation codes, a key is shown
+// once, on creation or rotation, and only its hash is stored.
+type APIKeyService struct {
+	keys repository.APIKeyRepositoryInterface
+	// rotationGrace is how long the old secret of a rotated key keeps working.
+	rotationGrace time.Duration
+	now           func() time.Time
+}
+
+func NewAPIKeyService(keys repository.APIKeyRepositoryInterface, rotationGrace time.Duration) *APIKeyService {
+	return &APIKeyService{keys: keys, rotationGrace: rotationGrace, now: time.Now}
+}
+
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, req APIKeyRequest, userID uuid.UUID, role string) (_ models.APIKey, err error) {
+	ctx, span := startSpan(ctx, "APIKeyService.CreateAPIKey")
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return models.APIKey{}, ErrAccessDenied
+	}
+	if len(req.Permissions) == 0 {
+		return models.APIKey{}, ErrPermissionNotAllowed
+	}
+	for _, permission := range req.Permissions {
+		if !apiKeyPermissions[permission] {
+			return models.APIKey{}, ErrPermissionNotAllowed
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		return models.APIKey{}, ErrExpiryInPast
+	}
+
+	key, keyHash, err := newAPIKey()
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	created, err := s.keys.InsertAPIKey(ctx, models.APIKey{
+		Name:        req.Name,
+		Prefix:      key[:apiKeyShownLength],
+		Permissions: req.Permissions,
+		PVZIDs:      req.PVZIDs,
+		CreatedBy:   userID,
+		ExpiresAt:   req.ExpiresAt,
+	}, keyHash)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	created.Key = key
+	return *created, nil
+}
+
+func (s *APIKeyService) GetAPIKeys(ctx context.Context, role string) (_ []models.APIKey, err error) {
+	ctx, span := startSpan(ctx, "APIKeyService.GetAPIKeys")
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return nil, ErrAccessDenied
+	}
+
+	return s.keys.GetAPIKeys(ctx)
+}
+
+// RotateAPIKey replaces the key's secret and returns the new one. The old secret keeps working for the
+// rotation grace period, so an integration can switch over without downtime.
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, id uuid.UUID, role string) (_ models.APIKey, err error) {
+	ctx, span := startSpan(ctx, "APIKeyService.RotateAPIKey", attribute.String("api_key.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return models.APIKey{}, ErrAccessDenied
+	}
+
+	key, keyHash, err := newAPIKey()
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	rotated, err := s.keys.RotateAPIKey(ctx, id, keyHash, key[:apiKeyShownLength], s.now().Add(s.rotationGrace))
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	rotated.Key = key
+	return *rotated, nil
+}
+
+// RevokeAPIKey refuses the key, including a previous secret still in its rotation grace period.
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID, role string) (err error) {
+	ctx, span := startSpan(ctx, "APIKeyService.RevokeAPIKey", attribute.String("api_key.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return ErrAccessDenied
+	}
+
+	return s.keys.RevokeAPIKey(ctx, id)
+}
+
+// AuthenticateAPIKey returns the active key with this secret, or ErrInvalidAPIKey.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (_ *models.APIKey, err error) {
+	ctx, span := startSpan(ctx, "APIKeyService.AuthenticateAPIKey")
+	defer func() { endSpan(span, err) }()
+
+	found, err := s.keys.GetActiveAPIKeyByHash(ctx, hashSecretToken(key))
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, ErrInvalidAPIKey
+	}
+	return found, nil
+}
+
+// newAPIKey returns a new key and the hash it is stored by.
+func newAPIKey() (string, string, error) {
+	token, err := newSecretToken()
+	if err != nil {
+		return "", "", err
+	}
+	key := apiKeyPrefix + token
+	return key, hashSecretToken(key), nil
+}
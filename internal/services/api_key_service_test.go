@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"pvz/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAPIKeyRepo struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepo) InsertAPIKey(ctx context.Context, key models.APIKey, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, key, keyHash)
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) RotateAPIKey(ctx context.Context, id uuid.UUID, keyHash, prefix string, previousExpiresAt time.Time) (*models.APIKey, error) {
+	args := m.Called(ctx, id, keyHash, prefix, previousExpiresAt)
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	moderatorID, pvzID := uuid.New(), uuid.New()
+	newService := func(repo *MockAPIKeyRepo) *APIKeyService {
+		service := NewAPIKeyService(repo, time.Hour)
+		service.now = func() time.Time { return now }
+		return service
+	}
+
+	t.Run("returns the key once and stores its hash", func(t *testing.T) {
+		repo := new(MockAPIKeyRepo)
+		expiresAt := now.Add(30 * 24 * time.Hour)
+		req := APIKeyRequest{Name: "wms", Permissions: []string{models.PermissionReceptionsWrite}, PVZIDs: []uuid.UUID{pvzID}, ExpiresAt: &expiresAt}
+
+		var stored models.APIKey
+		var storedHash string
+		repo.On("InsertAPIKey", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { stored, storedHash = args.Get(1).(models.APIKey), args.String(2) }).
+			Return(&models.APIKey{ID: uuid.New(), Name: "wms"}, nil)
+
+		key, err := newService(repo).CreateAPIKey(context.Background(), req, moderatorID, "moderator")
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(key.Key, "pvz_"))
+		assert.Equal(t, hashSecretToken(key.Key), storedHash)
+		assert.Equal(t, key.Key[:12], stored.Prefix)
+		assert.Equal(t, moderatorID, stored.CreatedBy)
+		assert.Equal(t, []uuid.UUID{pvzID}, stored.PVZIDs)
+		assert.Equal(t, &expiresAt, stored.ExpiresAt)
+	})
+
+	cases := []struct {
+		name string
+		req  APIKeyRequest
+		role string
+		err  error
+	}{
+		{"employee", APIKeyRequest{Name: "wms", Permissions: []string{models.PermissionProductsWrite}}, "employee", ErrAccessDenied},
+		{"no permissions", APIKeyRequest{Name: "wms"}, "moderator", ErrPermissionNotAllowed},
+		{"unknown permission", APIKeyRequest{Name: "wms", Permissions: []string{"pvz:write"}}, "moderator", ErrPermissionNotAllowed},
+		{"expiry in the past", APIKeyRequest{Name: "wms", Permissions: []string{models.PermissionProductsWrite}, ExpiresAt: &now}, "moderator", ErrExpiryInPast},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockAPIKeyRepo)
+
+			_, err := newService(repo).CreateAPIKey(context.Background(), tc.req, moderatorID, tc.role)
+
+			assert.ErrorIs(t, err, tc.err)
+			repo.AssertNotCalled(t, "InsertAPIKey", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAPIKeyService_RotateAPIKey(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	id := uuid.New()
+
+	t.Run("keeps the old secret for the grace period", func(t *testing.T) {
+		repo := new(MockAPIKeyRepo)
+		service := NewAPIKeyService(repo, 24*time.Hour)
+		service.now = func() time.Time { return now }
+
+		var storedHash string
+		repo.On("RotateAPIKey", mock.Anything, id, mock.Anything, mock.Anything, now.Add(24*time.Hour)).
+			Run(func(args mock.Arguments) { storedHash = args.String(2) }).
+			Return(&models.APIKey{ID: id}, nil)
+
+		key, err := service.RotateAPIKey(context.Background(), id, "moderator")
+
+		require.NoError(t, err)
+		assert.Equal(t, hashSecretToken(key.Key), storedHash)
+	})
+
+	t.Run("employee", func(t *testing.T) {
+		repo := new(MockAPIKeyRepo)
+
+		_, err := NewAPIKeyService(repo, time.Hour).RotateAPIKey(context.Background(), id, "employee")
+
+		assert.ErrorIs(t, err, ErrAccessDenied)
+		repo.AssertNotCalled(t, "RotateAPIKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAPIKeyService_AuthenticateAPIKey(t *testing.T) {
+	repo := new(MockAPIKeyRepo)
+	service := NewAPIKeyService(repo, time.Hour)
+	key := &models.APIKey{ID: uuid.New(), Permissions: []string{models.PermissionProductsWrite}}
+	repo.On("GetActiveAPIKeyByHash", mock.Anything, hashSecretToken("pvz_valid")).Return(key, nil)
+	repo.On("GetActiveAPIKeyByHash", mock.Anything, hashSecretToken("pvz_unknown")).Return((*models.APIKey)(nil), nil)
+
+	found, err := service.AuthenticateAPIKey(context.Background(), "pvz_valid")
+	require.NoError(t, err)
+	assert.Equal(t, key, found)
+
+	_, err = service.AuthenticateAPIKey(context.Background(), "pvz_unknown")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+}
+
+func TestAPIKeyService_Moderation(t *testing.T) {
+	id := uuid.New()
+	repo := new(MockAPIKeyRepo)
+	service := NewAPIKeyService(repo, time.Hour)
+	repo.On("GetAPIKeys", mock.Anything).Return([]models.APIKey{{ID: id}}, nil)
+	repo.On("RevokeAPIKey", mock.Anything, id).Return(nil)
+
+	keys, err := service.GetAPIKeys(context.Background(), "moderator")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.NoError(t, service.RevokeAPIKey(context.Background(), id, "moderator"))
+
+	_, err = service.GetAPIKeys(context.Background(), "employee")
+	assert.ErrorIs(t, err, ErrAccessDenied)
+	assert.ErrorIs(t, service.RevokeAPIKey(context.Background(), id, "employee"), ErrAccessDenied)
+}
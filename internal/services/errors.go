@@ -1,6 +1,9 @@
 package services
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrAccessDenied          = errors.New("access denied")
@@ -10,4 +13,37 @@ var (
 	ErrPageParamIsInvalid    = errors.New("page parametr is invalid")
 	ErrLimitParamIsInvalid   = errors.New("limit parametr is invalid")
 	ErrInvalidRole           = errors.New("role is invalid")
+	ErrUserNotFound          = errors.New("no such user")
+	ErrWrongPassword         = errors.New("wrong password")
+	ErrAccountLocked         = errors.New("account is locked")
+	ErrWrongCurrentPassword  = errors.New("current password is wrong")
+	ErrWeakPassword          = errors.New("password does not meet the policy")
+	ErrDummyLoginDisabled    = errors.New("dummy login is disabled outside dev mode")
+	ErrAccountDeactivated    = errors.New("account is deactivated")
+	ErrTokenRevoked          = errors.New("token was revoked")
+	ErrOwnAccount            = errors.New("moderators cannot change the role of, deactivate or delete their own account")
+)
+
+var (
+	ErrEventTypeNotAllowed = errors.New("not allowed event type")
+	ErrInvalidWebhookURL   = errors.New("webhook url is invalid")
+)
+
+var (
+	ErrPermissionNotAllowed = errors.New("not allowed permission")
+	ErrExpiryInPast         = errors.New("expiry is not in the future")
+	ErrInvalidAPIKey        = errors.New("invalid API key")
 )
+
+// LimitError rejects a page size outside 1..Max; the maximum depends on the list.
+type LimitError struct {
+	Max int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("limit must be between 1 and %d", e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitParamIsInvalid
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const eventsBatchLimit = 100
+
+// streamedEventTypes are the events shown on the live reception dashboard.
+var streamedEventTypes = []string{
+	models.EventProductAdded,
+	models.EventProductDeleted,
+	models.EventReceptionClosed,
+}
+
+type EventServiceInterface interface {
+	GetPVZEvents(ctx context.Context, pvzID uuid.UUID, afterSeq int64, role string) ([]models.Event, error)
+	GetLastEventSeq(ctx context.Context, pvzID uuid.UUID, role string) (int64, error)
+}
+
+type EventService struct {
+	outboxRepo repository.OutboxRepositoryInterface
+}
+
+func NewEventService(outboxRepo repository.OutboxRepositoryInterface) *EventService {
+	return &EventService{outboxRepo: outboxRepo}
+}
+
+func (s *EventService) GetPVZEvents(ctx context.Context, pvzID uuid.UUID, afterSeq int64, role string) ([]models.Event, error) {
+	if _, ok := allowedRoles[role]; !ok {
+		return nil, ErrAccessDenied
+	}
+
+	return s.outboxRepo.GetEventsAfter(ctx, pvzID, afterSeq, streamedEventTypes, eventsBatchLimit)
+}
+
+func (s *EventService) GetLastEventSeq(ctx context.Context, pvzID uuid.UUID, role string) (int64, error) {
+	if _, ok := allowedRoles[role]; !ok {
+		return 0, ErrAccessDenied
+	}
+
+	return s.outboxRepo.GetLastEventSeq(ctx, pvzID)
+}
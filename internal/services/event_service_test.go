@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"pvz/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) ClaimUnpublished(ctx context.Context, limit int, lease time.Duration) ([]models.Event, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkPublishFailed(ctx context.Context, id uuid.UUID, lastError string, retryAt *time.Time) error {
+	args := m.Called(ctx, id, lastError, retryAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) ReleaseEvents(ctx context.Context, ids []uuid.UUID) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) GetEventsAfter(ctx context.Context, pvzID uuid.UUID, afterSeq int64, eventTypes []string, limit int) ([]models.Event, error) {
+	args := m.Called(ctx, pvzID, afterSeq, eventTypes, limit)
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockOutboxRepository) GetLastEventSeq(ctx context.Context, pvzID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestEventService_GetPVZEvents(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+	eventService := NewEventService(mockRepo)
+	pvzID := uuid.New()
+
+	t.Run("returns streamed event types only", func(t *testing.T) {
+		mockRepo.On("GetEventsAfter", mock.Anything, pvzID, int64(3), streamedEventTypes, eventsBatchLimit).
+			Return([]models.Event{{Seq: 4, Type: models.EventProductAdded}}, nil)
+
+		events, err := eventService.GetPVZEvents(context.Background(), pvzID, 3, "employee")
+
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("access denied for unknown role", func(t *testing.T) {
+		events, err := eventService.GetPVZEvents(context.Background(), pvzID, 3, "guest")
+
+		assert.Equal(t, ErrAccessDenied, err)
+		assert.Nil(t, events)
+	})
+}
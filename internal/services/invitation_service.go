@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InvitationServiceInterface interface {
+	CreateInvitation(ctx context.Context, email string, userID uuid.UUID, role string) (models.Invitation, error)
+	GetInvitations(ctx context.Context, role string) ([]models.Invitation, error)
+	DeleteInvitation(ctx context.Context, id uuid.UUID, role string) error
+}
+
+// InvitationService lets moderators invite new moderators. The code is shown once, to the
+// moderator who creates the invitation; only its hash is stored.
+type InvitationService struct {
+	invitations repository.InvitationRepositoryInterface
+	ttl         time.Duration
+	now         func() time.Time
+}
+
+func NewInvitationService(invitations repository.InvitationRepositoryInterface, ttl time.Duration) *InvitationService {
+	return &InvitationService{invitations: invitations, ttl: ttl, now: time.Now}
+}
+
+// CreateInvitation issues an invitation valid for the configured TTL. With an email, only that
+// email can register with it.
+func (s *InvitationService) CreateInvitation(ctx context.Context, email string, userID uuid.UUID, role string) (_ models.Invitation, err error) {
+	ctx, span := startSpan(ctx, "InvitationService.CreateInvitation")
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return models.Invitation{}, ErrAccessDenied
+	}
+
+	code, err := newSecretToken()
+	if err != nil {
+		return models.Invitation{}, err
+	}
+
+	invitation, err := s.invitations.InsertInvitation(ctx, hashSecretToken(code), email, userID, s.now().Add(s.ttl))
+	if err != nil {
+		return models.Invitation{}, err
+	}
+	invitation.Code = code
+	return *invitation, nil
+}
+
+func (s *InvitationService) GetInvitations(ctx context.Context, role string) (_ []models.Invitation, err error) {
+	ctx, span := startSpan(ctx, "InvitationService.GetInvitations")
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return nil, ErrAccessDenied
+	}
+
+	return s.invitations.GetInvitations(ctx)
+}
+
+func (s *InvitationService) DeleteInvitation(ctx context.Context, id uuid.UUID, role string) (err error) {
+	ctx, span := startSpan(ctx, "InvitationService.DeleteInvitation")
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return ErrAccessDenied
+	}
+
+	return s.invitations.DeleteInvitation(ctx, id)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockInvitationRepo struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepo) InsertInvitation(ctx context.Context, codeHash, email string, createdBy uuid.UUID, expiresAt time.Time) (*models.Invitation, error) {
+	args := m.Called(ctx, codeHash, email, createdBy, expiresAt)
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepo) GetInvitations(ctx context.Context) ([]models.Invitation, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepo) DeleteInvitation(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestInvitationService_CreateInvitation(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	moderatorID := uuid.New()
+
+	t.Run("returns the code once and stores its hash", func(t *testing.T) {
+		repo := new(MockInvitationRepo)
+		service := NewInvitationService(repo, 72*time.Hour)
+		service.now = func() time.Time { return now }
+
+		var storedHash string
+		repo.On("InsertInvitation", mock.Anything, mock.Anything, "new@example.com", moderatorID, now.Add(72*time.Hour)).
+			Run(func(args mock.Arguments) { storedHash = args.String(1) }).
+			Return(&models.Invitation{ID: uuid.New(), Email: "new@example.com", CreatedBy: moderatorID, ExpiresAt: now.Add(72 * time.Hour)}, nil)
+
+		invitation, err := service.CreateInvitation(context.Background(), "new@example.com", moderatorID, "moderator")
+
+		require.NoError(t, err)
+		require.NotEmpty(t, invitation.Code)
+		assert.Equal(t, hashSecretToken(invitation.Code), storedHash)
+		assert.NotEqual(t, invitation.Code, storedHash)
+	})
+
+	t.Run("employee", func(t *testing.T) {
+		repo := new(MockInvitationRepo)
+
+		_, err := NewInvitationService(repo, time.Hour).CreateInvitation(context.Background(), "", uuid.New(), "employee")
+
+		assert.ErrorIs(t, err, ErrAccessDenied)
+		repo.AssertNotCalled(t, "InsertInvitation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestInvitationService_GetInvitations(t *testing.T) {
+	repo := new(MockInvitationRepo)
+	service := NewInvitationService(repo, time.Hour)
+	repo.On("GetInvitations", mock.Anything).Return([]models.Invitation{{ID: uuid.New()}}, nil)
+
+	invitations, err := service.GetInvitations(context.Background(), "moderator")
+	assert.NoError(t, err)
+	assert.Len(t, invitations, 1)
+
+	_, err = service.GetInvitations(context.Background(), "employee")
+	assert.ErrorIs(t, err, ErrAccessDenied)
+}
+
+func TestInvitationService_DeleteInvitation(t *testing.T) {
+	repo := new(MockInvitationRepo)
+	service := NewInvitationService(repo, time.Hour)
+	id := uuid.New()
+	repo.On("DeleteInvitation", mock.Anything, id).Return(repository.ErrInvitationNotFound)
+
+	assert.ErrorIs(t, service.DeleteInvitation(context.Background(), id, "moderator"), repository.ErrInvitationNotFound)
+	assert.ErrorIs(t, service.DeleteInvitation(context.Background(), id, "employee"), ErrAccessDenied)
+}
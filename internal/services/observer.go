@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+	"pvz/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Observer is told about business operations after they succeed, whichever transport they came
+// through. Implementations must be cheap and cannot fail the operation; internal/metrics counts them.
+type Observer interface {
+	PVZCreated(ctx context.Context, pvz models.PVZ)
+	ReceptionOpened(ctx context.Context, reception models.Reception)
+	ReceptionClosed(ctx context.Context, reception models.Reception)
+	ProductAdded(ctx context.Context, product models.Product, pvzID uuid.UUID)
+	ProductDeleted(ctx context.Context, product models.Product, pvzID uuid.UUID)
+}
+
+// NopObserver ignores every notification.
+type NopObserver struct{}
+
+func (NopObserver) PVZCreated(context.Context, models.PVZ)                    {}
+func (NopObserver) ReceptionOpened(context.Context, models.Reception)         {}
+func (NopObserver) ReceptionClosed(context.Context, models.Reception)         {}
+func (NopObserver) ProductAdded(context.Context, models.Product, uuid.UUID)   {}
+func (NopObserver) ProductDeleted(context.Context, models.Product, uuid.UUID) {}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"pvz/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockObserver struct {
+	mock.Mock
+}
+
+func (m *MockObserver) PVZCreated(ctx context.Context, pvz models.PVZ) {
+	m.Called(ctx, pvz)
+}
+
+func (m *MockObserver) ReceptionOpened(ctx context.Context, reception models.Reception) {
+	m.Called(ctx, reception)
+}
+
+func (m *MockObserver) ReceptionClosed(ctx context.Context, reception models.Reception) {
+	m.Called(ctx, reception)
+}
+
+func (m *MockObserver) ProductAdded(ctx context.Context, product models.Product, pvzID uuid.UUID) {
+	m.Called(ctx, product, pvzID)
+}
+
+func (m *MockObserver) ProductDeleted(ctx context.Context, product models.Product, pvzID uuid.UUID) {
+	m.Called(ctx, product, pvzID)
+}
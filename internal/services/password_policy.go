@@ -0,0 +1,95 @@
+package services
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+)
+
+// Password policy violations, reported to clients as they are.
+const (
+	PasswordTooShort      = "too_short"
+	PasswordTooLong       = "too_long"
+	PasswordMissingLower  = "missing_lower"
+	PasswordMissingUpper  = "missing_upper"
+	PasswordMissingDigit  = "missing_digit"
+	PasswordMissingSymbol = "missing_symbol"
+	PasswordBreached      = "breached"
+	// PasswordUnchanged is reported when a password is changed to itself.
+	PasswordUnchanged = "unchanged"
+)
+
+// maxPasswordBytes is where bcrypt stops reading; longer passwords would be silently truncated.
+const maxPasswordBytes = 72
+
+//go:embed breached_passwords.txt
+var breachedPasswordList string
+
+var breachedPasswords = func() map[string]bool {
+	passwords := make(map[string]bool)
+	for _, line := range strings.Split(breachedPasswordList, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			passwords[line] = true
+		}
+	}
+	return passwords
+}()
+
+// PasswordPolicyError lists every rule a password breaks.
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrWeakPassword.Error() + ": " + strings.Join(e.Violations, ", ")
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// PasswordPolicy is checked whenever a password is set; existing passwords keep working when it changes.
+type PasswordPolicy struct {
+	MinLength int
+	// RequiredClasses are lower, upper, digit and symbol.
+	RequiredClasses []string
+}
+
+// Check returns a *PasswordPolicyError when the password breaks any rule.
+func (p PasswordPolicy) Check(password string) error {
+	var violations []string
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, PasswordTooShort)
+	}
+	if len(password) > maxPasswordBytes {
+		violations = append(violations, PasswordTooLong)
+	}
+
+	has := map[string]bool{}
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			has["lower"] = true
+		case unicode.IsUpper(r):
+			has["upper"] = true
+		case unicode.IsDigit(r):
+			has["digit"] = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			has["symbol"] = true
+		}
+	}
+	for _, class := range p.RequiredClasses {
+		if !has[class] {
+			violations = append(violations, "missing_"+class)
+		}
+	}
+
+	if breachedPasswords[strings.ToLower(password)] {
+		violations = append(violations, PasswordBreached)
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
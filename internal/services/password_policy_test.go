@@ -0,0 +1,46 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, RequiredClasses: []string{"lower", "upper", "digit"}}
+
+	cases := []struct {
+		name       string
+		password   string
+		violations []string
+	}{
+		{"strong", "Tr0ub4dor-horse", nil},
+		{"non-latin letters count", "Пароль2026ПВЗ", nil},
+		{"too short", "Ab1", []string{PasswordTooShort}},
+		{"missing classes", "correcthorse", []string{PasswordMissingUpper, PasswordMissingDigit}},
+		{"breached in any case", "Password123", []string{PasswordBreached}},
+		{"too long for bcrypt", "Aa1" + strings.Repeat("x", 70), []string{PasswordTooLong}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Check(tc.password)
+			if tc.violations == nil {
+				assert.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrWeakPassword)
+			var policyErr *PasswordPolicyError
+			require.ErrorAs(t, err, &policyErr)
+			assert.Equal(t, tc.violations, policyErr.Violations)
+		})
+	}
+
+	t.Run("symbols", func(t *testing.T) {
+		withSymbol := PasswordPolicy{MinLength: 1, RequiredClasses: []string{"symbol"}}
+		assert.NoError(t, withSymbol.Check("a b"))
+		assert.NoError(t, withSymbol.Check("a+b"))
+		assert.ErrorIs(t, withSymbol.Check("ab1"), ErrWeakPassword)
+	})
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"pvz/internal/notify"
+	"pvz/internal/repository"
+	"time"
+)
+
+type PasswordResetServiceInterface interface {
+	RequestPasswordReset(ctx context.Context, email string) error
+	CompletePasswordReset(ctx context.Context, token, password string) error
+}
+
+// PasswordResetService lets users who forgot their password set a new one with a single-use
+// token sent through the notifier.
+type PasswordResetService struct {
+	userRepo repository.UserRepositoryInterface
+	resets   repository.PasswordResetRepositoryInterface
+	notifier notify.NotifierInterface
+	policy   PasswordPolicy
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+func NewPasswordResetService(userRepo repository.UserRepositoryInterface, resets repository.PasswordResetRepositoryInterface,
+	notifier notify.NotifierInterface, policy PasswordPolicy, ttl time.Duration) *PasswordResetService {
+	return &PasswordResetService{userRepo: userRepo, resets: resets, notifier: notifier, policy: policy, ttl: ttl, now: time.Now}
+}
+
+// RequestPasswordReset sends a reset token to the email if it belongs to a user. It succeeds for
+// unknown emails too and does not report a failed delivery, so it cannot be used to find accounts.
+func (s *PasswordResetService) RequestPasswordReset(ctx context.Context, email string) (err error) {
+	ctx, span := startSpan(ctx, "PasswordResetService.RequestPasswordReset")
+	defer func() { endSpan(span, err) }()
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := s.now().Add(s.ttl)
+	if err := s.resets.ReplaceResetToken(ctx, user.ID, hashSecretToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	msg := notify.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("Use this token to set a new password: %s\nIt is valid until %s. If you did not ask for it, ignore this message.",
+			token, expiresAt.UTC().Format(time.RFC3339)),
+	}
+	if err := s.notifier.Notify(ctx, msg); err != nil {
+		slog.ErrorContext(ctx, "failed to send password reset token", slog.String("user_id", user.ID.String()), slog.Any("error", err))
+	}
+	return nil
+}
+
+// CompletePasswordReset sets a new password with a token from RequestPasswordReset, signs the user out
+// everywhere and lifts a lockout of the account: the token proves the user owns the email.
+func (s *PasswordResetService) CompletePasswordReset(ctx context.Context, token, password string) (err error) {
+	ctx, span := startSpan(ctx, "PasswordResetService.CompletePasswordReset")
+	defer func() { endSpan(span, err) }()
+
+	// checked first, so that a rejected password does not use up the token
+	if err := s.policy.Check(password); err != nil {
+		return err
+	}
+
+	return s.userRepo.ResetPasswordWithToken(ctx, hashSecretToken(token), password)
+}
+
+// newSecretToken generates the secrets handed to users: password reset tokens, invitation codes
+// and API keys.
+func newSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashSecretToken is how secret tokens are stored; they are random enough that a plain SHA-256 is safe.
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pvz/internal/models"
+	"pvz/internal/notify"
+	"pvz/internal/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPasswordResetRepo struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepo) ReplaceResetToken(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, userID, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+type recordingNotifier struct {
+	messages []notify.Message
+	err      error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, msg notify.Message) error {
+	n.messages = append(n.messages, msg)
+	return n.err
+}
+
+func TestPasswordResetService_RequestPasswordReset(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	t.Run("sends a token and stores its hash", func(t *testing.T) {
+		userRepo := new(MockUserRepo)
+		resets := new(MockPasswordResetRepo)
+		notifier := &recordingNotifier{}
+		service := NewPasswordResetService(userRepo, resets, notifier, testPolicy, time.Hour)
+		service.now = func() time.Time { return now }
+		user := &models.User{ID: uuid.New(), Email: "user@example.com"}
+
+		var storedHash string
+		userRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+		resets.On("ReplaceResetToken", mock.Anything, user.ID, mock.Anything, now.Add(time.Hour)).
+			Run(func(args mock.Arguments) { storedHash = args.String(2) }).
+			Return(nil)
+
+		require.NoError(t, service.RequestPasswordReset(context.Background(), user.Email))
+
+		require.Len(t, notifier.messages, 1)
+		msg := notifier.messages[0]
+		assert.Equal(t, user.Email, msg.To)
+		token := strings.TrimPrefix(strings.SplitN(msg.Body, "\n", 2)[0], "Use this token to set a new password: ")
+		assert.Len(t, token, 43)
+		assert.Equal(t, hashSecretToken(token), storedHash)
+		assert.NotContains(t, msg.Body, storedHash)
+	})
+
+	t.Run("unknown email succeeds silently", func(t *testing.T) {
+		userRepo := new(MockUserRepo)
+		notifier := &recordingNotifier{}
+		service := NewPasswordResetService(userRepo, new(MockPasswordResetRepo), notifier, testPolicy, time.Hour)
+		userRepo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return((*models.User)(nil), nil)
+
+		assert.NoError(t, service.RequestPasswordReset(context.Background(), "nobody@example.com"))
+		assert.Empty(t, notifier.messages)
+	})
+
+	t.Run("delivery failure is not reported", func(t *testing.T) {
+		userRepo := new(MockUserRepo)
+		resets := new(MockPasswordResetRepo)
+		service := NewPasswordResetService(userRepo, resets, &recordingNotifier{err: errors.New("smtp is down")}, testPolicy, time.Hour)
+		user := &models.User{ID: uuid.New(), Email: "user@example.com"}
+		userRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+		resets.On("ReplaceResetToken", mock.Anything, user.ID, mock.Anything, mock.Anything).Return(nil)
+
+		assert.NoError(t, service.RequestPasswordReset(context.Background(), user.Email))
+	})
+}
+
+func TestPasswordResetService_CompletePasswordReset(t *testing.T) {
+	t.Run("redeems the token with the new password", func(t *testing.T) {
+		userRepo := new(MockUserRepo)
+		service := NewPasswordResetService(userRepo, new(MockPasswordResetRepo), &recordingNotifier{}, testPolicy, time.Hour)
+		userRepo.On("ResetPasswordWithToken", mock.Anything, hashSecretToken("token"), "New-passw0rd").Return(nil)
+
+		assert.NoError(t, service.CompletePasswordReset(context.Background(), "token", "New-passw0rd"))
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("weak password keeps the token", func(t *testing.T) {
+		userRepo := new(MockUserRepo)
+		service := NewPasswordResetService(userRepo, new(MockPasswordResetRepo), &recordingNotifier{}, testPolicy, time.Hour)
+
+		err := service.CompletePasswordReset(context.Background(), "token", "weak")
+
+		assert.ErrorIs(t, err, ErrWeakPassword)
+		userRepo.AssertNotCalled(t, "ResetPasswordWithToken", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		userRepo := new(MockUserRepo)
+		service := NewPasswordResetService(userRepo, new(MockPasswordResetRepo), &recordingNotifier{}, testPolicy, time.Hour)
+		userRepo.On("ResetPasswordWithToken", mock.Anything, mock.Anything, mock.Anything).Return(repository.ErrResetTokenNotFound)
+
+		err := service.CompletePasswordReset(context.Background(), "token", "New-passw0rd")
+
+		assert.ErrorIs(t, err, repository.ErrResetTokenNotFound)
+	})
+}
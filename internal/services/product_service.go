@@ -4,8 +4,10 @@ import (
 	"context"
 	"pvz/internal/models"
 	"pvz/internal/repository"
+	"sort"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var allowedProductTypes = map[string]bool{
@@ -15,19 +17,34 @@ var allowedProductTypes = map[string]bool{
 }
 
 type ProductServiceInterface interface {
-	AddProduct(ctx context.Context, productType string, pvzID uuid.UUID, role string) (models.Product, error)
+	AddProduct(ctx context.Context, productType string, pvzID, userID uuid.UUID, role string) (models.Product, error)
 	DeleteProduct(ctx context.Context, pvzID uuid.UUID, role string) error
 }
 
 type ProductService struct {
 	productRepo repository.ProductRepositoryInterface
+	observer    Observer
 }
 
-func NewProductService(productRepo repository.ProductRepositoryInterface) *ProductService {
-	return &ProductService{productRepo: productRepo}
+func NewProductService(productRepo repository.ProductRepositoryInterface, observer Observer) *ProductService {
+	return &ProductService{productRepo: productRepo, observer: observer}
 }
 
-func (s *ProductService) AddProduct(ctx context.Context, productType string, pvzID uuid.UUID, role string) (models.Product, error) {
+// ProductTypes lists the product types a reception accepts, in a stable order.
+func ProductTypes() []string {
+	types := make([]string, 0, len(allowedProductTypes))
+	for productType := range allowedProductTypes {
+		types = append(types, productType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func (s *ProductService) AddProduct(ctx context.Context, productType string, pvzID, userID uuid.UUID, role string) (_ models.Product, err error) {
+	ctx, span := startSpan(ctx, "ProductService.AddProduct",
+		attribute.String("pvz.id", pvzID.String()), attribute.String("product.type", productType))
+	defer func() { endSpan(span, err) }()
+
 	if role != "employee" {
 		return models.Product{}, ErrAccessDenied
 	}
@@ -36,23 +53,28 @@ func (s *ProductService) AddProduct(ctx context.Context, productType string, pvz
 		return models.Product{}, ErrProductTypeNotAllowed
 	}
 
-	product, err := s.productRepo.InsertProduct(ctx, productType, pvzID)
+	product, err := s.productRepo.InsertProduct(ctx, productType, pvzID, userID)
 	if err != nil {
 		return models.Product{}, err
 	}
+	s.observer.ProductAdded(ctx, *product, pvzID)
 
 	return *product, err
 }
 
-func (s *ProductService) DeleteProduct(ctx context.Context, pvzID uuid.UUID, role string) error {
+func (s *ProductService) DeleteProduct(ctx context.Context, pvzID uuid.UUID, role string) (err error) {
+	ctx, span := startSpan(ctx, "ProductService.DeleteProduct", attribute.String("pvz.id", pvzID.String()))
+	defer func() { endSpan(span, err) }()
+
 	if role != "employee" {
 		return ErrAccessDenied
 	}
 
-	err := s.productRepo.DeleteLastProduct(ctx, pvzID)
+	product, err := s.productRepo.DeleteLastProduct(ctx, pvzID)
 	if err != nil {
 		return err
 	}
+	s.observer.ProductDeleted(ctx, *product, pvzID)
 
 	return nil
 }
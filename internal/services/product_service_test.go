@@ -16,22 +16,24 @@ type MockProductRepository struct {
 	mock.Mock
 }
 
-func (m *MockProductRepository) InsertProduct(ctx context.Context, productType string, pvzID uuid.UUID) (*models.Product, error) {
-	args := m.Called(ctx, productType, pvzID)
+func (m *MockProductRepository) InsertProduct(ctx context.Context, productType string, pvzID, userID uuid.UUID) (*models.Product, error) {
+	args := m.Called(ctx, productType, pvzID, userID)
 	return args.Get(0).(*models.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error {
+func (m *MockProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) (*models.Product, error) {
 	args := m.Called(ctx, pvzID)
-	return args.Error(0)
+	return args.Get(0).(*models.Product), args.Error(1)
 }
 
 func TestProductService_AddProduct(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	productService := NewProductService(mockRepo)
+	observer := new(MockObserver)
+	productService := NewProductService(mockRepo, observer)
 
 	pvzID := uuid.New()
 	receptionID := uuid.New()
+	userID := uuid.New()
 	productType := "электроника"
 	role := "employee"
 
@@ -42,20 +44,24 @@ func TestProductService_AddProduct(t *testing.T) {
 			DateTime:    time.Now(),
 			ProductType: productType,
 			ReceptionID: receptionID,
+			CreatedBy:   userID,
 		}
 
-		mockRepo.On("InsertProduct", mock.Anything, productType, pvzID).Return(product, nil)
+		mockRepo.On("InsertProduct", mock.Anything, productType, pvzID, userID).Return(product, nil)
+		observer.On("ProductAdded", mock.Anything, *product, pvzID).Once()
 
-		outProduct, err := productService.AddProduct(context.Background(), productType, pvzID, role)
+		outProduct, err := productService.AddProduct(context.Background(), productType, pvzID, userID, role)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, outProduct.ID)
+		assert.Equal(t, userID, outProduct.CreatedBy)
+		observer.AssertExpectations(t)
 	})
 
 	t.Run("access denied for non-employee role", func(t *testing.T) {
 		role = "admin"
 
-		product, err := productService.AddProduct(context.Background(), productType, pvzID, role)
+		product, err := productService.AddProduct(context.Background(), productType, pvzID, userID, role)
 
 		assert.Error(t, err)
 		assert.Equal(t, ErrAccessDenied, err)
@@ -66,7 +72,7 @@ func TestProductService_AddProduct(t *testing.T) {
 		role = "employee"
 		productType = "неизвестный тип"
 
-		product, err := productService.AddProduct(context.Background(), productType, pvzID, role)
+		product, err := productService.AddProduct(context.Background(), productType, pvzID, userID, role)
 
 		assert.Error(t, err)
 		assert.Equal(t, ErrProductTypeNotAllowed, err)
@@ -76,24 +82,28 @@ func TestProductService_AddProduct(t *testing.T) {
 
 func TestProductService_DeleteProduct(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	productService := NewProductService(mockRepo)
+	observer := new(MockObserver)
+	productService := NewProductService(mockRepo, observer)
 
 	pvzID := uuid.New()
 	role := "employee"
+	deleted := &models.Product{ID: uuid.New(), DateTime: time.Now(), ProductType: "обувь", ReceptionID: uuid.New()}
 
 	t.Run("successful product deletion", func(t *testing.T) {
 		// Mock the DeleteLastProduct method
-		mockRepo.On("DeleteLastProduct", mock.Anything, pvzID).Return(nil)
+		mockRepo.On("DeleteLastProduct", mock.Anything, pvzID).Return(deleted, nil)
+		observer.On("ProductDeleted", mock.Anything, *deleted, pvzID).Once()
 
 		err := productService.DeleteProduct(context.Background(), pvzID, role)
 
 		assert.NoError(t, err)
+		observer.AssertExpectations(t)
 	})
 
 	t.Run("error while deleting product", func(t *testing.T) {
 		mockRepo.ExpectedCalls = []*mock.Call{}
 
-		mockRepo.On("DeleteLastProduct", mock.Anything, pvzID).Return(errors.New("some error"))
+		mockRepo.On("DeleteLastProduct", mock.Anything, pvzID).Return((*models.Product)(nil), errors.New("some error"))
 
 		err := productService.DeleteProduct(context.Background(), pvzID, role)
 
@@ -103,7 +113,7 @@ func TestProductService_DeleteProduct(t *testing.T) {
 
 	t.Run("access denied for non-employee role", func(t *testing.T) {
 		role = "admin"
-		mockRepo.On("DeleteLastProduct", mock.Anything, pvzID).Return(nil)
+		mockRepo.On("DeleteLastProduct", mock.Anything, pvzID).Return(deleted, nil)
 
 		err := productService.DeleteProduct(context.Background(), pvzID, role)
 
@@ -2,10 +2,13 @@ package services
 
 import (
 	"context"
-	"errors"
 	"pvz/internal/models"
 	"pvz/internal/repository"
+	"sort"
 	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var allowedCities = map[string]bool{
@@ -16,35 +19,54 @@ var allowedCities = map[string]bool{
 
 type PVZServiceInterface interface {
 	CreatePVZ(ctx context.Context, city, role string) (models.PVZ, error)
-	GetPVZList(ctx context.Context, startDate, endDate *time.Time, page, limit int, role string) ([]models.PVZWithReceptions, error)
+	GetPVZList(ctx context.Context, startDate, endDate *time.Time, createdBy, closedBy *uuid.UUID, page, limit int, role string) ([]models.PVZWithReceptions, error)
 }
 
 type PVZService struct {
-	pvzRepo repository.PVZRepositoryInterface
+	pvzRepo      repository.PVZRepositoryInterface
+	maxListLimit int
+	observer     Observer
+}
+
+func NewPVZService(pvzRepo repository.PVZRepositoryInterface, maxListLimit int, observer Observer) *PVZService {
+	return &PVZService{pvzRepo: pvzRepo, maxListLimit: maxListLimit, observer: observer}
 }
 
-func NewPVZService(pvzRepo repository.PVZRepositoryInterface) *PVZService {
-	return &PVZService{pvzRepo: pvzRepo}
+// Cities lists the cities where a PVZ can be opened, in a stable order.
+func Cities() []string {
+	cities := make([]string, 0, len(allowedCities))
+	for city := range allowedCities {
+		cities = append(cities, city)
+	}
+	sort.Strings(cities)
+	return cities
 }
 
-func (s *PVZService) CreatePVZ(ctx context.Context, city, role string) (models.PVZ, error) {
+func (s *PVZService) CreatePVZ(ctx context.Context, city, role string) (_ models.PVZ, err error) {
+	ctx, span := startSpan(ctx, "PVZService.CreatePVZ", attribute.String("pvz.city", city))
+	defer func() { endSpan(span, err) }()
+
 	if role != "moderator" {
 		return models.PVZ{}, ErrAccessDenied
 	}
 
 	if _, ok := allowedCities[city]; !ok {
-		return models.PVZ{}, errors.New("not allowed city")
+		return models.PVZ{}, ErrCityNotAllowed
 	}
 
 	pvz, err := s.pvzRepo.InsertPVZ(ctx, city)
 	if err != nil {
 		return models.PVZ{}, err
 	}
+	s.observer.PVZCreated(ctx, *pvz)
 
 	return *pvz, err
 }
 
-func (s *PVZService) GetPVZList(ctx context.Context, startDate, endDate *time.Time, page, limit int, role string) ([]models.PVZWithReceptions, error) {
+func (s *PVZService) GetPVZList(ctx context.Context, startDate, endDate *time.Time, createdBy, closedBy *uuid.UUID, page, limit int, role string) (_ []models.PVZWithReceptions, err error) {
+	ctx, span := startSpan(ctx, "PVZService.GetPVZList", attribute.Int("page", page), attribute.Int("limit", limit))
+	defer func() { endSpan(span, err) }()
+
 	if role != "employee" {
 		return nil, ErrAccessDenied
 	}
@@ -53,14 +75,14 @@ func (s *PVZService) GetPVZList(ctx context.Context, startDate, endDate *time.Ti
 		return nil, ErrPageParamIsInvalid
 	}
 
-	if limit <= 0 || limit > 30 {
-		return nil, ErrLimitParamIsInvalid
+	if limit <= 0 || limit > s.maxListLimit {
+		return nil, &LimitError{Max: s.maxListLimit}
 	}
 
 	if startDate != nil && endDate != nil && startDate.After(*endDate) {
 		return nil, ErrStartLaterThenEnd
 	}
 
-	arr, err := s.pvzRepo.GetPVZList(ctx, startDate, endDate, page, limit)
+	arr, err := s.pvzRepo.GetPVZList(ctx, startDate, endDate, createdBy, closedBy, page, limit)
 	return arr, err
 }
@@ -21,23 +21,37 @@ func (m *MockPVZRepository) InsertPVZ(ctx context.Context, city string) (*models
 	return args.Get(0).(*models.PVZ), args.Error(1)
 }
 
-func (m *MockPVZRepository) GetPVZList(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]models.PVZWithReceptions, error) {
-	args := m.Called(ctx, startDate, endDate, page, limit)
+func (m *MockPVZRepository) GetPVZ(ctx context.Context, id uuid.UUID) (*models.PVZWithReceptions, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.PVZWithReceptions), args.Error(1)
+}
+
+func (m *MockPVZRepository) GetPVZCity(ctx context.Context, id uuid.UUID) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPVZRepository) GetPVZList(ctx context.Context, startDate, endDate *time.Time, createdBy, closedBy *uuid.UUID, page, limit int) ([]models.PVZWithReceptions, error) {
+	args := m.Called(ctx, startDate, endDate, createdBy, closedBy, page, limit)
 	return args.Get(0).([]models.PVZWithReceptions), args.Error(1)
 }
 
 func TestPVZService_CreatePVZ(t *testing.T) {
 	mockRepo := new(MockPVZRepository)
-	pvzService := NewPVZService(mockRepo)
+	observer := new(MockObserver)
+	pvzService := NewPVZService(mockRepo, 30, observer)
 
 	t.Run("successful PVZ creation", func(t *testing.T) {
-		mockRepo.On("InsertPVZ", mock.Anything, "Москва").Return(&models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва"}, nil)
+		created := &models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва"}
+		mockRepo.On("InsertPVZ", mock.Anything, "Москва").Return(created, nil)
+		observer.On("PVZCreated", mock.Anything, *created).Once()
 
 		pvz, err := pvzService.CreatePVZ(context.Background(), "Москва", "moderator")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, pvz)
 		assert.Equal(t, "Москва", pvz.City)
+		observer.AssertExpectations(t)
 	})
 
 	t.Run("access denied for non-moderator", func(t *testing.T) {
@@ -70,14 +84,14 @@ func TestPVZService_CreatePVZ(t *testing.T) {
 
 func TestPVZService_GetPVZList(t *testing.T) {
 	mockRepo := new(MockPVZRepository)
-	pvzService := NewPVZService(mockRepo)
+	pvzService := NewPVZService(mockRepo, 30, NopObserver{})
 
 	t.Run("successful get PVZ list", func(t *testing.T) {
 		startDate := time.Now().Add(-24 * time.Hour)
 		endDate := time.Now()
-		mockRepo.On("GetPVZList", mock.Anything, &startDate, &endDate, 1, 10).Return([]models.PVZWithReceptions{{ID: uuid.New(), City: "Москва"}}, nil)
+		mockRepo.On("GetPVZList", mock.Anything, &startDate, &endDate, (*uuid.UUID)(nil), (*uuid.UUID)(nil), 1, 10).Return([]models.PVZWithReceptions{{ID: uuid.New(), City: "Москва"}}, nil)
 
-		pvzList, err := pvzService.GetPVZList(context.Background(), &startDate, &endDate, 1, 10, "employee")
+		pvzList, err := pvzService.GetPVZList(context.Background(), &startDate, &endDate, nil, nil, 1, 10, "employee")
 
 		assert.NoError(t, err)
 		assert.Len(t, pvzList, 1)
@@ -85,8 +99,20 @@ func TestPVZService_GetPVZList(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("filter by reception creator and closer", func(t *testing.T) {
+		createdBy := uuid.New()
+		closedBy := uuid.New()
+		mockRepo.On("GetPVZList", mock.Anything, (*time.Time)(nil), (*time.Time)(nil), &createdBy, &closedBy, 1, 10).Return([]models.PVZWithReceptions{}, nil)
+
+		pvzList, err := pvzService.GetPVZList(context.Background(), nil, nil, &createdBy, &closedBy, 1, 10, "employee")
+
+		assert.NoError(t, err)
+		assert.Empty(t, pvzList)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("access denied for non-employee", func(t *testing.T) {
-		pvzList, err := pvzService.GetPVZList(context.Background(), nil, nil, 1, 10, "moderator")
+		pvzList, err := pvzService.GetPVZList(context.Background(), nil, nil, nil, nil, 1, 10, "moderator")
 
 		assert.Error(t, err)
 		assert.Equal(t, ErrAccessDenied, err)
@@ -94,7 +120,7 @@ func TestPVZService_GetPVZList(t *testing.T) {
 	})
 
 	t.Run("invalid page parameter", func(t *testing.T) {
-		pvzList, err := pvzService.GetPVZList(context.Background(), nil, nil, -1, 10, "employee")
+		pvzList, err := pvzService.GetPVZList(context.Background(), nil, nil, nil, nil, -1, 10, "employee")
 
 		assert.Error(t, err)
 		assert.Equal(t, ErrPageParamIsInvalid, err)
@@ -102,10 +128,10 @@ func TestPVZService_GetPVZList(t *testing.T) {
 	})
 
 	t.Run("invalid limit parameter", func(t *testing.T) {
-		pvzList, err := pvzService.GetPVZList(context.Background(), nil, nil, 1, 31, "employee")
+		pvzList, err := pvzService.GetPVZList(context.Background(), nil, nil, nil, nil, 1, 31, "employee")
 
-		assert.Error(t, err)
-		assert.Equal(t, ErrLimitParamIsInvalid, err)
+		assert.ErrorIs(t, err, ErrLimitParamIsInvalid)
+		assert.Equal(t, &LimitError{Max: 30}, err)
 		assert.Nil(t, pvzList)
 	})
 
@@ -113,7 +139,7 @@ func TestPVZService_GetPVZList(t *testing.T) {
 		startDate := time.Now().Add(24 * time.Hour)
 		endDate := time.Now()
 
-		pvzList, err := pvzService.GetPVZList(context.Background(), &startDate, &endDate, 1, 10, "employee")
+		pvzList, err := pvzService.GetPVZList(context.Background(), &startDate, &endDate, nil, nil, 1, 10, "employee")
 
 		assert.Error(t, err)
 		assert.Equal(t, ErrStartLaterThenEnd, err)
@@ -122,9 +148,9 @@ func TestPVZService_GetPVZList(t *testing.T) {
 
 	t.Run("error while getting PVZ list", func(t *testing.T) {
 		mockRepo.ExpectedCalls = []*mock.Call{}
-		mockRepo.On("GetPVZList", mock.Anything, mock.Anything, mock.Anything, 1, 10).Return([]models.PVZWithReceptions{}, errors.New("database error"))
+		mockRepo.On("GetPVZList", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, 1, 10).Return([]models.PVZWithReceptions{}, errors.New("database error"))
 
-		pvzList, err := pvzService.GetPVZList(context.Background(), nil, nil, 1, 10, "employee")
+		pvzList, err := pvzService.GetPVZList(context.Background(), nil, nil, nil, nil, 1, 10, "employee")
 
 		assert.Error(t, err)
 		assert.Equal(t, "database error", err.Error())
@@ -6,43 +6,67 @@ import (
 	"pvz/internal/repository"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type ReceptionServiceInterface interface {
-	CreateReception(ctx context.Context, pvzID uuid.UUID, role string) (models.Reception, error)
-	CloseReception(ctx context.Context, pvzID uuid.UUID, role string) (models.Reception, error)
+	CreateReception(ctx context.Context, pvzID, userID uuid.UUID, role string) (models.Reception, error)
+	CloseReception(ctx context.Context, pvzID, userID uuid.UUID, role string) (models.Reception, error)
+	ForceCloseReception(ctx context.Context, pvzID, closedBy uuid.UUID) (models.Reception, error)
 }
 
 type ReceptionService struct {
 	receptionRepo repository.ReceptionRepositoryInterface
+	observer      Observer
 }
 
-func NewReceptionService(receptionRepo repository.ReceptionRepositoryInterface) *ReceptionService {
-	return &ReceptionService{receptionRepo: receptionRepo}
+func NewReceptionService(receptionRepo repository.ReceptionRepositoryInterface, observer Observer) *ReceptionService {
+	return &ReceptionService{receptionRepo: receptionRepo, observer: observer}
 }
 
-func (s *ReceptionService) CreateReception(ctx context.Context, pvzID uuid.UUID, role string) (models.Reception, error) {
+func (s *ReceptionService) CreateReception(ctx context.Context, pvzID, userID uuid.UUID, role string) (_ models.Reception, err error) {
+	ctx, span := startSpan(ctx, "ReceptionService.CreateReception", attribute.String("pvz.id", pvzID.String()))
+	defer func() { endSpan(span, err) }()
+
 	if role != "employee" {
 		return models.Reception{}, ErrAccessDenied
 	}
 
-	reception, err := s.receptionRepo.InsertReception(ctx, pvzID)
+	reception, err := s.receptionRepo.InsertReception(ctx, pvzID, userID)
 	if err != nil {
 		return models.Reception{}, err
 	}
+	s.observer.ReceptionOpened(ctx, *reception)
 
 	return *reception, nil
 }
 
-func (s *ReceptionService) CloseReception(ctx context.Context, pvzID uuid.UUID, role string) (models.Reception, error) {
+func (s *ReceptionService) CloseReception(ctx context.Context, pvzID, userID uuid.UUID, role string) (_ models.Reception, err error) {
+	ctx, span := startSpan(ctx, "ReceptionService.CloseReception", attribute.String("pvz.id", pvzID.String()))
+	defer func() { endSpan(span, err) }()
+
 	if role != "employee" {
 		return models.Reception{}, ErrAccessDenied
 	}
 
-	reception, err := s.receptionRepo.UpdateLastReceptionStatus(ctx, pvzID)
+	return s.closeReception(ctx, pvzID, userID)
+}
+
+// ForceCloseReception closes the open reception of a PVZ on behalf of closedBy whatever their role,
+// for operators who have to close a reception its employee cannot.
+func (s *ReceptionService) ForceCloseReception(ctx context.Context, pvzID, closedBy uuid.UUID) (_ models.Reception, err error) {
+	ctx, span := startSpan(ctx, "ReceptionService.ForceCloseReception", attribute.String("pvz.id", pvzID.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.closeReception(ctx, pvzID, closedBy)
+}
+
+func (s *ReceptionService) closeReception(ctx context.Context, pvzID, closedBy uuid.UUID) (models.Reception, error) {
+	reception, err := s.receptionRepo.UpdateLastReceptionStatus(ctx, pvzID, closedBy)
 	if err != nil {
 		return models.Reception{}, err
 	}
+	s.observer.ReceptionClosed(ctx, *reception)
 
 	return *reception, nil
 }
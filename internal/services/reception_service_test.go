@@ -16,21 +16,28 @@ type MockReceptionRepository struct {
 	mock.Mock
 }
 
-func (m *MockReceptionRepository) InsertReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
-	args := m.Called(ctx, pvzID)
+func (m *MockReceptionRepository) InsertReception(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error) {
+	args := m.Called(ctx, pvzID, userID)
 	return args.Get(0).(*models.Reception), args.Error(1)
 }
 
-func (m *MockReceptionRepository) UpdateLastReceptionStatus(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
-	args := m.Called(ctx, pvzID)
+func (m *MockReceptionRepository) CountOpenReceptions(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockReceptionRepository) UpdateLastReceptionStatus(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error) {
+	args := m.Called(ctx, pvzID, userID)
 	return args.Get(0).(*models.Reception), args.Error(1)
 }
 
 func TestReceptionService_CreateReception(t *testing.T) {
 	mockRepo := new(MockReceptionRepository)
-	receptionService := NewReceptionService(mockRepo)
+	observer := new(MockObserver)
+	receptionService := NewReceptionService(mockRepo, observer)
 
 	pvzID := uuid.New()
+	userID := uuid.New()
 	role := "employee"
 
 	t.Run("successful reception creation", func(t *testing.T) {
@@ -40,18 +47,20 @@ func TestReceptionService_CreateReception(t *testing.T) {
 			PVZID:    pvzID,
 			Status:   models.ReceptionStatusInProgress,
 		}
-		mockRepo.On("InsertReception", mock.Anything, pvzID).Return(expectedReception, nil)
+		mockRepo.On("InsertReception", mock.Anything, pvzID, userID).Return(expectedReception, nil)
+		observer.On("ReceptionOpened", mock.Anything, *expectedReception).Once()
 
-		reception, err := receptionService.CreateReception(context.Background(), pvzID, role)
+		reception, err := receptionService.CreateReception(context.Background(), pvzID, userID, role)
 
 		assert.NoError(t, err)
 		assert.Equal(t, *expectedReception, reception)
+		observer.AssertExpectations(t)
 	})
 
 	t.Run("access denied for non-employee role", func(t *testing.T) {
 		role = "admin"
 
-		reception, err := receptionService.CreateReception(context.Background(), pvzID, role)
+		reception, err := receptionService.CreateReception(context.Background(), pvzID, userID, role)
 
 		assert.Error(t, err)
 		assert.Equal(t, ErrAccessDenied, err)
@@ -61,9 +70,9 @@ func TestReceptionService_CreateReception(t *testing.T) {
 	t.Run("error while creating reception", func(t *testing.T) {
 		role = "employee"
 		mockRepo.ExpectedCalls = []*mock.Call{}
-		mockRepo.On("InsertReception", mock.Anything, pvzID).Return(&models.Reception{}, errors.New("some error"))
+		mockRepo.On("InsertReception", mock.Anything, pvzID, userID).Return(&models.Reception{}, errors.New("some error"))
 
-		reception, err := receptionService.CreateReception(context.Background(), pvzID, role)
+		reception, err := receptionService.CreateReception(context.Background(), pvzID, userID, role)
 
 		assert.Error(t, err)
 		assert.Equal(t, "some error", err.Error())
@@ -73,9 +82,11 @@ func TestReceptionService_CreateReception(t *testing.T) {
 
 func TestReceptionService_CloseReception(t *testing.T) {
 	mockRepo := new(MockReceptionRepository)
-	receptionService := NewReceptionService(mockRepo)
+	observer := new(MockObserver)
+	receptionService := NewReceptionService(mockRepo, observer)
 
 	pvzID := uuid.New()
+	userID := uuid.New()
 	role := "employee"
 
 	t.Run("successful reception close", func(t *testing.T) {
@@ -83,20 +94,23 @@ func TestReceptionService_CloseReception(t *testing.T) {
 			ID:       uuid.New(),
 			DateTime: time.Now(),
 			PVZID:    pvzID,
-			Status:   models.ReceptionStatusInProgress,
+			Status:   models.ReceptionStatusClosed,
+			ClosedBy: &userID,
 		}
-		mockRepo.On("UpdateLastReceptionStatus", mock.Anything, pvzID).Return(expectedReception, nil)
+		mockRepo.On("UpdateLastReceptionStatus", mock.Anything, pvzID, userID).Return(expectedReception, nil)
+		observer.On("ReceptionClosed", mock.Anything, *expectedReception).Once()
 
-		reception, err := receptionService.CloseReception(context.Background(), pvzID, role)
+		reception, err := receptionService.CloseReception(context.Background(), pvzID, userID, role)
 
 		assert.NoError(t, err)
 		assert.Equal(t, *expectedReception, reception)
+		observer.AssertExpectations(t)
 	})
 
 	t.Run("access denied for non-employee role", func(t *testing.T) {
 		role = "admin"
 
-		reception, err := receptionService.CloseReception(context.Background(), pvzID, role)
+		reception, err := receptionService.CloseReception(context.Background(), pvzID, userID, role)
 
 		assert.Error(t, err)
 		assert.Equal(t, ErrAccessDenied, err)
@@ -106,12 +120,37 @@ func TestReceptionService_CloseReception(t *testing.T) {
 	t.Run("error while closing reception", func(t *testing.T) {
 		mockRepo.ExpectedCalls = []*mock.Call{}
 		role = "employee"
-		mockRepo.On("UpdateLastReceptionStatus", mock.Anything, pvzID).Return(&models.Reception{}, errors.New("some error"))
+		mockRepo.On("UpdateLastReceptionStatus", mock.Anything, pvzID, userID).Return(&models.Reception{}, errors.New("some error"))
 
-		reception, err := receptionService.CloseReception(context.Background(), pvzID, role)
+		reception, err := receptionService.CloseReception(context.Background(), pvzID, userID, role)
 
 		assert.Error(t, err)
 		assert.Equal(t, "some error", err.Error())
 		assert.Empty(t, reception)
 	})
 }
+
+func TestReceptionService_ForceCloseReception(t *testing.T) {
+	mockRepo := new(MockReceptionRepository)
+	observer := new(MockObserver)
+	receptionService := NewReceptionService(mockRepo, observer)
+
+	pvzID := uuid.New()
+	moderatorID := uuid.New()
+	expectedReception := &models.Reception{
+		ID:       uuid.New(),
+		DateTime: time.Now(),
+		PVZID:    pvzID,
+		Status:   models.ReceptionStatusClosed,
+		ClosedBy: &moderatorID,
+	}
+	mockRepo.On("UpdateLastReceptionStatus", mock.Anything, pvzID, moderatorID).Return(expectedReception, nil)
+	// counted in receptions_closed like any other close
+	observer.On("ReceptionClosed", mock.Anything, *expectedReception).Once()
+
+	reception, err := receptionService.ForceCloseReception(context.Background(), pvzID, moderatorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, *expectedReception, reception)
+	observer.AssertExpectations(t)
+}
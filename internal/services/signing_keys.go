@@ -0,0 +1,122 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// minRSABits is the smallest RSA key accepted, as RFC 7518 requires for RS256.
+const minRSABits = 2048
+
+// SigningKey is an asymmetric key tokens are signed with, named in their kid header.
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	signer crypto.Signer
+	// ActiveFrom is when the key starts signing; zero means from the start.
+	ActiveFrom time.Time
+}
+
+// LoadSigningKey reads an RSA (RS256) or Ed25519 (EdDSA) private key from a PEM file, e.g. one made
+// by `openssl genpkey -algorithm ed25519`.
+func LoadSigningKey(id, file string, activeFrom time.Time) (SigningKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("failed to read key %s: %w", id, err)
+	}
+	key, err := ParseSigningKey(id, data, activeFrom)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("key %s in %s: %w", id, file, err)
+	}
+	return key, nil
+}
+
+// ParseSigningKey reads a PKCS #8 or, for RSA, PKCS #1 private key in PEM.
+func ParseSigningKey(id string, data []byte, activeFrom time.Time) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, errors.New("no PEM data")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return SigningKey{}, fmt.Errorf("unexpected PEM block %q, want a private key", block.Type)
+	}
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	if rsaKey, ok := parsed.(*rsa.PrivateKey); ok && rsaKey.N.BitLen() < minRSABits {
+		return SigningKey{}, fmt.Errorf("RSA key has %d bits, at least %d are required", rsaKey.N.BitLen(), minRSABits)
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return SigningKey{}, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+	return NewSigningKey(id, signer, activeFrom)
+}
+
+// NewSigningKey wraps an RSA or Ed25519 private key.
+func NewSigningKey(id string, signer crypto.Signer, activeFrom time.Time) (SigningKey, error) {
+	switch signer.(type) {
+	case *rsa.PrivateKey:
+		return SigningKey{ID: id, Method: jwt.SigningMethodRS256, signer: signer, ActiveFrom: activeFrom}, nil
+	case ed25519.PrivateKey:
+		return SigningKey{ID: id, Method: jwt.SigningMethodEdDSA, signer: signer, ActiveFrom: activeFrom}, nil
+	}
+	return SigningKey{}, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", signer)
+}
+
+func (k SigningKey) Public() crypto.PublicKey {
+	return k.signer.Public()
+}
+
+// JWKS is a JSON Web Key Set (RFC 7517) of the public keys tokens are verified with.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is a public RSA or Ed25519 key (RFC 7517, RFC 8037).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+func (k SigningKey) JWK() JWK {
+	jwk := JWK{Use: "sig", Alg: k.Method.Alg(), Kid: k.ID}
+	switch public := k.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
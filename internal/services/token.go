@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pvz/internal/models"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenManager issues and validates the service's JWTs. It is shared by the user service, the HTTP
+// middleware and the gRPC interceptor, so all of them use the same keys, lifetime, issuer and audience.
+//
+// Tokens are signed with HS256 and the shared secret or, once signing keys are configured, with RS256 or
+// EdDSA. Then each token names its key in the kid header, and other services verify it with the public
+// keys from JWKS, without the secret.
+type TokenManager struct {
+	secret []byte
+	// hs256Until is when signing keys replaced the secret; HS256 tokens issued later are refused.
+	hs256Until time.Time
+	// keys are ordered by ActiveFrom; the last active one signs.
+	keys     []SigningKey
+	ttl      time.Duration
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+func NewTokenManager(secret string, ttl time.Duration, issuer, audience string) *TokenManager {
+	return &TokenManager{secret: []byte(secret), ttl: ttl, issuer: issuer, audience: audience, now: time.Now}
+}
+
+// NewKeyedTokenManager signs with the key of keys whose ActiveFrom passed last, so a key added with a
+// later ActiveFrom takes over by itself. HS256 tokens are refused unless hs256Until is set: then the
+// secret verifies those issued until hs256Until, but no new ones are signed with it.
+func NewKeyedTokenManager(keys []SigningKey, secret string, hs256Until time.Time, ttl time.Duration,
+	issuer, audience string) (*TokenManager, error) {
+	sorted := slices.Clone(keys)
+	slices.SortStableFunc(sorted, func(a, b SigningKey) int { return a.ActiveFrom.Compare(b.ActiveFrom) })
+	ids := make(map[string]bool)
+	for _, key := range sorted {
+		if ids[key.ID] {
+			return nil, fmt.Errorf("key id %q is used twice", key.ID)
+		}
+		ids[key.ID] = true
+	}
+
+	m := &TokenManager{keys: sorted, ttl: ttl, issuer: issuer, audience: audience, now: time.Now}
+	if secret != "" && !hs256Until.IsZero() {
+		m.secret = []byte(secret)
+		m.hs256Until = hs256Until
+	}
+	if _, err := m.signingKey(m.now()); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// signingKey returns the key whose ActiveFrom passed last.
+func (m *TokenManager) signingKey(now time.Time) (SigningKey, error) {
+	for i := len(m.keys) - 1; i >= 0; i-- {
+		if !m.keys[i].ActiveFrom.After(now) {
+			return m.keys[i], nil
+		}
+	}
+	return SigningKey{}, errors.New("none of the signing keys is active yet")
+}
+
+// verifyingKeys are the keys tokens may still be signed with: every key but those replaced more than a
+// token lifetime ago. Keys that only sign later are included, so verifiers get them in advance.
+func (m *TokenManager) verifyingKeys(now time.Time) []SigningKey {
+	var keys []SigningKey
+	for i, key := range m.keys {
+		if i+1 < len(m.keys) && now.After(m.keys[i+1].ActiveFrom.Add(m.ttl)) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// JWKS returns the public keys of verifyingKeys for other services. HS256 has none to publish.
+func (m *TokenManager) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range m.verifyingKeys(m.now()) {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+	return set
+}
+
+// TokenCheckerInterface decides whether a valid token is still honoured, e.g. whether its user has
+// not been deactivated since it was issued.
+type TokenCheckerInterface interface {
+	CheckToken(ctx context.Context, claims *CustomClaims) error
+}
+
+// TokenCheckerFunc adapts a function to TokenCheckerInterface.
+type TokenCheckerFunc func(ctx context.Context, claims *CustomClaims) error
+
+func (f TokenCheckerFunc) CheckToken(ctx context.Context, claims *CustomClaims) error {
+	return f(ctx, claims)
+}
+
+func (m *TokenManager) Generate(user *models.User) (string, error) {
+	return m.generate(CustomClaims{UserID: user.ID, Role: user.Role})
+}
+
+// GenerateDummy issues a token of the role that no user stands behind, marked as such.
+func (m *TokenManager) GenerateDummy(id uuid.UUID, role string) (string, error) {
+	return m.generate(CustomClaims{UserID: id, Role: role, Dummy: true})
+}
+
+func (m *TokenManager) generate(claims CustomClaims) (string, error) {
+	now := m.now()
+	claims.StandardClaims = jwt.StandardClaims{
+		Subject:   claims.UserID.String(),
+		Issuer:    m.issuer,
+		Audience:  m.audience,
+		ExpiresAt: now.Add(m.ttl).Unix(),
+		// compared with the user's tokens_revoked_at
+		IssuedAt: now.Unix(),
+	}
+
+	if len(m.keys) == 0 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	}
+
+	key, err := m.signingKey(now)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signer)
+}
+
+func (m *TokenManager) Parse(tokenString string) (*CustomClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, m.verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*CustomClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	// a token of another deployment sharing the secret, or one meant for another service, is refused
+	if !claims.VerifyIssuer(m.issuer, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if !claims.VerifyAudience(m.audience, true) {
+		return nil, fmt.Errorf("%w: unexpected audience %q", ErrInvalidToken, claims.Audience)
+	}
+
+	return claims, nil
+}
+
+// verificationKey picks the key by the kid header and refuses tokens whose alg does not match it, so a
+// public key is never taken for an HMAC secret. Next to signing keys, an HS256 token is only accepted if
+// it was issued before hs256Until.
+func (m *TokenManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if len(m.secret) == 0 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		if len(m.keys) > 0 {
+			claims, ok := token.Claims.(*CustomClaims)
+			if !ok || claims.IssuedAt == 0 || time.Unix(claims.IssuedAt, 0).After(m.hs256Until) {
+				return nil, fmt.Errorf("HS256 tokens issued after %s are not accepted", m.hs256Until.Format(time.RFC3339))
+			}
+		}
+		return m.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	for _, key := range m.verifyingKeys(m.now()) {
+		if key.ID != kid {
+			continue
+		}
+		if key.Method.Alg() != token.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %v for key %q", token.Header["alg"], kid)
+		}
+		return key.Public(), nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"pvz/internal/models"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenManager(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Role: "employee"}
+
+	t.Run("round trip", func(t *testing.T) {
+		tokens := NewTokenManager("secret", time.Hour, "pvz-service", "pvz-api")
+
+		token, err := tokens.Generate(user)
+		assert.NoError(t, err)
+
+		claims, err := tokens.Parse(token)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+		assert.Equal(t, "employee", claims.Role)
+		assert.InDelta(t, time.Now().Add(time.Hour).Unix(), claims.ExpiresAt, 5)
+		assert.InDelta(t, time.Now().Unix(), claims.IssuedAt, 5)
+		assert.Equal(t, user.ID.String(), claims.Subject)
+		assert.Equal(t, "pvz-service", claims.Issuer)
+		assert.Equal(t, "pvz-api", claims.Audience)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		tokens := NewTokenManager("secret", time.Hour, "pvz-service", "pvz-api")
+		tokens.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+
+		token, err := tokens.Generate(user)
+		assert.NoError(t, err)
+
+		_, err = tokens.Parse(token)
+		assert.Error(t, err)
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		token, err := NewTokenManager("secret", time.Hour, "pvz-service", "pvz-api").Generate(user)
+		assert.NoError(t, err)
+
+		_, err = NewTokenManager("other", time.Hour, "pvz-service", "pvz-api").Parse(token)
+		assert.Error(t, err)
+	})
+
+	t.Run("other issuer", func(t *testing.T) {
+		token, err := NewTokenManager("secret", time.Hour, "staging", "pvz-api").Generate(user)
+		assert.NoError(t, err)
+
+		_, err = NewTokenManager("secret", time.Hour, "pvz-service", "pvz-api").Parse(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("other audience", func(t *testing.T) {
+		token, err := NewTokenManager("secret", time.Hour, "pvz-service", "billing").Generate(user)
+		assert.NoError(t, err)
+
+		_, err = NewTokenManager("secret", time.Hour, "pvz-service", "pvz-api").Parse(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("unsigned token", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, CustomClaims{UserID: user.ID, Role: "moderator"}).
+			SignedString(jwt.UnsafeAllowNoneSignatureType)
+		assert.NoError(t, err)
+
+		_, err = NewTokenManager("secret", time.Hour, "pvz-service", "pvz-api").Parse(token)
+		assert.Error(t, err)
+	})
+}
+
+func TestTokenManager_SigningKeys(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Role: "employee"}
+	now := time.Now()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	current, err := NewSigningKey("current", edKey, time.Time{})
+	require.NoError(t, err)
+	next, err := NewSigningKey("next", rsaKey, now.Add(24*time.Hour))
+	require.NoError(t, err)
+
+	newManager := func(t *testing.T, keys ...SigningKey) *TokenManager {
+		tokens, err := NewKeyedTokenManager(keys, "", time.Time{}, time.Hour, "pvz-service", "pvz-api")
+		require.NoError(t, err)
+		return tokens
+	}
+	kid := func(t *testing.T, token string) string {
+		parsed, _, err := new(jwt.Parser).ParseUnverified(token, &CustomClaims{})
+		require.NoError(t, err)
+		return parsed.Header["kid"].(string) + " " + parsed.Method.Alg()
+	}
+
+	t.Run("signs with the active key", func(t *testing.T) {
+		tokens := newManager(t, next, current)
+
+		token, err := tokens.Generate(user)
+		require.NoError(t, err)
+		assert.Equal(t, "current EdDSA", kid(t, token))
+
+		claims, err := tokens.Parse(token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+	})
+
+	t.Run("scheduled rotation", func(t *testing.T) {
+		tokens := newManager(t, current, next)
+		oldToken, err := tokens.Generate(user)
+		require.NoError(t, err)
+
+		// the next key signs from its ActiveFrom, tokens of the old key stay valid for a token lifetime
+		tokens.now = func() time.Time { return now.Add(24*time.Hour + time.Minute) }
+		newToken, err := tokens.Generate(user)
+		require.NoError(t, err)
+		assert.Equal(t, "next RS256", kid(t, newToken))
+		assert.Equal(t, []string{"current", "next"}, jwkIDs(tokens.JWKS()))
+
+		// then the old key is retired
+		tokens.now = func() time.Time { return now.Add(26 * time.Hour) }
+		_, err = tokens.Parse(oldToken)
+		assert.ErrorContains(t, err, `unknown key id "current"`)
+		assert.Equal(t, []string{"next"}, jwkIDs(tokens.JWKS()))
+	})
+
+	t.Run("publishes keys before they sign", func(t *testing.T) {
+		set := newManager(t, current, next).JWKS()
+
+		require.Equal(t, []string{"current", "next"}, jwkIDs(set))
+		assert.Equal(t, JWK{Kty: "OKP", Use: "sig", Alg: "EdDSA", Kid: "current", Crv: "Ed25519",
+			X: base64.RawURLEncoding.EncodeToString(edKey.Public().(ed25519.PublicKey))}, set.Keys[0])
+		assert.Equal(t, "RSA", set.Keys[1].Kty)
+		assert.Equal(t, "AQAB", set.Keys[1].E)
+		assert.Empty(t, NewTokenManager("secret", time.Hour, "pvz-service", "pvz-api").JWKS().Keys)
+	})
+
+	t.Run("HS256 compatibility", func(t *testing.T) {
+		legacy, err := NewTokenManager("secret", time.Hour, "pvz-service", "pvz-api").Generate(user)
+		require.NoError(t, err)
+		withSecret := func(t *testing.T, hs256Until time.Time) *TokenManager {
+			tokens, err := NewKeyedTokenManager([]SigningKey{current}, "secret", hs256Until, time.Hour, "pvz-service", "pvz-api")
+			require.NoError(t, err)
+			return tokens
+		}
+
+		_, err = withSecret(t, now.Add(time.Minute)).Parse(legacy)
+		assert.NoError(t, err, "HS256 tokens issued before the cutoff stay valid")
+		_, err = withSecret(t, now.Add(-time.Minute)).Parse(legacy)
+		assert.ErrorContains(t, err, "HS256 tokens issued after")
+		_, err = withSecret(t, time.Time{}).Parse(legacy)
+		assert.Error(t, err, "the secret alone does not keep HS256 tokens valid")
+		_, err = newManager(t, current).Parse(legacy)
+		assert.Error(t, err)
+	})
+
+	t.Run("alg does not match the key", func(t *testing.T) {
+		forged := jwt.NewWithClaims(jwt.SigningMethodRS256, CustomClaims{
+			UserID: user.ID, Role: "moderator",
+			StandardClaims: jwt.StandardClaims{Issuer: "pvz-service", Audience: "pvz-api", ExpiresAt: now.Add(time.Hour).Unix()},
+		})
+		forged.Header["kid"] = "current"
+		token, err := forged.SignedString(rsaKey)
+		require.NoError(t, err)
+
+		_, err = newManager(t, current).Parse(token)
+		assert.ErrorContains(t, err, "unexpected signing method")
+	})
+
+	t.Run("no active key", func(t *testing.T) {
+		_, err := NewKeyedTokenManager([]SigningKey{next}, "", time.Time{}, time.Hour, "pvz-service", "pvz-api")
+		assert.Error(t, err)
+	})
+
+	t.Run("duplicate key id", func(t *testing.T) {
+		_, err := NewKeyedTokenManager([]SigningKey{current, current}, "", time.Time{}, time.Hour, "pvz-service", "pvz-api")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseSigningKey(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pkcs8 := func(key interface{}) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+
+	key, err := ParseSigningKey("ed", pkcs8(edKey), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", key.Method.Alg())
+
+	key, err = ParseSigningKey("rsa", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", key.Method.Alg())
+
+	_, err = ParseSigningKey("weak", pkcs8(weakKey), time.Time{})
+	assert.ErrorContains(t, err, "1024 bits")
+	_, err = ParseSigningKey("ec", pkcs8(ecKey), time.Time{})
+	assert.ErrorContains(t, err, "unsupported key type")
+	_, err = ParseSigningKey("public", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{1}}), time.Time{})
+	assert.Error(t, err)
+	_, err = ParseSigningKey("garbage", []byte("not a key"), time.Time{})
+	assert.Error(t, err)
+}
+
+func jwkIDs(set JWKS) []string {
+	var ids []string
+	for _, key := range set.Keys {
+		ids = append(ids, key.Kid)
+	}
+	return ids
+}
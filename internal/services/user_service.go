@@ -2,20 +2,24 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"os"
+	"log/slog"
 	"pvz/internal/models"
 	"pvz/internal/repository"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type CustomClaims struct {
 	UserID uuid.UUID `json:"user_id"`
 	Role   string    `json:"role"`
+	// Dummy marks the tokens from DummyLogin, which have no user behind them.
+	Dummy bool `json:"dummy,omitempty"`
 	jwt.StandardClaims
 }
 
@@ -25,20 +29,94 @@ var allowedRoles = map[string]bool{
 }
 
 type UserServiceInterface interface {
-	RegisterUser(ctx context.Context, email, password, role string) (models.User, error)
-	LoginUser(ctx context.Context, email, password string) (string, error)
+	RegisterUser(ctx context.Context, email, password, role string, auth RegistrationAuth) (models.User, error)
+	LoginUser(ctx context.Context, email, password string, client ClientInfo) (string, error)
+	DummyLogin(role string) (string, error)
+	ResetPassword(ctx context.Context, email, password string) error
+	ChangePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error
+	UnlockUser(ctx context.Context, id uuid.UUID, role string) error
+	GetLoginHistory(ctx context.Context, id uuid.UUID, role string) ([]models.LoginAttempt, error)
+	GetUsers(ctx context.Context, filter repository.UserFilter, page, limit int, role string) ([]models.User, error)
+	GetUser(ctx context.Context, id uuid.UUID, role string) (models.User, error)
+	ChangeRole(ctx context.Context, id uuid.UUID, newRole string, userID uuid.UUID, role string) error
+	SetDeactivated(ctx context.Context, id uuid.UUID, deactivated bool, userID uuid.UUID, role string) error
+	DeleteUser(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) error
+	GetProfile(ctx context.Context, userID uuid.UUID, role string) (models.Profile, error)
 }
 
+// RegistrationAuth is what entitles a caller to register a moderator: a moderator's own token
+// (CallerRole) or an invitation code. Employees register without either.
+type RegistrationAuth struct {
+	CallerRole     string
+	InvitationCode string
+}
+
+// ClientInfo describes where a login comes from, for the login history.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// Lockout locks an account for Duration after MaxAttempts wrong passwords in a row; MaxAttempts of 0 disables it.
+type Lockout struct {
+	MaxAttempts int
+	Duration    time.Duration
+}
+
+// loginHistoryLimit is how many of the latest attempts GetLoginHistory returns.
+const loginHistoryLimit = 50
+
+// dummyPasswordHash is compared against when the email is unknown, so that a login for a missing
+// account takes as long as one with a wrong password and response times do not reveal which emails exist.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
 type UserService struct {
-	userRepo repository.UserRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	loginHistory repository.LoginAttemptRepositoryInterface
+	tokens       *TokenManager
+	lockout      Lockout
+	policy       PasswordPolicy
+	// listMax is the largest page GetUsers returns.
+	listMax int
+	// devMode enables DummyLogin.
+	devMode bool
+	now     func() time.Time
 }
 
-func NewUserService(userRepo repository.UserRepositoryInterface) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepositoryInterface, loginHistory repository.LoginAttemptRepositoryInterface,
+	tokens *TokenManager, lockout Lockout, policy PasswordPolicy, listMax int, devMode bool) *UserService {
+	return &UserService{userRepo: userRepo, loginHistory: loginHistory, tokens: tokens, lockout: lockout, policy: policy,
+		listMax: listMax, devMode: devMode, now: time.Now}
 }
 
-func (u *UserService) RegisterUser(ctx context.Context, email, password, role string) (models.User, error) {
-	user, err := u.userRepo.InsertUser(ctx, email, password, role)
+// RegisterUser creates an account. A moderator account needs a moderator caller or an invitation
+// code, which is used up by the registration.
+func (u *UserService) RegisterUser(ctx context.Context, email, password, role string, auth RegistrationAuth) (_ models.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.RegisterUser", attribute.String("user.role", role))
+	defer func() { endSpan(span, err) }()
+
+	if _, ok := allowedRoles[role]; !ok {
+		return models.User{}, ErrInvalidRole
+	}
+	if err := u.policy.Check(password); err != nil {
+		return models.User{}, err
+	}
+
+	var user *models.User
+	switch {
+	case role != "moderator" || auth.CallerRole == "moderator":
+		user, err = u.userRepo.InsertUser(ctx, email, password, role)
+	case auth.InvitationCode != "":
+		user, err = u.userRepo.InsertInvitedUser(ctx, email, password, role, hashSecretToken(auth.InvitationCode))
+	default:
+		return models.User{}, ErrAccessDenied
+	}
 	if err != nil {
 		return models.User{}, err
 	}
@@ -46,51 +124,320 @@ func (u *UserService) RegisterUser(ctx context.Context, email, password, role st
 	return *user, nil
 }
 
-func (u *UserService) LoginUser(ctx context.Context, email, password string) (string, error) {
+// LoginUser checks the password and issues a token. Unknown emails, wrong passwords and locked accounts
+// all fail with an error that the API reports the same way; every attempt goes to the login history.
+func (u *UserService) LoginUser(ctx context.Context, email, password string, client ClientInfo) (_ string, err error) {
+	ctx, span := startSpan(ctx, "UserService.LoginUser")
+	defer func() { endSpan(span, err) }()
+
 	user, err := u.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
 		return "", err
 	}
 
+	attempt := models.LoginAttempt{Email: email, IP: client.IP, UserAgent: client.UserAgent}
 	if user == nil {
-		return "", errors.New("no such user")
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		u.recordAttempt(ctx, attempt, models.LoginFailureUnknownEmail)
+		return "", ErrUserNotFound
 	}
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
-	if err != nil {
-		return "", errors.New("wrong password")
+	attempt.UserID = &user.ID
+
+	// the hash is compared even for locked accounts, so they answer as slowly as the others
+	passwordErr := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if user.Locked(u.now()) {
+		u.recordAttempt(ctx, attempt, models.LoginFailureLocked)
+		return "", ErrAccountLocked
+	}
+	if passwordErr != nil {
+		if err := u.countFailure(ctx, user); err != nil {
+			return "", err
+		}
+		u.recordAttempt(ctx, attempt, models.LoginFailureWrongPassword)
+		return "", ErrWrongPassword
+	}
+	// reported only to whoever knows the password, so it does not reveal which emails exist
+	if user.DeactivatedAt != nil {
+		u.recordAttempt(ctx, attempt, models.LoginFailureDeactivated)
+		return "", ErrAccountDeactivated
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := u.userRepo.ResetLoginFailures(ctx, user.ID); err != nil {
+			return "", err
+		}
 	}
 
-	token, err := generateJWT(user)
+	token, err := u.tokens.Generate(user)
 	if err != nil {
 		return "", err
 	}
 
+	attempt.Success = true
+	u.recordAttempt(ctx, attempt, "")
 	return token, nil
 }
 
-func DummyLogin(role string) (string, error) {
-	if _, ok := allowedRoles[role]; !ok {
-		return "", ErrInvalidRole
+func (u *UserService) countFailure(ctx context.Context, user *models.User) error {
+	if u.lockout.MaxAttempts == 0 {
+		return nil
+	}
+	lockedUntil, err := u.userRepo.RecordLoginFailure(ctx, user.ID, u.lockout.MaxAttempts, u.now().Add(u.lockout.Duration))
+	if err != nil {
+		return err
 	}
+	if lockedUntil != nil && lockedUntil.After(u.now()) {
+		slog.WarnContext(ctx, "account locked after failed logins",
+			slog.String("user_id", user.ID.String()), slog.Time("locked_until", *lockedUntil))
+	}
+	return nil
+}
 
-	user := models.User{
-		ID:   uuid.New(),
-		Role: role,
+// recordAttempt writes the login history. A failed write is only logged: the history must not
+// decide whether a user can log in.
+func (u *UserService) recordAttempt(ctx context.Context, attempt models.LoginAttempt, failureReason string) {
+	attempt.FailureReason = failureReason
+	if err := u.loginHistory.InsertLoginAttempt(ctx, attempt); err != nil {
+		slog.ErrorContext(ctx, "failed to record login attempt", slog.Any("error", err))
 	}
+}
 
-	token, err := generateJWT(&user)
+func (u *UserService) DummyLogin(role string) (string, error) {
+	if !u.devMode {
+		return "", ErrDummyLoginDisabled
+	}
+	if _, ok := allowedRoles[role]; !ok {
+		return "", ErrInvalidRole
+	}
+
+	token, err := u.tokens.GenerateDummy(uuid.New(), role)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate token for DummyLogin: %w", err)
 	}
 	return token, nil
 }
 
-func generateJWT(user *models.User) (string, error) {
-	claims := CustomClaims{
-		UserID: user.ID,
-		Role:   user.Role,
+// ResetPassword sets a new password for the user with the given email.
+func (u *UserService) ResetPassword(ctx context.Context, email, password string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.ResetPassword")
+	defer func() { endSpan(span, err) }()
+
+	if err := u.policy.Check(password); err != nil {
+		return err
+	}
+
+	user, err := u.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	return u.userRepo.UpdatePassword(ctx, user.ID, password)
+}
+
+// ChangePassword replaces the password of a user who knows the current one. A wrong current password
+// counts towards the lockout like a failed login, so a stolen token cannot be used to guess it.
+func (u *UserService) ChangePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.ChangePassword")
+	defer func() { endSpan(span, err) }()
+
+	user, err := u.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return repository.ErrUserNotFound
+	}
+	if user.Locked(u.now()) {
+		return ErrAccountLocked
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)) != nil {
+		if err := u.countFailure(ctx, user); err != nil {
+			return err
+		}
+		return ErrWrongCurrentPassword
+	}
+
+	if err := u.policy.Check(newPassword); err != nil {
+		return err
+	}
+	if newPassword == currentPassword {
+		return &PasswordPolicyError{Violations: []string{PasswordUnchanged}}
+	}
+
+	return u.userRepo.UpdatePassword(ctx, user.ID, newPassword)
+}
+
+// UnlockUser lifts a lockout and clears the count of failed logins.
+func (u *UserService) UnlockUser(ctx context.Context, id uuid.UUID, role string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.UnlockUser", attribute.String("user.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return ErrAccessDenied
+	}
+
+	return u.userRepo.ResetLoginFailures(ctx, id)
+}
+
+// GetLoginHistory returns the latest login attempts of the user, newest first.
+func (u *UserService) GetLoginHistory(ctx context.Context, id uuid.UUID, role string) (_ []models.LoginAttempt, err error) {
+	ctx, span := startSpan(ctx, "UserService.GetLoginHistory", attribute.String("user.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return nil, ErrAccessDenied
+	}
+
+	user, err := u.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, repository.ErrUserNotFound
+	}
+
+	return u.loginHistory.GetLoginAttempts(ctx, id, loginHistoryLimit)
+}
+
+// CheckToken refuses the tokens of deleted and deactivated users and the ones issued before the user's
+// tokens were revoked. Tokens from DummyLogin belong to no user, so they pass only in dev mode; a token
+// of a deleted user is refused in dev mode too.
+func (u *UserService) CheckToken(ctx context.Context, claims *CustomClaims) (err error) {
+	ctx, span := startSpan(ctx, "UserService.CheckToken", attribute.String("user.id", claims.UserID.String()))
+	defer func() { endSpan(span, err) }()
+
+	user, err := u.userRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case user == nil && claims.Dummy && u.devMode:
+		return nil
+	case user == nil:
+		return fmt.Errorf("%w: user does not exist", ErrTokenRevoked)
+	case user.DeactivatedAt != nil:
+		return fmt.Errorf("%w: %w", ErrTokenRevoked, ErrAccountDeactivated)
+	// iat has whole seconds, so a token issued in the second of the revocation is still honoured
+	case user.TokensRevokedAt != nil && claims.IssuedAt < user.TokensRevokedAt.Unix():
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// GetProfile describes the authenticated user. A DummyLogin token has no user behind it, so in dev
+// mode its profile is made up from the token: no email and no PVZs.
+func (u *UserService) GetProfile(ctx context.Context, userID uuid.UUID, role string) (_ models.Profile, err error) {
+	ctx, span := startSpan(ctx, "UserService.GetProfile", attribute.String("user.id", userID.String()))
+	defer func() { endSpan(span, err) }()
+
+	user, err := u.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return models.Profile{}, err
+	}
+	if user == nil {
+		if !u.devMode {
+			return models.Profile{}, repository.ErrUserNotFound
+		}
+		return models.Profile{ID: userID, Role: role, AssignedPVZs: []uuid.UUID{}, Dummy: true}, nil
+	}
+
+	pvzIDs, err := u.userRepo.GetAssignedPVZs(ctx, userID)
+	if err != nil {
+		return models.Profile{}, err
+	}
+	return models.Profile{ID: user.ID, Email: user.Email, Role: user.Role, AssignedPVZs: pvzIDs}, nil
+}
+
+// GetUsers returns a page of the users that match the filter, ordered by email.
+func (u *UserService) GetUsers(ctx context.Context, filter repository.UserFilter, page, limit int, role string) (_ []models.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.GetUsers", attribute.Int("page", page), attribute.Int("limit", limit))
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return nil, ErrAccessDenied
+	}
+	if filter.Role != "" && !allowedRoles[filter.Role] {
+		return nil, ErrInvalidRole
+	}
+	if page <= 0 {
+		return nil, ErrPageParamIsInvalid
+	}
+	if limit <= 0 || limit > u.listMax {
+		return nil, &LimitError{Max: u.listMax}
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return u.userRepo.GetUsers(ctx, filter, page, limit)
+}
+
+func (u *UserService) GetUser(ctx context.Context, id uuid.UUID, role string) (_ models.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.GetUser", attribute.String("user.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	if role != "moderator" {
+		return models.User{}, ErrAccessDenied
+	}
+
+	user, err := u.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user == nil {
+		return models.User{}, repository.ErrUserNotFound
+	}
+	return *user, nil
+}
+
+// ChangeRole gives the user another role. The user's tokens are revoked, as they carry the old role.
+func (u *UserService) ChangeRole(ctx context.Context, id uuid.UUID, newRole string, userID uuid.UUID, role string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.ChangeRole", attribute.String("user.id", id.String()), attribute.String("user.role", newRole))
+	defer func() { endSpan(span, err) }()
+
+	if err := checkManagedUser(id, userID, role); err != nil {
+		return err
+	}
+	if !allowedRoles[newRole] {
+		return ErrInvalidRole
+	}
+
+	return u.userRepo.UpdateRole(ctx, id, newRole)
+}
+
+// SetDeactivated deactivates an account, which refuses its logins and tokens, or reactivates it.
+// A reactivated user logs in again: the tokens from before the deactivation stay refused.
+func (u *UserService) SetDeactivated(ctx context.Context, id uuid.UUID, deactivated bool, userID uuid.UUID, role string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.SetDeactivated", attribute.String("user.id", id.String()), attribute.Bool("deactivated", deactivated))
+	defer func() { endSpan(span, err) }()
+
+	if err := checkManagedUser(id, userID, role); err != nil {
+		return err
+	}
+
+	return u.userRepo.SetDeactivated(ctx, id, deactivated)
+}
+
+// DeleteUser removes the account. Receptions and products keep the id of the user who made them.
+func (u *UserService) DeleteUser(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.DeleteUser", attribute.String("user.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	if err := checkManagedUser(id, userID, role); err != nil {
+		return err
+	}
+
+	return u.userRepo.DeleteUser(ctx, id)
+}
+
+// checkManagedUser lets moderators manage accounts other than their own, so that the last moderator
+// cannot lock everyone out of moderation by accident.
+func checkManagedUser(id, userID uuid.UUID, role string) error {
+	if role != "moderator" {
+		return ErrAccessDenied
+	}
+	if id == userID {
+		return ErrOwnAccount
+	}
+	return nil
 }
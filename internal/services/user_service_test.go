@@ -2,9 +2,11 @@ package services
 
 import (
 	"context"
-	"os"
+	"errors"
 	"pvz/internal/models"
+	"pvz/internal/repository"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
@@ -13,6 +15,14 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	testTokens  = NewTokenManager("supersecret", time.Hour, "pvz-service", "pvz-api")
+	testLockout = Lockout{MaxAttempts: 3, Duration: 15 * time.Minute}
+	testPolicy  = PasswordPolicy{MinLength: 8, RequiredClasses: []string{"lower", "upper", "digit"}}
+	userListMax = 30
+	testClient  = ClientInfo{IP: "10.0.0.1", UserAgent: "test-agent"}
+)
+
 type MockUserRepo struct {
 	mock.Mock
 }
@@ -22,17 +32,103 @@ func (m *MockUserRepo) InsertUser(ctx context.Context, email, password, role str
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepo) InsertInvitedUser(ctx context.Context, email, password, role, invitationHash string) (*models.User, error) {
+	args := m.Called(ctx, email, password, role, invitationHash)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	args := m.Called(ctx, email)
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepo) UpdatePassword(ctx context.Context, id uuid.UUID, password string) error {
+	args := m.Called(ctx, id, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) RecordLoginFailure(ctx context.Context, id uuid.UUID, maxAttempts int, lockUntil time.Time) (*time.Time, error) {
+	args := m.Called(ctx, id, maxAttempts, lockUntil)
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockUserRepo) ResetPasswordWithToken(ctx context.Context, tokenHash, password string) error {
+	args := m.Called(ctx, tokenHash, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) ResetLoginFailures(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) GetUsers(ctx context.Context, filter repository.UserFilter, page, limit int) ([]models.User, error) {
+	args := m.Called(ctx, filter, page, limit)
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockUserRepo) UpdateRole(ctx context.Context, id uuid.UUID, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) SetDeactivated(ctx context.Context, id uuid.UUID, deactivated bool) error {
+	args := m.Called(ctx, id, deactivated)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) GetAssignedPVZs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockUserRepo) AssignPVZ(ctx context.Context, id, pvzID uuid.UUID) error {
+	args := m.Called(ctx, id, pvzID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) UnassignPVZ(ctx context.Context, id, pvzID uuid.UUID) error {
+	args := m.Called(ctx, id, pvzID)
+	return args.Error(0)
+}
+
+type MockLoginAttemptRepo struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptRepo) InsertLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepo) GetLoginAttempts(ctx context.Context, userID uuid.UUID, limit int) ([]models.LoginAttempt, error) {
+	args := m.Called(ctx, userID, limit)
+	return args.Get(0).([]models.LoginAttempt), args.Error(1)
+}
+
+// newTestUserService returns a service whose login history accepts every attempt.
+func newTestUserService(userRepo *MockUserRepo) (*UserService, *MockLoginAttemptRepo) {
+	history := new(MockLoginAttemptRepo)
+	history.On("InsertLoginAttempt", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return NewUserService(userRepo, history, testTokens, testLockout, testPolicy, userListMax, false), history
+}
+
 func TestUserService_RegisterUser(t *testing.T) {
 	mockRepo := new(MockUserRepo)
-	userService := NewUserService(mockRepo)
+	userService, _ := newTestUserService(mockRepo)
 
 	email := "test@example.com"
-	password := "password123"
+	password := "Sklad2026pvz"
 	role := "moderator"
 	user := &models.User{
 		ID:    uuid.New(),
@@ -42,18 +138,110 @@ func TestUserService_RegisterUser(t *testing.T) {
 
 	mockRepo.On("InsertUser", mock.Anything, email, password, role).Return(user, nil)
 
-	t.Run("successful user registration", func(t *testing.T) {
-		createdUser, err := userService.RegisterUser(context.Background(), email, password, role)
+	t.Run("moderator registered by a moderator", func(t *testing.T) {
+		createdUser, err := userService.RegisterUser(context.Background(), email, password, role, RegistrationAuth{CallerRole: "moderator"})
 
 		assert.NoError(t, err)
 		assert.Equal(t, email, createdUser.Email)
 		assert.Equal(t, role, createdUser.Role)
 	})
+
+	t.Run("moderator registered with an invitation", func(t *testing.T) {
+		mockRepo.On("InsertInvitedUser", mock.Anything, "invited@example.com", password, role, hashSecretToken("code")).
+			Return(&models.User{ID: uuid.New(), Email: "invited@example.com", Role: role}, nil).Once()
+
+		createdUser, err := userService.RegisterUser(context.Background(), "invited@example.com", password, role, RegistrationAuth{InvitationCode: "code"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, role, createdUser.Role)
+	})
+
+	t.Run("invalid invitation", func(t *testing.T) {
+		mockRepo.On("InsertInvitedUser", mock.Anything, "invited@example.com", password, role, hashSecretToken("used")).
+			Return((*models.User)(nil), repository.ErrInvalidInvitation).Once()
+
+		_, err := userService.RegisterUser(context.Background(), "invited@example.com", password, role, RegistrationAuth{InvitationCode: "used"})
+
+		assert.ErrorIs(t, err, repository.ErrInvalidInvitation)
+	})
+
+	t.Run("moderator without a moderator or an invitation", func(t *testing.T) {
+		for _, auth := range []RegistrationAuth{{}, {CallerRole: "employee"}} {
+			_, err := userService.RegisterUser(context.Background(), "self@example.com", password, role, auth)
+
+			assert.ErrorIs(t, err, ErrAccessDenied)
+		}
+		mockRepo.AssertNotCalled(t, "InsertUser", mock.Anything, "self@example.com", password, role)
+	})
+
+	t.Run("employee needs neither", func(t *testing.T) {
+		mockRepo.On("InsertUser", mock.Anything, "employee@example.com", password, "employee").
+			Return(&models.User{ID: uuid.New(), Email: "employee@example.com", Role: "employee"}, nil).Once()
+
+		createdUser, err := userService.RegisterUser(context.Background(), "employee@example.com", password, "employee", RegistrationAuth{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "employee", createdUser.Role)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		_, err := userService.RegisterUser(context.Background(), email, password, "admin", RegistrationAuth{})
+
+		assert.ErrorIs(t, err, ErrInvalidRole)
+		mockRepo.AssertNotCalled(t, "InsertUser", mock.Anything, email, password, "admin")
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		_, err := userService.RegisterUser(context.Background(), email, "password123", role, RegistrationAuth{CallerRole: "moderator"})
+
+		assert.ErrorIs(t, err, ErrWeakPassword)
+		var policyErr *PasswordPolicyError
+		assert.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []string{PasswordMissingUpper, PasswordBreached}, policyErr.Violations)
+		mockRepo.AssertNotCalled(t, "InsertUser", mock.Anything, email, "password123", role)
+	})
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		user := &models.User{ID: uuid.New(), Email: "test@example.com", Role: "employee"}
+
+		mockRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+		mockRepo.On("UpdatePassword", mock.Anything, user.ID, "New-passw0rd").Return(nil)
+
+		err := userService.ResetPassword(context.Background(), user.Email, "New-passw0rd")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+
+		mockRepo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return((*models.User)(nil), nil)
+
+		err := userService.ResetPassword(context.Background(), "nobody@example.com", "New-passw0rd")
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+
+		err := userService.ResetPassword(context.Background(), "test@example.com", "short")
+
+		assert.ErrorIs(t, err, ErrWeakPassword)
+		mockRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+	})
 }
 
 func TestUserService_LoginUser_Success(t *testing.T) {
 	mockRepo := new(MockUserRepo)
-	userService := NewUserService(mockRepo)
+	userService, _ := newTestUserService(mockRepo)
 
 	email := "test@example.com"
 	password := "securepass"
@@ -66,10 +254,9 @@ func TestUserService_LoginUser_Success(t *testing.T) {
 	}
 
 	mockRepo.On("GetUserByEmail", mock.Anything, email).Return(user, nil)
-	os.Setenv("JWT_SECRET", "supersecret")
 
 	t.Run("successful login", func(t *testing.T) {
-		token, err := userService.LoginUser(context.Background(), email, password)
+		token, err := userService.LoginUser(context.Background(), email, password, testClient)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
@@ -78,7 +265,7 @@ func TestUserService_LoginUser_Success(t *testing.T) {
 
 func TestUserService_LoginUser_InvalidPassword(t *testing.T) {
 	mockRepo := new(MockUserRepo)
-	userService := NewUserService(mockRepo)
+	userService, _ := newTestUserService(mockRepo)
 
 	email := "user@example.com"
 	user := &models.User{
@@ -88,9 +275,10 @@ func TestUserService_LoginUser_InvalidPassword(t *testing.T) {
 	}
 
 	mockRepo.On("GetUserByEmail", mock.Anything, email).Return(user, nil)
+	mockRepo.On("RecordLoginFailure", mock.Anything, user.ID, testLockout.MaxAttempts, mock.Anything).Return((*time.Time)(nil), nil)
 
 	t.Run("invalid password", func(t *testing.T) {
-		token, err := userService.LoginUser(context.Background(), email, "wrongpassword")
+		token, err := userService.LoginUser(context.Background(), email, "wrongpassword", testClient)
 
 		assert.Error(t, err)
 		assert.Empty(t, token)
@@ -100,14 +288,14 @@ func TestUserService_LoginUser_InvalidPassword(t *testing.T) {
 
 func TestUserService_LoginUser_UserNotFound(t *testing.T) {
 	mockRepo := new(MockUserRepo)
-	userService := NewUserService(mockRepo)
+	userService, _ := newTestUserService(mockRepo)
 
 	email := "notfound@example.com"
 
 	mockRepo.On("GetUserByEmail", mock.Anything, email).Return((*models.User)(nil), nil)
 
 	t.Run("user not found", func(t *testing.T) {
-		token, err := userService.LoginUser(context.Background(), email, "any")
+		token, err := userService.LoginUser(context.Background(), email, "any", testClient)
 
 		assert.Error(t, err)
 		assert.Equal(t, "no such user", err.Error())
@@ -115,11 +303,44 @@ func TestUserService_LoginUser_UserNotFound(t *testing.T) {
 	})
 }
 
+func TestUserService_LoginUser_Deactivated(t *testing.T) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("securepass"), bcrypt.MinCost)
+	deactivatedAt := time.Now().Add(-time.Hour)
+	user := &models.User{ID: uuid.New(), Email: "gone@example.com", Password: string(hashedPassword), Role: "employee", DeactivatedAt: &deactivatedAt}
+
+	t.Run("right password", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		history := new(MockLoginAttemptRepo)
+		userService := NewUserService(mockRepo, history, testTokens, testLockout, testPolicy, userListMax, false)
+		mockRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+		history.On("InsertLoginAttempt", mock.Anything, mock.MatchedBy(func(a models.LoginAttempt) bool {
+			return a.FailureReason == models.LoginFailureDeactivated && !a.Success
+		})).Return(nil)
+
+		token, err := userService.LoginUser(context.Background(), user.Email, "securepass", testClient)
+
+		assert.ErrorIs(t, err, ErrAccountDeactivated)
+		assert.Empty(t, token)
+		history.AssertExpectations(t)
+	})
+
+	t.Run("wrong password does not reveal the deactivation", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		mockRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+		mockRepo.On("RecordLoginFailure", mock.Anything, user.ID, testLockout.MaxAttempts, mock.Anything).Return((*time.Time)(nil), nil)
+
+		_, err := userService.LoginUser(context.Background(), user.Email, "wrong", testClient)
+
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	})
+}
+
 func TestDummyLogin_Success(t *testing.T) {
-	_ = os.Setenv("JWT_SECRET", "testsecret")
+	userService := NewUserService(new(MockUserRepo), new(MockLoginAttemptRepo), NewTokenManager("testsecret", time.Hour, "pvz-service", "pvz-api"), testLockout, testPolicy, userListMax, true)
 
 	t.Run("successful dummy login", func(t *testing.T) {
-		token, err := DummyLogin("moderator")
+		token, err := userService.DummyLogin("moderator")
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
@@ -135,14 +356,484 @@ func TestDummyLogin_Success(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, "moderator", claims.Role)
 		assert.NotEmpty(t, claims.UserID)
+		assert.True(t, claims.Dummy)
 	})
 }
 
 func TestDummyLogin_ValidRole(t *testing.T) {
 	t.Run("invalid role", func(t *testing.T) {
 		role := ""
-		_, err := DummyLogin(role)
+		_, err := NewUserService(new(MockUserRepo), new(MockLoginAttemptRepo), testTokens, testLockout, testPolicy, userListMax, true).DummyLogin(role)
 
 		assert.ErrorIs(t, err, ErrInvalidRole)
 	})
 }
+
+func TestDummyLogin_DevModeOnly(t *testing.T) {
+	token, err := NewUserService(new(MockUserRepo), new(MockLoginAttemptRepo), testTokens, testLockout, testPolicy, userListMax, false).DummyLogin("moderator")
+
+	assert.ErrorIs(t, err, ErrDummyLoginDisabled)
+	assert.Empty(t, token)
+}
+
+func TestUserService_LoginUser_Lockout(t *testing.T) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("securepass"), bcrypt.MinCost)
+	newUser := func() *models.User {
+		return &models.User{ID: uuid.New(), Email: "user@example.com", Password: string(hashedPassword), Role: "employee"}
+	}
+	attemptWith := func(reason string, success bool) interface{} {
+		return mock.MatchedBy(func(a models.LoginAttempt) bool {
+			return a.FailureReason == reason && a.Success == success && a.IP == testClient.IP && a.UserAgent == testClient.UserAgent
+		})
+	}
+
+	t.Run("wrong password is counted and recorded", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		history := new(MockLoginAttemptRepo)
+		userService := NewUserService(mockRepo, history, testTokens, testLockout, testPolicy, userListMax, false)
+		now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+		userService.now = func() time.Time { return now }
+		user := newUser()
+		lockedUntil := now.Add(testLockout.Duration)
+
+		mockRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+		mockRepo.On("RecordLoginFailure", mock.Anything, user.ID, 3, lockedUntil).Return(&lockedUntil, nil)
+		history.On("InsertLoginAttempt", mock.Anything, attemptWith(models.LoginFailureWrongPassword, false)).Return(nil)
+
+		_, err := userService.LoginUser(context.Background(), user.Email, "wrong", testClient)
+
+		assert.ErrorIs(t, err, ErrWrongPassword)
+		mockRepo.AssertExpectations(t)
+		history.AssertExpectations(t)
+	})
+
+	t.Run("locked account refuses the right password", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		history := new(MockLoginAttemptRepo)
+		userService := NewUserService(mockRepo, history, testTokens, testLockout, testPolicy, userListMax, false)
+		user := newUser()
+		lockedUntil := time.Now().Add(time.Minute)
+		user.LockedUntil = &lockedUntil
+
+		mockRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+		history.On("InsertLoginAttempt", mock.Anything, attemptWith(models.LoginFailureLocked, false)).Return(nil)
+
+		token, err := userService.LoginUser(context.Background(), user.Email, "securepass", testClient)
+
+		assert.ErrorIs(t, err, ErrAccountLocked)
+		assert.Empty(t, token)
+		mockRepo.AssertNotCalled(t, "RecordLoginFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		history.AssertExpectations(t)
+	})
+
+	t.Run("success after an expired lock resets the count", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		history := new(MockLoginAttemptRepo)
+		userService := NewUserService(mockRepo, history, testTokens, testLockout, testPolicy, userListMax, false)
+		user := newUser()
+		expired := time.Now().Add(-time.Minute)
+		user.LockedUntil = &expired
+		user.FailedLoginAttempts = 1
+
+		mockRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+		mockRepo.On("ResetLoginFailures", mock.Anything, user.ID).Return(nil)
+		history.On("InsertLoginAttempt", mock.Anything, mock.MatchedBy(func(a models.LoginAttempt) bool {
+			return a.Success && a.FailureReason == "" && *a.UserID == user.ID
+		})).Return(nil)
+
+		token, err := userService.LoginUser(context.Background(), user.Email, "securepass", testClient)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+		mockRepo.AssertExpectations(t)
+		history.AssertExpectations(t)
+	})
+
+	t.Run("unknown email is recorded without a user", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		history := new(MockLoginAttemptRepo)
+		userService := NewUserService(mockRepo, history, testTokens, testLockout, testPolicy, userListMax, false)
+
+		mockRepo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return((*models.User)(nil), nil)
+		history.On("InsertLoginAttempt", mock.Anything, mock.MatchedBy(func(a models.LoginAttempt) bool {
+			return a.UserID == nil && a.Email == "nobody@example.com" && a.FailureReason == models.LoginFailureUnknownEmail
+		})).Return(nil)
+
+		_, err := userService.LoginUser(context.Background(), "nobody@example.com", "any", testClient)
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		history.AssertExpectations(t)
+	})
+
+	t.Run("disabled lockout does not count failures", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		history := new(MockLoginAttemptRepo)
+		userService := NewUserService(mockRepo, history, testTokens, Lockout{}, testPolicy, userListMax, false)
+		history.On("InsertLoginAttempt", mock.Anything, mock.Anything).Return(nil)
+		user := newUser()
+
+		mockRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+
+		_, err := userService.LoginUser(context.Background(), user.Email, "wrong", testClient)
+
+		assert.ErrorIs(t, err, ErrWrongPassword)
+		mockRepo.AssertNotCalled(t, "RecordLoginFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("history failure does not block the login", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		history := new(MockLoginAttemptRepo)
+		userService := NewUserService(mockRepo, history, testTokens, testLockout, testPolicy, userListMax, false)
+		user := newUser()
+
+		mockRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+		history.On("InsertLoginAttempt", mock.Anything, mock.Anything).Return(errors.New("db is down"))
+
+		token, err := userService.LoginUser(context.Background(), user.Email, "securepass", testClient)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+	})
+}
+
+func TestUserService_UnlockUser(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("moderator", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		mockRepo.On("ResetLoginFailures", mock.Anything, id).Return(nil)
+
+		assert.NoError(t, userService.UnlockUser(context.Background(), id, "moderator"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("employee", func(t *testing.T) {
+		userService, _ := newTestUserService(new(MockUserRepo))
+
+		assert.ErrorIs(t, userService.UnlockUser(context.Background(), id, "employee"), ErrAccessDenied)
+	})
+}
+
+func TestUserService_GetLoginHistory(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, history := newTestUserService(mockRepo)
+		attempts := []models.LoginAttempt{{ID: uuid.New(), UserID: &id, Success: true}}
+		mockRepo.On("GetUserByID", mock.Anything, id).Return(&models.User{ID: id}, nil)
+		history.On("GetLoginAttempts", mock.Anything, id, loginHistoryLimit).Return(attempts, nil)
+
+		result, err := userService.GetLoginHistory(context.Background(), id, "moderator")
+
+		assert.NoError(t, err)
+		assert.Equal(t, attempts, result)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		mockRepo.On("GetUserByID", mock.Anything, id).Return((*models.User)(nil), nil)
+
+		_, err := userService.GetLoginHistory(context.Background(), id, "moderator")
+
+		assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	})
+
+	t.Run("employee", func(t *testing.T) {
+		userService, _ := newTestUserService(new(MockUserRepo))
+
+		_, err := userService.GetLoginHistory(context.Background(), id, "employee")
+
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("Current-passw0rd"), bcrypt.MinCost)
+	newUser := func() *models.User {
+		return &models.User{ID: uuid.New(), Email: "user@example.com", Password: string(hashedPassword), Role: "employee"}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		user := newUser()
+		mockRepo.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+		mockRepo.On("UpdatePassword", mock.Anything, user.ID, "New-passw0rd").Return(nil)
+
+		err := userService.ChangePassword(context.Background(), user.ID, "Current-passw0rd", "New-passw0rd")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("wrong current password counts towards the lockout", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		user := newUser()
+		mockRepo.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+		mockRepo.On("RecordLoginFailure", mock.Anything, user.ID, testLockout.MaxAttempts, mock.Anything).Return((*time.Time)(nil), nil)
+
+		err := userService.ChangePassword(context.Background(), user.ID, "guess", "New-passw0rd")
+
+		assert.ErrorIs(t, err, ErrWrongCurrentPassword)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("locked account", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		user := newUser()
+		lockedUntil := time.Now().Add(time.Minute)
+		user.LockedUntil = &lockedUntil
+		mockRepo.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+
+		err := userService.ChangePassword(context.Background(), user.ID, "Current-passw0rd", "New-passw0rd")
+
+		assert.ErrorIs(t, err, ErrAccountLocked)
+	})
+
+	t.Run("new password must follow the policy and differ", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		user := newUser()
+		mockRepo.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+
+		err := userService.ChangePassword(context.Background(), user.ID, "Current-passw0rd", "weak")
+		assert.ErrorIs(t, err, ErrWeakPassword)
+
+		err = userService.ChangePassword(context.Background(), user.ID, "Current-passw0rd", "Current-passw0rd")
+		var policyErr *PasswordPolicyError
+		assert.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []string{PasswordUnchanged}, policyErr.Violations)
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("user without an account", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		id := uuid.New()
+		mockRepo.On("GetUserByID", mock.Anything, id).Return((*models.User)(nil), nil)
+
+		err := userService.ChangePassword(context.Background(), id, "Current-passw0rd", "New-passw0rd")
+
+		assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	})
+}
+
+func TestUserService_CheckToken(t *testing.T) {
+	id := uuid.New()
+	issuedAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	claims := &CustomClaims{UserID: id, Role: "employee", StandardClaims: jwt.StandardClaims{IssuedAt: issuedAt.Unix()}}
+	at := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		dummy   bool
+		devMode bool
+		wantErr error
+	}{
+		{name: "active user", user: &models.User{ID: id}},
+		{name: "token issued after the revocation", user: &models.User{ID: id, TokensRevokedAt: at(issuedAt.Add(-time.Minute))}},
+		{name: "token issued in the second of the revocation", user: &models.User{ID: id, TokensRevokedAt: at(issuedAt.Add(500 * time.Millisecond))}},
+		{name: "token issued before the revocation", user: &models.User{ID: id, TokensRevokedAt: at(issuedAt.Add(time.Minute))}, wantErr: ErrTokenRevoked},
+		{name: "deactivated user", user: &models.User{ID: id, DeactivatedAt: at(issuedAt.Add(-time.Hour))}, wantErr: ErrAccountDeactivated},
+		{name: "deleted user", wantErr: ErrTokenRevoked},
+		{name: "dummy token in dev mode", dummy: true, devMode: true},
+		{name: "dummy token outside dev mode", dummy: true, wantErr: ErrTokenRevoked},
+		{name: "deleted user in dev mode", devMode: true, wantErr: ErrTokenRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepo)
+			mockRepo.On("GetUserByID", mock.Anything, id).Return(tt.user, nil)
+			userService := NewUserService(mockRepo, nil, testTokens, testLockout, testPolicy, userListMax, tt.devMode)
+
+			claims := *claims
+			claims.Dummy = tt.dummy
+			err := userService.CheckToken(context.Background(), &claims)
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.ErrorIs(t, err, ErrTokenRevoked)
+		})
+	}
+
+	t.Run("database error", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		mockRepo.On("GetUserByID", mock.Anything, id).Return((*models.User)(nil), errors.New("db down"))
+
+		err := NewUserService(mockRepo, nil, testTokens, testLockout, testPolicy, userListMax, false).CheckToken(context.Background(), claims)
+
+		assert.EqualError(t, err, "db down")
+	})
+}
+
+func TestUserService_GetUsers(t *testing.T) {
+	filter := repository.UserFilter{Role: "employee", Email: "pvz"}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		users := []models.User{{ID: uuid.New(), Email: "pvz@example.com", Role: "employee"}}
+		mockRepo.On("GetUsers", mock.Anything, filter, 2, 10).Return(users, nil)
+
+		result, err := userService.GetUsers(context.Background(), filter, 2, 10, "moderator")
+
+		assert.NoError(t, err)
+		assert.Equal(t, users, result)
+	})
+
+	tests := []struct {
+		name    string
+		filter  repository.UserFilter
+		page    int
+		limit   int
+		role    string
+		wantErr error
+	}{
+		{name: "employee", filter: filter, page: 1, limit: 10, role: "employee", wantErr: ErrAccessDenied},
+		{name: "unknown role filter", filter: repository.UserFilter{Role: "admin"}, page: 1, limit: 10, role: "moderator", wantErr: ErrInvalidRole},
+		{name: "invalid page", filter: filter, page: 0, limit: 10, role: "moderator", wantErr: ErrPageParamIsInvalid},
+		{name: "limit too large", filter: filter, page: 1, limit: userListMax + 1, role: "moderator", wantErr: ErrLimitParamIsInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userService, _ := newTestUserService(new(MockUserRepo))
+
+			_, err := userService.GetUsers(context.Background(), tt.filter, tt.page, tt.limit, tt.role)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestUserService_GetUser(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		mockRepo.On("GetUserByID", mock.Anything, id).Return(&models.User{ID: id, Role: "employee"}, nil)
+
+		user, err := userService.GetUser(context.Background(), id, "moderator")
+
+		assert.NoError(t, err)
+		assert.Equal(t, id, user.ID)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		mockRepo.On("GetUserByID", mock.Anything, id).Return((*models.User)(nil), nil)
+
+		_, err := userService.GetUser(context.Background(), id, "moderator")
+
+		assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	})
+
+	t.Run("employee", func(t *testing.T) {
+		userService, _ := newTestUserService(new(MockUserRepo))
+
+		_, err := userService.GetUser(context.Background(), id, "employee")
+
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+}
+
+func TestUserService_GetProfile(t *testing.T) {
+	id, pvzID := uuid.New(), uuid.New()
+
+	t.Run("user", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		mockRepo.On("GetUserByID", mock.Anything, id).Return(&models.User{ID: id, Email: "user@example.com", Role: "employee"}, nil)
+		mockRepo.On("GetAssignedPVZs", mock.Anything, id).Return([]uuid.UUID{pvzID}, nil)
+
+		profile, err := userService.GetProfile(context.Background(), id, "employee")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.Profile{ID: id, Email: "user@example.com", Role: "employee", AssignedPVZs: []uuid.UUID{pvzID}}, profile)
+	})
+
+	t.Run("dummy token in dev mode", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		userService.devMode = true
+		mockRepo.On("GetUserByID", mock.Anything, id).Return((*models.User)(nil), nil)
+
+		profile, err := userService.GetProfile(context.Background(), id, "moderator")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.Profile{ID: id, Role: "moderator", AssignedPVZs: []uuid.UUID{}, Dummy: true}, profile)
+		mockRepo.AssertNotCalled(t, "GetAssignedPVZs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		mockRepo.On("GetUserByID", mock.Anything, id).Return((*models.User)(nil), nil)
+
+		_, err := userService.GetProfile(context.Background(), id, "employee")
+
+		assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	})
+}
+
+func TestUserService_ManageUser(t *testing.T) {
+	id := uuid.New()
+	moderatorID := uuid.New()
+
+	t.Run("change role", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		mockRepo.On("UpdateRole", mock.Anything, id, "moderator").Return(nil)
+
+		assert.NoError(t, userService.ChangeRole(context.Background(), id, "moderator", moderatorID, "moderator"))
+		assert.ErrorIs(t, userService.ChangeRole(context.Background(), id, "admin", moderatorID, "moderator"), ErrInvalidRole)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("deactivate and reactivate", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		mockRepo.On("SetDeactivated", mock.Anything, id, true).Return(nil)
+		mockRepo.On("SetDeactivated", mock.Anything, id, false).Return(nil)
+
+		assert.NoError(t, userService.SetDeactivated(context.Background(), id, true, moderatorID, "moderator"))
+		assert.NoError(t, userService.SetDeactivated(context.Background(), id, false, moderatorID, "moderator"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockRepo := new(MockUserRepo)
+		userService, _ := newTestUserService(mockRepo)
+		mockRepo.On("DeleteUser", mock.Anything, id).Return(repository.ErrUserNotFound)
+
+		assert.ErrorIs(t, userService.DeleteUser(context.Background(), id, moderatorID, "moderator"), repository.ErrUserNotFound)
+	})
+
+	t.Run("employee", func(t *testing.T) {
+		userService, _ := newTestUserService(new(MockUserRepo))
+
+		assert.ErrorIs(t, userService.ChangeRole(context.Background(), id, "moderator", id, "employee"), ErrAccessDenied)
+		assert.ErrorIs(t, userService.SetDeactivated(context.Background(), id, true, moderatorID, "employee"), ErrAccessDenied)
+		assert.ErrorIs(t, userService.DeleteUser(context.Background(), id, moderatorID, "employee"), ErrAccessDenied)
+	})
+
+	t.Run("own account", func(t *testing.T) {
+		userService, _ := newTestUserService(new(MockUserRepo))
+
+		assert.ErrorIs(t, userService.ChangeRole(context.Background(), moderatorID, "employee", moderatorID, "moderator"), ErrOwnAccount)
+		assert.ErrorIs(t, userService.SetDeactivated(context.Background(), moderatorID, true, moderatorID, "moderator"), ErrOwnAccount)
+		assert.ErrorIs(t, userService.DeleteUser(context.Background(), moderatorID, moderatorID, "moderator"), ErrOwnAccount)
+	})
+}
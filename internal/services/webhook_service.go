@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const deliveriesListLimit = 100
+
+var allowedEventTypes = map[string]bool{
+	models.EventPVZCreated:       true,
+	models.EventReceptionCreated: true,
+	models.EventReceptionClosed:  true,
+	models.EventProductAdded:     true,
+	models.EventProductDeleted:   true,
+}
+
+type WebhookServiceInterface interface {
+	CreateSubscription(ctx context.Context, targetURL, eventType string, userID uuid.UUID, role string) (models.WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context, role string) ([]models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID, role string) error
+	GetDeliveries(ctx context.Context, subscriptionID uuid.UUID, role string) ([]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID uuid.UUID, role string) (models.WebhookDelivery, error)
+}
+
+type WebhookService struct {
+	webhookRepo repository.WebhookRepositoryInterface
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepositoryInterface) *WebhookService {
+	return &WebhookService{webhookRepo: webhookRepo}
+}
+
+func (s *WebhookService) CreateSubscription(ctx context.Context, targetURL, eventType string, userID uuid.UUID, role string) (models.WebhookSubscription, error) {
+	if role != "moderator" {
+		return models.WebhookSubscription{}, ErrAccessDenied
+	}
+
+	if _, ok := allowedEventTypes[eventType]; !ok {
+		return models.WebhookSubscription{}, ErrEventTypeNotAllowed
+	}
+
+	parsed, err := url.Parse(targetURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return models.WebhookSubscription{}, ErrInvalidWebhookURL
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	subscription, err := s.webhookRepo.InsertSubscription(ctx, targetURL, eventType, secret, userID)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return *subscription, nil
+}
+
+func (s *WebhookService) GetSubscriptions(ctx context.Context, role string) ([]models.WebhookSubscription, error) {
+	if role != "moderator" {
+		return nil, ErrAccessDenied
+	}
+
+	return s.webhookRepo.GetSubscriptions(ctx)
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id uuid.UUID, role string) error {
+	if role != "moderator" {
+		return ErrAccessDenied
+	}
+
+	return s.webhookRepo.DeleteSubscription(ctx, id)
+}
+
+func (s *WebhookService) GetDeliveries(ctx context.Context, subscriptionID uuid.UUID, role string) ([]models.WebhookDelivery, error) {
+	if role != "moderator" {
+		return nil, ErrAccessDenied
+	}
+
+	return s.webhookRepo.GetDeliveries(ctx, subscriptionID, deliveriesListLimit)
+}
+
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID, role string) (models.WebhookDelivery, error) {
+	if role != "moderator" {
+		return models.WebhookDelivery{}, ErrAccessDenied
+	}
+
+	delivery, err := s.webhookRepo.ResetDelivery(ctx, deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	return *delivery, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
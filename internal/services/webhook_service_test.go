@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) InsertSubscription(ctx context.Context, url, eventType, secret string, createdBy uuid.UUID) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, url, eventType, secret, createdBy)
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) EnqueueDeliveries(ctx context.Context, event models.Event) (int, error) {
+	args := m.Called(ctx, event)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) MarkDeliveryDelivered(ctx context.Context, id uuid.UUID, responseCode int) error {
+	args := m.Called(ctx, id, responseCode)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, responseCode int, lastError string, nextAttemptAt *time.Time) error {
+	args := m.Called(ctx, id, responseCode, lastError, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ResetDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func TestWebhookService_CreateSubscription(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	webhookService := NewWebhookService(mockRepo)
+	userID := uuid.New()
+	url := "https://partner.example/hook"
+
+	t.Run("successful subscription", func(t *testing.T) {
+		mockRepo.On("InsertSubscription", mock.Anything, url, models.EventReceptionClosed, mock.AnythingOfType("string"), userID).
+			Return(&models.WebhookSubscription{ID: uuid.New(), URL: url, EventType: models.EventReceptionClosed, Secret: "generated"}, nil)
+
+		subscription, err := webhookService.CreateSubscription(context.Background(), url, models.EventReceptionClosed, userID, "moderator")
+
+		assert.NoError(t, err)
+		assert.Equal(t, url, subscription.URL)
+		secret := mockRepo.Calls[0].Arguments.String(3)
+		assert.Len(t, secret, 64)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("access denied for employee", func(t *testing.T) {
+		_, err := webhookService.CreateSubscription(context.Background(), url, models.EventReceptionClosed, userID, "employee")
+
+		assert.Equal(t, ErrAccessDenied, err)
+	})
+
+	t.Run("unknown event type", func(t *testing.T) {
+		_, err := webhookService.CreateSubscription(context.Background(), url, "reception.exploded", userID, "moderator")
+
+		assert.Equal(t, ErrEventTypeNotAllowed, err)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		_, err := webhookService.CreateSubscription(context.Background(), "ftp://partner.example", models.EventPVZCreated, userID, "moderator")
+
+		assert.Equal(t, ErrInvalidWebhookURL, err)
+	})
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	webhookService := NewWebhookService(mockRepo)
+	deliveryID := uuid.New()
+
+	t.Run("successful redelivery", func(t *testing.T) {
+		mockRepo.On("ResetDelivery", mock.Anything, deliveryID).
+			Return(&models.WebhookDelivery{ID: deliveryID, Status: models.DeliveryStatusPending}, nil)
+
+		delivery, err := webhookService.Redeliver(context.Background(), deliveryID, "moderator")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DeliveryStatusPending, delivery.Status)
+	})
+
+	t.Run("delivery not found", func(t *testing.T) {
+		mockRepo.ExpectedCalls = []*mock.Call{}
+		mockRepo.On("ResetDelivery", mock.Anything, deliveryID).
+			Return((*models.WebhookDelivery)(nil), repository.ErrDeliveryNotFound)
+
+		_, err := webhookService.Redeliver(context.Background(), deliveryID, "moderator")
+
+		assert.ErrorIs(t, err, repository.ErrDeliveryNotFound)
+	})
+
+	t.Run("access denied for employee", func(t *testing.T) {
+		_, err := webhookService.Redeliver(context.Background(), deliveryID, "employee")
+
+		assert.Equal(t, ErrAccessDenied, err)
+	})
+}
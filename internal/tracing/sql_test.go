@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// dsnConnector turns the sqlmock driver into a connector, as pq.NewConnector does for lib/pq.
+type dsnConnector struct {
+	dsn string
+	drv driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.drv.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.drv }
+
+func newTracedDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *tracetest.SpanRecorder) {
+	t.Helper()
+	mockDB, mock, err := sqlmock.NewWithDSN(t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	db := sql.OpenDB(WrapConnector(dsnConnector{dsn: t.Name(), drv: mockDB.Driver()}))
+	t.Cleanup(func() { db.Close() })
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return db, mock, recorder
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestWrapConnector(t *testing.T) {
+	t.Run("query span counts returned rows", func(t *testing.T) {
+		db, mock, recorder := newTracedDB(t)
+		query := "SELECT id FROM pvz WHERE city = $1"
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("Москва").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1").AddRow("2"))
+
+		parentCtx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+		rows, err := db.QueryContext(parentCtx, query, "Москва")
+		require.NoError(t, err)
+		for rows.Next() {
+		}
+		require.NoError(t, rows.Close())
+		parent.End()
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		span := spans[0]
+		assert.Equal(t, "SELECT", span.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		attrs := attributes(span)
+		assert.Equal(t, query, attrs["db.query.text"].AsString())
+		assert.Equal(t, "postgresql", attrs["db.system.name"].AsString())
+		assert.Equal(t, int64(2), attrs["db.response.returned_rows"].AsInt64())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("exec span counts affected rows", func(t *testing.T) {
+		db, mock, recorder := newTracedDB(t)
+		query := "DELETE FROM idempotency_keys WHERE expires_at < $1"
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnResult(sqlmock.NewResult(0, 3))
+
+		_, err := db.ExecContext(context.Background(), query, 1)
+		require.NoError(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "DELETE", spans[0].Name())
+		assert.Equal(t, int64(3), attributes(spans[0])[rowsAffectedKey].AsInt64())
+	})
+
+	t.Run("queries in a transaction are traced", func(t *testing.T) {
+		db, mock, recorder := newTracedDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1")).WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+		mock.ExpectCommit()
+
+		tx, err := db.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		var n int
+		require.NoError(t, tx.QueryRowContext(context.Background(), "SELECT 1").Scan(&n))
+		require.NoError(t, tx.Commit())
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, int64(1), attributes(spans[0])["db.response.returned_rows"].AsInt64())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed query marks the span", func(t *testing.T) {
+		db, mock, recorder := newTracedDB(t)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1")).WillReturnError(errors.New("relation does not exist"))
+
+		_, err := db.QueryContext(context.Background(), "SELECT 1")
+		require.Error(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, "relation does not exist", spans[0].Status().Description)
+	})
+}
+
+func TestOperationName(t *testing.T) {
+	assert.Equal(t, "INSERT", operationName("\n  insert INTO pvz (city) VALUES ($1)"))
+	assert.Equal(t, "SQL", operationName(" "))
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"pvz/internal/config"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	t.Run("file exporter continues incoming traces", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "traces.jsonl")
+		shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: "file", File: file, SampleRatio: 1})
+		require.NoError(t, err)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(otelgin.Middleware(ServiceName))
+		var traceID trace.TraceID
+		router.GET("/pvz", func(c *gin.Context) {
+			traceID = trace.SpanContextFromContext(c.Request.Context()).TraceID()
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/pvz", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		require.NoError(t, shutdown(context.Background()))
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID.String())
+		exported, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Contains(t, string(exported), "4bf92f3577b34da6a3ce929d0e0e4736")
+		assert.Contains(t, string(exported), ServiceName)
+	})
+
+	t.Run("none exports nothing", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: "none"})
+
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := Setup(context.Background(), config.TracingConfig{Exporter: "jaeger"})
+
+		assert.Error(t, err)
+	})
+}
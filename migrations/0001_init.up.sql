@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    password TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('employee', 'moderator'))
+);
+
+CREATE TABLE IF NOT EXISTS pvz (
+    id UUID PRIMARY KEY,
+    registration_date TIMESTAMPTZ NOT NULL DEFAULT now(),
+    city TEXT NOT NULL CHECK (city IN ('Москва', 'Санкт-Петербург', 'Казань'))
+);
+
+CREATE TABLE IF NOT EXISTS receptions (
+    id UUID PRIMARY KEY,
+    date_time TIMESTAMPTZ NOT NULL DEFAULT now(),
+    pvz_id UUID NOT NULL REFERENCES pvz(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'close'))
+);
+
+CREATE TABLE IF NOT EXISTS products (
+    id UUID PRIMARY KEY,
+    date_time TIMESTAMPTZ NOT NULL DEFAULT now(),
+    type TEXT NOT NULL CHECK (type IN ('электроника', 'одежда', 'обувь')),
+    reception_id UUID NOT NULL REFERENCES receptions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_receptions_pvz_status ON receptions(pvz_id, status);
+CREATE INDEX IF NOT EXISTS idx_products_reception_id ON products(reception_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_receptions_closed_by;
+DROP INDEX IF EXISTS idx_receptions_created_by;
+
+ALTER TABLE products DROP COLUMN IF EXISTS created_by;
+
+ALTER TABLE receptions
+    DROP COLUMN IF EXISTS closed_by,
+    DROP COLUMN IF EXISTS created_by;
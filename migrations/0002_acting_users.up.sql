@@ -0,0 +1,8 @@
+ALTER TABLE receptions
+    ADD COLUMN IF NOT EXISTS created_by UUID,
+    ADD COLUMN IF NOT EXISTS closed_by UUID;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS created_by UUID;
+
+CREATE INDEX IF NOT EXISTS idx_receptions_created_by ON receptions(created_by);
+CREATE INDEX IF NOT EXISTS idx_receptions_closed_by ON receptions(closed_by);
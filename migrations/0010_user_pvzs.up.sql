@@ -0,0 +1,7 @@
+-- the PVZs a user works at, listed by GET /me
+CREATE TABLE IF NOT EXISTS user_pvzs (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pvz_id UUID NOT NULL REFERENCES pvz(id) ON DELETE CASCADE,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, pvz_id)
+);
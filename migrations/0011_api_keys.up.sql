@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    -- the start of the key, shown in listings so that a key can be recognised
+    prefix TEXT NOT NULL,
+    permissions TEXT[] NOT NULL,
+    -- empty: every PVZ
+    pvz_ids UUID[] NOT NULL DEFAULT '{}',
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ,
+    -- after a rotation the previous secret keeps working until previous_key_expires_at
+    previous_key_hash TEXT,
+    previous_key_expires_at TIMESTAMPTZ,
+    rotated_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_previous_key_hash ON api_keys(previous_key_hash);
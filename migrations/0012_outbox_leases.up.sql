@@ -0,0 +1,7 @@
+-- events are claimed for a lease and published outside the claiming transaction; an event that keeps
+-- failing is set aside with failed_at instead of holding back every later event
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS failed_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON outbox(seq) WHERE published_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_outbox_pvz_stream;
+CREATE INDEX IF NOT EXISTS idx_outbox_pvz_seq ON outbox(pvz_id, seq);
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS xact_id;
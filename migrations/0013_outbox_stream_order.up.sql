@@ -0,0 +1,7 @@
+-- seq is taken when an event is written, not when it commits, so a long transaction can make an older seq
+-- visible after a newer one. The event stream is ordered by the writing transaction instead and only
+-- reaches transactions older than any still in progress, which can no longer be joined by new events.
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS xact_id XID8 NOT NULL DEFAULT pg_current_xact_id();
+
+DROP INDEX IF EXISTS idx_outbox_pvz_seq;
+CREATE INDEX IF NOT EXISTS idx_outbox_pvz_stream ON outbox(pvz_id, xact_id, seq);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_outbox_webhooks_pending;
+
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS webhooks_failed_at,
+    DROP COLUMN IF EXISTS webhooks_last_error,
+    DROP COLUMN IF EXISTS webhooks_attempts,
+    DROP COLUMN IF EXISTS webhooks_locked_until,
+    DROP COLUMN IF EXISTS webhooks_enqueued_at;
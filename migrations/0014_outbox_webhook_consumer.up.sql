@@ -0,0 +1,13 @@
+-- webhook subscriptions consume the outbox on their own, with their own lease, attempts and failures,
+-- so a failing OUTBOX_PUBLISHER neither holds back the deliveries nor gets an event again when they fail
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS webhooks_enqueued_at TIMESTAMPTZ;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS webhooks_locked_until TIMESTAMPTZ;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS webhooks_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS webhooks_last_error TEXT;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS webhooks_failed_at TIMESTAMPTZ;
+
+-- events published so far had their deliveries enqueued in the same step
+UPDATE outbox SET webhooks_enqueued_at = published_at WHERE published_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_webhooks_pending ON outbox(seq)
+    WHERE webhooks_enqueued_at IS NULL AND webhooks_failed_at IS NULL;
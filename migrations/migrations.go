@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned schema migrations applied by internal/migrate.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql. 0001 is the schema of the
+// former init.sql, written with IF NOT EXISTS so such databases are adopted; every later change,
+// including the columns added after init.sql, comes as a migration of its own.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -8,6 +8,7 @@ import (
 	"pvz/internal/repository"
 	"pvz/internal/services"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
@@ -17,23 +18,26 @@ import (
 func setupRoutesForBasicTest(db *sql.DB) *gin.Engine {
 	r := gin.Default()
 
-	middleware.SetSecretKey(secret)
+	tokens := services.NewTokenManager(secret, time.Hour, "pvz-service", "pvz-api")
 
 	pvzRepo := repository.NewPWZRepository(db)
 	receptionRepo := repository.NewReceptionRepository(db)
 	productRepo := repository.NewProductRepository(db)
 
-	pvzService := services.NewPVZService(pvzRepo)
-	receptionService := services.NewReceptionService(receptionRepo)
-	productService := services.NewProductService(productRepo)
+	pvzService := services.NewPVZService(pvzRepo, 30, services.NopObserver{})
+	receptionService := services.NewReceptionService(receptionRepo, services.NopObserver{})
+	productService := services.NewProductService(productRepo, services.NopObserver{})
 
-	PVZHandler := handlers.NewPVZHandler(pvzService)
+	PVZHandler := handlers.NewPVZHandler(pvzService, 10)
 	receptionHandler := handlers.NewReceptionHandler(receptionService)
 	productHandler := handlers.NewProductHandler(productService)
+	userService := services.NewUserService(
+		repository.NewUserRepository(db), repository.NewLoginAttemptRepository(db), tokens, services.Lockout{}, services.PasswordPolicy{}, 30, true)
+	userHandler := handlers.NewUserHandler(userService, 10)
 
-	r.POST("/dummyLogin", handlers.DummyLoginHandler)
+	r.POST("/dummyLogin", userHandler.DummyLogin)
 
-	r.Use(middleware.JWTMiddleware())
+	r.Use(middleware.JWTMiddleware(tokens, userService))
 
 	r.POST("/pvz", PVZHandler.CreatePVZ)
 	r.GET("/pvz", PVZHandler.GetPVZInfo)
@@ -52,7 +56,6 @@ func TestBasicScenario(t *testing.T) {
 		assert.NoError(t, err)
 	}()
 
-	middleware.SetSecretKey(secret)
 	router := setupRoutesForBasicTest(db)
 	server := httptest.NewServer(router)
 	defer server.Close()
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"pvz/internal/migrate"
+	"pvz/internal/repository"
+	"pvz/migrations"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// test bringing a database created by the former init.sql (testdata/init.sql) up to date.
+func TestMigrateFromInitSQL(t *testing.T) {
+	ctx := context.Background()
+	db := initTestDB()
+	defer db.Close()
+
+	// a schema of its own keeps the test away from the tables the other tests use
+	schema := "migrate_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err := db.ExecContext(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	defer db.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE")
+
+	scoped, err := sql.Open("postgres", testConnStr+" search_path="+schema)
+	require.NoError(t, err)
+	defer scoped.Close()
+
+	baseline, err := os.ReadFile("testdata/init.sql")
+	require.NoError(t, err)
+	_, err = scoped.ExecContext(ctx, string(baseline))
+	require.NoError(t, err)
+
+	migrator, err := migrate.New(scoped, migrations.FS)
+	require.NoError(t, err)
+	all, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(all))
+
+	// the columns added after init.sql are there for the repositories
+	pvz, err := repository.NewPWZRepository(scoped).InsertPVZ(ctx, "Казань")
+	require.NoError(t, err)
+	userID := uuid.New()
+	reception, err := repository.NewReceptionRepository(scoped).InsertReception(ctx, pvz.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, userID, reception.CreatedBy)
+
+	dump, err := repository.NewPWZRepository(scoped).GetPVZ(ctx, pvz.ID)
+	require.NoError(t, err)
+	assert.Len(t, dump.Receptions, 1)
+}
@@ -15,9 +15,10 @@ import (
 
 const secret = "3q2+7wX9zY8RtKpLmN1v5xQjZ0oWcVbGyA6sFhJdU"
 
+const testConnStr = "host=postgres port=5432 user=postgres password=password dbname=pvz sslmode=disable"
+
 func initTestDB() *sql.DB {
-	connStr := "host=postgres port=5432 user=postgres password=password dbname=pvz sslmode=disable"
-	db, err := sql.Open("postgres", connStr)
+	db, err := sql.Open("postgres", testConnStr)
 	if err != nil {
 		log.Fatalf("failed to open database: %v", err)
 		return nil